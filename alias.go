@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrAliasCycle = errors.New("alias cycle")
+
+const aliasSection = "aliases"
+
+// EnableAliases registers an alias command for defining shortcuts such as
+//
+//	app alias set dp "deploy --env prod"
+//
+// which are stored in the config file and expanded by Run before the
+// command is resolved. Aliases cannot shadow commands.
+func (app *App) EnableAliases() {
+	app.aliases = true
+
+	app.AddCommand(NewCommand("alias", "Shell", "Manage command aliases: list, set <name> <command>, delete <name>",
+		func(cmd *Command) {
+			cmd.AppendVarArg("action", "list, set or delete, followed by its arguments")
+		},
+		func(cmd *Command) error {
+			args := cmd.VarArgs()
+
+			cf, err := app.loadConfig()
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case args[0] == "list" && len(args) == 1:
+				rc, err := app.resolveConfig(cmd.Context())
+				if err != nil {
+					return err
+				}
+
+				for _, n := range rc.keys(aliasSection) {
+					v, _ := rc.get(aliasSection + "." + n)
+					fmt.Fprintf(cmd.Stdout, "%-18s %s\n", n, v)
+				}
+
+				return nil
+			case args[0] == "set" && len(args) == 3:
+				name, expansion := args[1].String(), args[2].String()
+
+				if err := app.validAlias(name); err != nil {
+					return err
+				}
+
+				if _, err := splitArgs(expansion); err != nil {
+					return fmt.Errorf("invalid alias %s: %v", name, err)
+				}
+
+				if err := cf.set(aliasSection+"."+name, expansion); err != nil {
+					return err
+				}
+
+				if _, err := app.expandAliasesIn(cf, []string{app.name(), name}); err != nil {
+					return err
+				}
+
+				return cf.save()
+			case args[0] == "delete" && len(args) == 2:
+				ok, err := cf.delete(aliasSection + "." + args[1].String())
+				if err != nil {
+					return err
+				} else if !ok {
+					return fmt.Errorf("no alias named %s", args[1])
+				}
+
+				return cf.save()
+			}
+
+			return cmd.usageErr("Invalid alias action", ErrArgCount)
+		}))
+}
+
+func (app *App) validAlias(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, ".:# \t\"'") {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+
+	if _, ok := app.Commands[name]; ok {
+		return fmt.Errorf("alias %s would shadow the %s command", name, name)
+	}
+
+	return nil
+}
+
+// expandAliases replaces an alias in the command position of args with its
+// expansion, repeatedly, failing with ErrAliasCycle if an alias refers back
+// to itself.
+func (app *App) expandAliases(ctx context.Context, args []string) ([]string, error) {
+	if len(args) < 2 {
+		return args, nil
+	}
+
+	if _, ok := app.Commands[args[1]]; ok {
+		return args, nil
+	}
+
+	cf, err := app.resolveConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.expandAliasesIn(cf, args)
+}
+
+func (app *App) expandAliasesIn(cf *configFile, args []string) ([]string, error) {
+	seen := []string{}
+
+	for len(args) > 1 {
+		if _, ok := app.Commands[args[1]]; ok {
+			break
+		}
+
+		expansion, ok := cf.get(aliasSection + "." + args[1])
+		if !ok {
+			break
+		}
+
+		for _, s := range seen {
+			if s == args[1] {
+				return nil, fmt.Errorf("%w: %s", ErrAliasCycle, strings.Join(append(seen, args[1]), " -> "))
+			}
+		}
+
+		seen = append(seen, args[1])
+
+		words, err := splitArgs(expansion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %s: %v", args[1], err)
+		}
+
+		expanded := append([]string{args[0]}, words...)
+		args = append(expanded, args[2:]...)
+	}
+
+	return args, nil
+}
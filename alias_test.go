@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	var out strings.Builder
+	var ran []string
+
+	app := NewApp()
+	app.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	app.Stdout = &out
+	app.EnableAliases()
+
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.Flags.String("env", "dev", "the env")
+		c.AppendVarArg("services", "the services")
+	}, func(c *Command) error {
+		ran = []string{c.Flag("env").String()}
+		for _, s := range c.VarArgs() {
+			ran = append(ran, s.String())
+		}
+		return nil
+	}))
+
+	for _, args := range [][]string{
+		{"alias", "set", "dp", "deploy --env prod"},
+		{"alias", "set", "dpw", "dp web"},
+	} {
+		if err := app.Run(append([]string{"app"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := app.Run([]string{"app", "dpw", "api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ran, []string{"prod", "web", "api"}) {
+		t.Fatalf("Unexpected run: %q", ran)
+	}
+
+	if err := app.Run([]string{"app", "alias", "set", "deploy", "dp"}); err == nil {
+		t.Fatal("Expected shadowing a command to fail")
+	}
+
+	if err := app.Run([]string{"app", "alias", "set", "dp", "dpw --force"}); !errors.Is(err, ErrAliasCycle) {
+		t.Fatalf("Expected ErrAliasCycle, got %v", err)
+	}
+
+	if err := app.Run([]string{"app", "alias", "list"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "dp                 deploy --env prod\ndpw                dp web\n"
+	if out.String() != expected {
+		t.Fatalf("Unexpected list:\n%s", out.String())
+	}
+
+	if err := app.Run([]string{"app", "alias", "delete", "dp"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Run([]string{"app", "dpw"}); !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("Expected ErrInvalidCommand after deleting dp, got %v", err)
+	}
+}
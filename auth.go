@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const authSessionKey = "auth/session"
+
+var ErrNotLoggedIn = errors.New("not logged in")
+
+// Session is the stored result of a login.
+type Session struct {
+	User    string            `json:"user"`
+	Token   string            `json:"token"`
+	Expires time.Time         `json:"expires,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Expired reports whether the session has an expiry time that has passed.
+func (s *Session) Expired() bool {
+	return !s.Expires.IsZero() && !time.Now().Before(s.Expires)
+}
+
+// AuthProvider logs users in and out of the service an App talks to.
+type AuthProvider interface {
+	// Login authenticates the user, e.g. by prompting or with a browser
+	// flow, and returns the new session.
+	Login(cmd *Command) (*Session, error)
+
+	// Logout revokes s with the service.
+	Logout(cmd *Command, s *Session) error
+}
+
+// AuthRefresher is implemented by providers that can renew an expired
+// session without the user logging in again.
+type AuthRefresher interface {
+	Refresh(cmd *Command, s *Session) (*Session, error)
+}
+
+// EnableAuth adds login, logout and whoami commands backed by provider.
+// Sessions are stored, readable only by the user, next to the config file.
+func (app *App) EnableAuth(provider AuthProvider) {
+	app.auth = provider
+
+	app.AddCommand(NewCommand("login", "Shell", "Log in", func(*Command) {}, func(cmd *Command) error {
+		s, err := provider.Login(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.authStore().set(authSessionKey, s); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.Stdout, app.trf("Logged in as %s", s.User))
+		return nil
+	}))
+
+	app.AddCommand(NewCommand("logout", "Shell", "Log out and forget the stored session", func(*Command) {}, func(cmd *Command) error {
+		s := &Session{}
+
+		ok, err := app.authStore().get(authSessionKey, s)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			fmt.Fprintln(cmd.Stdout, app.tr("Not logged in"))
+			return nil
+		}
+
+		if err := provider.Logout(cmd, s); err != nil {
+			return err
+		}
+
+		return app.authStore().delete(authSessionKey)
+	}))
+
+	app.AddCommand(NewCommand("whoami", "Shell", "Show the logged in user", func(*Command) {}, func(cmd *Command) error {
+		s, err := cmd.Session()
+		if err != nil {
+			return err
+		}
+
+		if s.Expires.IsZero() {
+			fmt.Fprintln(cmd.Stdout, s.User)
+		} else {
+			fmt.Fprintln(cmd.Stdout, app.trf("%s (session expires %s)", s.User, s.Expires.Format(time.RFC3339)))
+		}
+
+		return nil
+	}))
+}
+
+func (app *App) authStore() *stateStore {
+	return stateStoreFor(filepath.Dir(app.configPath()))
+}
+
+// Session returns the session stored by login. An expired session is
+// refreshed if the provider supports it. It returns ErrNotLoggedIn when
+// there is no usable session.
+func (cmd *Command) Session() (*Session, error) {
+	app := cmd.app
+	if app == nil || app.auth == nil {
+		return nil, ErrNotLoggedIn
+	}
+
+	s := &Session{}
+
+	ok, err := app.authStore().get(authSessionKey, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrNotLoggedIn
+	}
+
+	if !s.Expired() {
+		return s, nil
+	}
+
+	r, ok := app.auth.(AuthRefresher)
+	if !ok {
+		return nil, fmt.Errorf("session expired: %w", ErrNotLoggedIn)
+	}
+
+	if s, err = r.Refresh(cmd, s); err != nil {
+		return nil, fmt.Errorf("refreshing session: %w", err)
+	}
+
+	if err := app.authStore().set(authSessionKey, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAuth struct {
+	expires   time.Time
+	loggedOut []string
+	refreshed int
+}
+
+func (ta *testAuth) Login(cmd *Command) (*Session, error) {
+	return &Session{User: "ada", Token: "t1", Expires: ta.expires}, nil
+}
+
+func (ta *testAuth) Logout(cmd *Command, s *Session) error {
+	ta.loggedOut = append(ta.loggedOut, s.Token)
+	return nil
+}
+
+func (ta *testAuth) Refresh(cmd *Command, s *Session) (*Session, error) {
+	ta.refreshed++
+	return &Session{User: s.User, Token: "t2"}, nil
+}
+
+func TestEnableAuth(t *testing.T) {
+	var out strings.Builder
+	var token string
+
+	provider := &testAuth{expires: time.Now().Add(-time.Minute)}
+
+	app := NewApp()
+	app.Stdout = &out
+	app.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	app.EnableAuth(provider)
+	app.AddCommand(NewCommand("api", "test-group", "call the api", func(*Command) {}, func(c *Command) error {
+		s, err := c.Session()
+		if err != nil {
+			return err
+		}
+
+		token = s.Token
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "api"}); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("Expected ErrNotLoggedIn, got %v", err)
+	}
+
+	if err := app.Run([]string{"app", "login"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Run([]string{"app", "api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "t2" || provider.refreshed != 1 {
+		t.Fatalf("Expected the expired session to be refreshed, got %q after %d refreshes", token, provider.refreshed)
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"app", "whoami"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "ada\n" {
+		t.Fatalf("Unexpected whoami output: %q", out.String())
+	}
+
+	if err := app.Run([]string{"app", "logout"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provider.loggedOut) != 1 || provider.loggedOut[0] != "t2" {
+		t.Fatalf("Expected t2 to be logged out, got %v", provider.loggedOut)
+	}
+
+	if err := app.Run([]string{"app", "whoami"}); !errors.Is(err, ErrNotLoggedIn) {
+		t.Fatalf("Expected ErrNotLoggedIn after logout, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrForbidden = errors.New("not authorized")
+
+// ForbiddenErr is returned when an authorizer rejects a command. Err is the
+// authorizer's reason.
+type ForbiddenErr struct {
+	Command string
+	Err     error
+}
+
+func (fe *ForbiddenErr) Error() string {
+	return fmt.Sprintf("not authorized to run %s: %v", fe.Command, fe.Err)
+}
+
+func (fe *ForbiddenErr) Unwrap() []error {
+	return []error{ErrForbidden, fe.Err}
+}
+
+// ExitCode is 77, EX_NOPERM from sysexits.h.
+func (fe *ForbiddenErr) ExitCode() int {
+	return 77
+}
+
+// authorize runs the App's and the command's authorizers, in that order.
+func (app *App) authorize(cmd *Command) error {
+	for _, auth := range []func(*Command) error{app.Authorize, cmd.Authorize} {
+		if auth == nil {
+			continue
+		}
+
+		if err := auth(cmd); err != nil {
+			return &ForbiddenErr{Command: cmd.Name, Err: err}
+		}
+	}
+
+	return nil
+}
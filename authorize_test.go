@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	var out strings.Builder
+	ran := false
+	role := "viewer"
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Authorize = func(c *Command) error {
+		if c.Annotations["role"] == "admin" && role != "admin" {
+			return errors.New("requires the admin role")
+		}
+		return nil
+	}
+
+	c := NewCommand("purge", "test-group", "purge everything", func(*Command) {}, func(*Command) error {
+		ran = true
+		return nil
+	})
+	c.Annotations = map[string]string{"role": "admin"}
+	app.AddCommand(c)
+
+	err := app.Run([]string{"app", "purge"})
+
+	var fe *ForbiddenErr
+	if !errors.As(err, &fe) || !errors.Is(err, ErrForbidden) || ExitCode(err) != 77 || ran {
+		t.Fatalf("Expected a ForbiddenErr, got %v", err)
+	}
+
+	if want := "not authorized to run purge: requires the admin role"; err.Error() != want {
+		t.Fatalf("Expected %q, got %q", want, err.Error())
+	}
+
+	app.HideUnauthorized = true
+
+	if err := app.Run([]string{"app", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "purge") {
+		t.Fatalf("Expected purge to be hidden:\n%s", out.String())
+	}
+
+	role = "admin"
+
+	if err := app.Run([]string{"app", "purge"}); err != nil || !ran {
+		t.Fatalf("Expected purge to run, got %v", err)
+	}
+
+	c.Authorize = func(*Command) error { return errors.New("maintenance window only") }
+
+	if err := app.Run([]string{"app", "purge"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Expected the command's authorizer to reject, got %v", err)
+	}
+}
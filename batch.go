@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type StepStatus string
+
+const (
+	StepOK      StepStatus = "ok"
+	StepFailed  StepStatus = "failed"
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepResult records the outcome of one command in a chained or batch run.
+type StepResult struct {
+	Index    int
+	Args     []string
+	Status   StepStatus
+	Err      error
+	Duration time.Duration
+}
+
+func (sr StepResult) Name() string {
+	if len(sr.Args) == 0 {
+		return ""
+	}
+
+	return sr.Args[0]
+}
+
+// BatchErr is returned when one or more steps of a batch run failed. Steps
+// holds every step, including the ones that succeeded or were skipped. It
+// unwraps to the errors of the failed steps, so errors.As finds a step's
+// UsageErr and ExitCode reports the first failed step's code.
+type BatchErr struct {
+	Steps []StepResult
+}
+
+func (be *BatchErr) Failed() []StepResult {
+	failed := []StepResult{}
+
+	for _, s := range be.Steps {
+		if s.Status == StepFailed {
+			failed = append(failed, s)
+		}
+	}
+
+	return failed
+}
+
+func (be *BatchErr) Error() string {
+	failed := be.Failed()
+
+	msgs := make([]string, 0, len(failed))
+	for _, s := range failed {
+		msgs = append(msgs, fmt.Sprintf("step %d (%s): %v", s.Index+1, s.Name(), s.Err))
+	}
+
+	return fmt.Sprintf("%d of %d steps failed: %s", len(failed), len(be.Steps), strings.Join(msgs, "; "))
+}
+
+func (be *BatchErr) Unwrap() []error {
+	errs := []error{}
+
+	for _, s := range be.Failed() {
+		errs = append(errs, s.Err)
+	}
+
+	return errs
+}
+
+// RunAll runs each invocation in order within this process, stopping at the
+// first failure. Invocations do not include the program name. When more than
+// one invocation is given a summary table is printed once all steps are done.
+// Usage for a step's UsageErr is left to the caller, as for Run.
+func (app *App) RunAll(invocations [][]string) error {
+	return app.runSteps(context.Background(), invocations, false)
+}
+
+func (app *App) runSteps(ctx context.Context, invocations [][]string, keepGoing bool) error {
+	steps := make([]StepResult, len(invocations))
+	failed := false
+
+	for i, inv := range invocations {
+		steps[i] = StepResult{Index: i, Args: inv}
+
+		if failed && !keepGoing {
+			steps[i].Status = StepSkipped
+			continue
+		}
+
+		start := time.Now()
+		err := app.RunContext(ctx, append([]string{app.name()}, inv...))
+		steps[i].Duration = time.Since(start)
+
+		if err != nil {
+			steps[i].Status = StepFailed
+			steps[i].Err = err
+			failed = true
+		} else {
+			steps[i].Status = StepOK
+		}
+	}
+
+	if len(steps) > 1 {
+		printSummary(app.stderrIn(ctx), steps)
+	}
+
+	if failed {
+		return &BatchErr{Steps: steps}
+	}
+
+	return nil
+}
+
+func printSummary(w io.Writer, steps []StepResult) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\nCOMMAND\tSTATUS\tDURATION")
+
+	for _, s := range steps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.Join(s.Args, " "), s.Status, s.Duration.Round(time.Millisecond))
+	}
+
+	tw.Flush()
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAppRunAll(t *testing.T) {
+	ran := []string{}
+
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Stderr = io.Discard
+	app.AddCommand(NewCommand("ok", "test-group", "succeeds", func(c *Command) {}, func(c *Command) error {
+		ran = append(ran, "ok")
+		return nil
+	}))
+	app.AddCommand(NewCommand("fail", "test-group", "fails", func(c *Command) {}, func(c *Command) error {
+		ran = append(ran, "fail")
+		return errors.New("boom")
+	}))
+
+	if err := app.RunAll([][]string{{"ok"}, {"ok"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	ran = ran[:0]
+
+	err := app.RunAll([][]string{{"ok"}, {"fail"}, {"ok"}})
+
+	be, ok := err.(*BatchErr)
+	if !ok {
+		t.Fatalf("Expected a batch error, got %v", err)
+	}
+
+	if len(ran) != 2 {
+		t.Fatalf("Expected 2 steps to run, got %v", ran)
+	}
+
+	failed := be.Failed()
+	if len(failed) != 1 || failed[0].Index != 1 || failed[0].Name() != "fail" {
+		t.Fatalf("Unexpected failed steps %+v", failed)
+	}
+
+	if be.Steps[2].Status != StepSkipped {
+		t.Fatalf("Expected last step to be skipped, got %s", be.Steps[2].Status)
+	}
+
+	err = app.RunAll([][]string{{"ok"}, {"ok", "--bogus"}})
+
+	var ue *UsageErr
+	if !errors.As(err, &ue) || ExitCode(err) != 2 {
+		t.Fatalf("Expected the step's usage error to be found, got %v", err)
+	}
+
+	if out.Len() != 0 {
+		t.Fatalf("Expected usage to be left to the caller, got %q", out.String())
+	}
+}
+
+func TestAppBefore(t *testing.T) {
+	calls := 0
+	token := ""
+
+	app := NewApp()
+	app.Stderr = io.Discard
+	app.Before = func(app *App) error {
+		calls++
+		if calls == 1 {
+			return errors.New("no credentials")
+		}
+
+		token = "abc"
+		return nil
+	}
+
+	app.AddCommand(NewCommand("use", "test-group", "uses the token", func(c *Command) {}, func(c *Command) error {
+		if token == "" {
+			return errors.New("setup did not run")
+		}
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "use"}); err == nil || err.Error() != "no credentials" {
+		t.Fatalf("Expected the setup error, got %v", err)
+	}
+
+	if err := app.RunAll([][]string{{"use"}, {"use"}, {"use"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected setup to run twice, got %d", calls)
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+func (cmd *Command) cacheable() bool {
+	return cmd.CacheTTL > 0 && (cmd.noCacheFlag == nil || !*cmd.noCacheFlag) && !cmd.readsStdin()
+}
+
+// readsStdin reports whether a stdin arg was given "-". The input isn't
+// part of the cache key, so such runs aren't cached.
+func (cmd *Command) readsStdin() bool {
+	for i, a := range cmd.Args {
+		if a.Stdin && cmd.Flags.Arg(i) == "-" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (app *App) resultCacheDir() string {
+	return filepath.Join(app.cacheDir(), "results")
+}
+
+// resultCachePath is where the output of the current run of cmd is cached,
+// named by a hash of everything that can change it: the version, the profile,
+// flags and args, and the env the command reads or was given for the run.
+func (app *App) resultCachePath(cmd *Command) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s\x00profile %s\x00", app.Version, cmd.profile)
+
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if f.Name != "no-cache" {
+			fmt.Fprintf(h, "flag %s=%s\x00", f.Name, f.Value)
+		}
+	})
+
+	for _, a := range cmd.Flags.Args() {
+		fmt.Fprintf(h, "arg %s\x00", a)
+	}
+
+	env := map[string]bool{}
+	for n := range cmd.EnvArgs {
+		env[n] = true
+	}
+
+	for n := range cmd.optionalEnv {
+		env[n] = true
+	}
+
+	for n := range cmd.envOverlay {
+		env[n] = true
+	}
+
+	names := make([]string, 0, len(env))
+	for n := range env {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		fmt.Fprintf(h, "env %s=%s\x00", n, cmd.getenv(n))
+	}
+
+	return filepath.Join(app.resultCacheDir(), cmd.Name, hex.EncodeToString(h.Sum(nil)))
+}
+
+// withCache writes the cached output at path instead of running the command
+// if it is younger than ttl, and otherwise caches the output of a successful
+// run.
+func withCache(run RunFunc, path string, ttl time.Duration) RunFunc {
+	return func(cmd *Command) error {
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) < ttl {
+			if b, err := os.ReadFile(path); err == nil {
+				_, err = cmd.Stdout.Write(b)
+				return err
+			}
+		}
+
+		var buf bytes.Buffer
+
+		stdout := cmd.Stdout
+		cmd.Stdout = io.MultiWriter(stdout, &buf)
+		err := run(cmd)
+		cmd.Stdout = stdout
+
+		if err == nil {
+			// A failure to cache only costs the next run its speed
+			writeCacheFile(path, buf.Bytes())
+		}
+
+		return err
+	}
+}
+
+func writeCacheFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// EnableCache registers a cache command for clearing the output cached for
+// commands with a CacheTTL:
+//
+//	app cache clear [command...]
+func (app *App) EnableCache() {
+	app.AddCommand(NewCommand("cache", "Shell", "Manage cached command output: clear [command...]",
+		func(cmd *Command) {
+			cmd.AppendVarArg("action", "clear, optionally followed by the commands to clear")
+		},
+		func(cmd *Command) error {
+			args := cmd.VarArgs()
+			if args[0].String() != "clear" {
+				return cmd.usageErr("Invalid cache action", ErrArgCount)
+			}
+
+			if len(args) == 1 {
+				return os.RemoveAll(app.resultCacheDir())
+			}
+
+			for _, a := range args[1:] {
+				c, ok := app.resolve(a.String())
+				if !ok {
+					return fmt.Errorf("no command named %q", a)
+				}
+
+				if err := os.RemoveAll(filepath.Join(app.resultCacheDir(), c.Name)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}))
+}
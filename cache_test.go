@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCmdCacheTTL(t *testing.T) {
+	runs := 0
+
+	app := NewApp()
+	app.CacheDir = t.TempDir()
+	app.EnableCache()
+
+	c := NewCommand("list-regions", "test-group", "lists regions", func(c *Command) {
+		c.Flags.String("cloud", "aws", "the cloud")
+	}, func(c *Command) error {
+		runs++
+		fmt.Fprintf(c.Stdout, "%s run %d\n", c.Flag("cloud"), runs)
+		return nil
+	})
+	c.CacheTTL = time.Hour
+	app.AddCommand(c)
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	for _, tc := range []struct {
+		args   []string
+		output string
+	}{
+		{[]string{"list-regions"}, "aws run 1\n"},
+		{[]string{"list-regions"}, "aws run 1\n"},
+		{[]string{"list-regions", "--cloud", "gcp"}, "gcp run 2\n"},
+		{[]string{"list-regions", "--no-cache"}, "aws run 3\n"},
+		{[]string{"list-regions", "--cloud", "gcp"}, "gcp run 2\n"},
+		{[]string{"cache", "clear", "list-regions"}, ""},
+		{[]string{"list-regions", "--cloud", "gcp"}, "gcp run 4\n"},
+	} {
+		out.Reset()
+
+		if err := app.Run(append([]string{"app"}, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != tc.output {
+			t.Fatalf("Expected %q for %q, got %q", tc.output, tc.args, out.String())
+		}
+	}
+}
+
+func TestCmdCacheKey(t *testing.T) {
+	runs := 0
+
+	app := NewApp()
+	app.CacheDir = t.TempDir()
+	app.Version = "1.0.0"
+
+	c := NewCommand("count", "test-group", "counts lines", func(c *Command) {
+		c.AppendStdinArg("file", "the file", PathOptions{})
+	}, func(c *Command) error {
+		runs++
+
+		r, err := c.ArgReader("file")
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		fmt.Fprintf(c.Stdout, "%d %d\n", strings.Count(string(b), "\n"), runs)
+		return err
+	})
+	c.CacheTTL = time.Hour
+	app.AddCommand(c)
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	for _, tc := range []struct {
+		stdin  string
+		output string
+	}{
+		{"a\n", "1 1\n"},
+		{"a\nb\n", "2 2\n"},
+	} {
+		out.Reset()
+		app.Stdin = strings.NewReader(tc.stdin)
+
+		if err := app.Run([]string{"app", "count", "-"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != tc.output {
+			t.Fatalf("Expected %q for %q, got %q", tc.output, tc.stdin, out.String())
+		}
+	}
+
+	key := app.resultCachePath(c)
+	app.Version = "2.0.0"
+
+	if app.resultCachePath(c) == key {
+		t.Fatal("Expected the version to be part of the cache key")
+	}
+}
+
+func TestCmdCacheProfile(t *testing.T) {
+	app := NewApp()
+	app.CacheDir = t.TempDir()
+	app.Getenv = func(string) string { return "" }
+	app.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(app.ConfigFile, []byte("profiles:\n  staging:\n    env:\n      REGION: us\n  prod:\n    env:\n      REGION: eu\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app.EnableProfiles()
+
+	c := NewCommand("region", "test-group", "prints the region", func(c *Command) {
+		c.AddOptionalEnvArg("REGION", "the region")
+	}, func(c *Command) error {
+		fmt.Fprintln(c.Stdout, c.EnvArg("REGION"))
+		return nil
+	})
+	c.CacheTTL = time.Hour
+	app.AddCommand(c)
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	for _, profile := range []string{"staging", "prod", "staging"} {
+		if err := app.Run([]string{"app", "--profile", profile, "region"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if out.String() != "us\neu\nus\n" {
+		t.Fatalf("Expected output cached per profile, got %q", out.String())
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import "math/rand"
+
+// Canary routes a random fraction of invocations to an alternate
+// implementation or through extra validation, so risky rewrites can be rolled
+// out gradually.
+type Canary struct {
+	// Fraction of invocations, between 0 and 1, that take the canary path.
+	Fraction float64
+
+	// Run replaces the command's Run for canary invocations. When nil the
+	// regular Run is used.
+	Run RunFunc
+
+	// Validate is called before Run on canary invocations; an error aborts
+	// the run.
+	Validate func(cmd *Command) error
+
+	// Rand returns the number in [0, 1) compared with Fraction for each
+	// invocation. It defaults to math/rand's Float64.
+	Rand func() float64
+}
+
+func (c *Canary) rand() float64 {
+	if c.Rand != nil {
+		return c.Rand()
+	}
+
+	return rand.Float64()
+}
+
+// InCanary reports whether the current run took the canary path.
+func (cmd *Command) InCanary() bool {
+	return cmd.inCanary
+}
+
+func (cmd *Command) canaryRun() RunFunc {
+	cmd.inCanary = cmd.Canary != nil && cmd.Canary.Fraction > 0 && cmd.Canary.rand() < cmd.Canary.Fraction
+
+	if !cmd.inCanary {
+		return cmd.Run
+	}
+
+	run := cmd.Run
+	if cmd.Canary.Run != nil {
+		run = cmd.Canary.Run
+	}
+
+	validate := cmd.Canary.Validate
+	if validate == nil {
+		return run
+	}
+
+	return func(cmd *Command) error {
+		if err := validate(cmd); err != nil {
+			return err
+		}
+
+		return run(cmd)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCmdCanary(t *testing.T) {
+	var ran string
+	var inCanary bool
+
+	app := NewApp()
+
+	c := NewCommand("sync", "test-group", "syncs things", func(c *Command) {}, func(c *Command) error {
+		ran = "stable"
+		inCanary = c.InCanary()
+		return nil
+	})
+	c.Canary = &Canary{
+		Fraction: 0.1,
+		Run: func(c *Command) error {
+			ran = "canary"
+			inCanary = c.InCanary()
+			return nil
+		},
+	}
+	app.AddCommand(c)
+
+	c.Canary.Rand = func() float64 { return 0.5 }
+
+	if err := app.Run([]string{"app", "sync"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ran != "stable" || inCanary {
+		t.Fatalf("Expected the stable path, got %s", ran)
+	}
+
+	c.Canary.Rand = func() float64 { return 0.05 }
+
+	if err := app.Run([]string{"app", "sync"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ran != "canary" || !inCanary {
+		t.Fatalf("Expected the canary path, got %s", ran)
+	}
+
+	errInvalid := errors.New("invalid")
+	c.Canary.Validate = func(c *Command) error {
+		return errInvalid
+	}
+
+	ran = ""
+
+	if err := app.Run([]string{"app", "sync"}); err != errInvalid || ran != "" {
+		t.Fatalf("Expected validation to abort the run, got %v", err)
+	}
+}
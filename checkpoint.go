@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"sort"
+	"sync"
+)
+
+// Checkpoint tracks which items of a batch have been completed so that an
+// interrupted command can resume where it left off. Completed items are
+// persisted in the app's state store under the command name and key.
+type Checkpoint struct {
+	key   string
+	store *stateStore
+
+	mu   sync.Mutex
+	done map[string]bool
+	err  error
+}
+
+func (cmd *Command) Checkpoint(key string) *Checkpoint {
+	cp := &Checkpoint{
+		key:   "checkpoint/" + cmd.Name + "/" + key,
+		store: stateStoreFor(cmd.app.dataDir()),
+		done:  map[string]bool{},
+	}
+
+	items := []string{}
+	if _, err := cp.store.get(cp.key, &items); err != nil {
+		cp.err = err
+	}
+
+	for _, item := range items {
+		cp.done[item] = true
+	}
+
+	return cp
+}
+
+// Err returns the error, if any, encountered loading the checkpoint.
+func (cp *Checkpoint) Err() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	return cp.err
+}
+
+func (cp *Checkpoint) Seen(item string) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	return cp.done[item]
+}
+
+// Remaining returns the items that have not been marked done, in order.
+func (cp *Checkpoint) Remaining(items []string) []string {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	ret := []string{}
+
+	for _, item := range items {
+		if !cp.done[item] {
+			ret = append(ret, item)
+		}
+	}
+
+	return ret
+}
+
+// Done marks item as completed and persists the checkpoint.
+func (cp *Checkpoint) Done(item string) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.done[item] {
+		return nil
+	}
+
+	cp.done[item] = true
+
+	items := make([]string, 0, len(cp.done))
+	for i := range cp.done {
+		items = append(items, i)
+	}
+
+	sort.Strings(items)
+
+	return cp.store.set(cp.key, items)
+}
+
+// Reset forgets all completed items, typically once the batch finishes.
+func (cp *Checkpoint) Reset() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.done = map[string]bool{}
+
+	return cp.store.delete(cp.key)
+}
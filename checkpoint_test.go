@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	app := NewApp()
+	app.DataDir = t.TempDir()
+
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {}, nil)
+	app.AddCommand(c)
+
+	cp := c.Checkpoint("files")
+	if err := cp.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cp.Done("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cp.Done("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh checkpoint should see the persisted items
+	cp = c.Checkpoint("files")
+
+	if !cp.Seen("a") || cp.Seen("b") {
+		t.Fatal("Unexpected seen items")
+	}
+
+	if r := cp.Remaining([]string{"a", "b", "c", "d"}); !reflect.DeepEqual(r, []string{"b", "d"}) {
+		t.Fatalf("Unexpected remaining items %v", r)
+	}
+
+	if err := cp.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Checkpoint("files").Seen("a") {
+		t.Fatal("Expected checkpoint to be reset")
+	}
+}
@@ -1,18 +1,35 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"os"
+	"io"
+	"log/slog"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Arg struct {
 	Name        string
 	Description string
 	Variable    bool
+
+	// Glob makes a variable arg expand glob patterns like "*.log" itself,
+	// for shells such as Windows' that don't. See AppendGlobVarArg.
+	Glob bool
+
+	// Stdin makes "-" stand for the standard input. See ArgReader.
+	Stdin bool
+
+	// KV makes a variable arg take key=value pairs. See KVArgs.
+	KV bool
+
+	path *pathArg
 }
 
 type Value string
@@ -38,6 +55,13 @@ func (v Value) Uint64() (uint64, error) {
 	return strconv.ParseUint(string(v), 10, 64)
 }
 
+var (
+	ErrNoCommand      = errors.New("no command given")
+	ErrInvalidCommand = errors.New("invalid command")
+	ErrArgCount       = errors.New("wrong number of command arguments")
+	ErrEnvUnset       = errors.New("environment variable unset")
+)
+
 type SetupFunc func(cmd *Command)
 type RunFunc func(cmd *Command) error
 
@@ -50,6 +74,130 @@ type Command struct {
 	Flags       *flag.FlagSet
 	Setup       SetupFunc
 	Run         RunFunc
+
+	// Stdin, Stdout and Stderr are the command's standard streams. When nil
+	// they are inherited from the App; during Run they are always set.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Getenv looks up environment variables for EnvArg. When nil the App's
+	// Getenv is used.
+	Getenv func(name string) string
+
+	// Keywords are extra search terms matched by App.Search.
+	Keywords []string
+
+	// Hidden commands can be run but are left out of help and search.
+	Hidden bool
+
+	// Examples are sample invocations shown in help, without the program
+	// name, e.g. "deploy --force web".
+	Examples []string
+
+	// Annotations hold arbitrary metadata for tooling, such as
+	// "requires-auth" or "stability". They are included in Describe.
+	Annotations map[string]string
+
+	// Stability is shown in help. Alpha commands can be hidden with
+	// App.HideAlpha.
+	Stability Stability
+
+	// Authorize is called before Run, after App.Authorize. An error stops
+	// the run with a ForbiddenErr.
+	Authorize func(cmd *Command) error
+
+	// Platforms limits the command to the listed GOOS or GOOS/GOARCH
+	// pairs, e.g. "linux" or "darwin/arm64". An empty list allows all.
+	Platforms    []string
+	Requirements []Requirement
+
+	// Timeout bounds the run time of Run, including retries. Zero means
+	// no limit.
+	Timeout time.Duration
+	Retry   *RetryPolicy
+
+	ExecEnv *ExecEnv
+	Canary  *Canary
+
+	// Destructive adds --yes/-y flags which make Confirm succeed without
+	// asking, and a --confirm flag which answers ConfirmPhrase.
+	Destructive bool
+
+	// SoftFail adds a --keep-going flag which makes EachVarArg process
+	// every item and report the failures at the end.
+	SoftFail bool
+
+	// Repeatable adds an --every flag which runs the command at an interval
+	// until interrupted, and --until-success and --max-runs flags to stop
+	// sooner.
+	Repeatable bool
+
+	// SupportsDryRun adds a --dry-run flag. See DryRun and Action.
+	SupportsDryRun bool
+
+	// TimeParser configures how ParseTime reads timestamps. When nil the
+	// defaults of TimeParser are used.
+	TimeParser *TimeParser
+
+	// CacheTTL caches the output of successful runs for this long, keyed
+	// by the command's args, flags and env args, and adds a --no-cache flag
+	// to bypass it. Only use it for read-only commands.
+	CacheTTL time.Duration
+
+	// Exclusive prevents two runs of the command from overlapping, using a
+	// lockfile in the app's runtime directory.
+	Exclusive bool
+
+	app        *App
+	secrets    map[string]bool
+	provenance map[string]Provenance
+	ctx        context.Context
+	shutdown   shutdownHooks
+
+	timeoutFlag *time.Duration
+	yesFlag     *bool
+	confirmFlag *string
+	follow      *followFlags
+	inCanary    bool
+	outputFlag  *string
+	quietFlag   *bool
+	verboseFlag *bool
+
+	keepGoingFlag *bool
+	colorFlag     *toggleValue
+	dryRunFlag    *bool
+	watchFlag     *string
+	repeat        *repeatFlags
+	pprof         *pprofFlags
+	hiddenFlags   map[string]bool
+	timingsFlag   *bool
+	noCacheFlag   *bool
+	checks        []check
+	factory       func() *Command
+	mount         *App
+	once          sync.Once
+	built         *Command
+	runMu         sync.Mutex
+	texts         map[string]CommandText
+
+	argValidators  map[string][]Validator
+	flagValidators map[string][]Validator
+	metavars       map[string]string
+	flagGroups     map[string]string
+	flagGroupOrder []string
+	commonApplied  int
+	optionalEnv    map[string]string
+
+	// envOverlay holds env values set for this run by a profile or a prompt
+	envOverlay map[string]string
+
+	// profile is the name of the profile applied to this run, if any
+	profile string
+
+	// noProfile keeps the config commands usable when the profile is
+	// missing or broken
+	noProfile bool
 }
 
 func NewCommand(name, group, desc string, setup SetupFunc, run RunFunc) *Command {
@@ -57,11 +205,15 @@ func NewCommand(name, group, desc string, setup SetupFunc, run RunFunc) *Command
 		Name:        name,
 		Description: desc,
 		Group:       group,
-		Flags:       flag.NewFlagSet(name, flag.ExitOnError),
+		Flags:       flag.NewFlagSet(name, flag.ContinueOnError),
 		Args:        []*Arg{},
 		EnvArgs:     map[string]string{},
 	}
 
+	// Bad flags are returned from Parse as a *UsageErr, which shows the
+	// command's own usage
+	cmd.Flags.Usage = func() {}
+
 	cmd.Setup = setup
 	cmd.Run = run
 
@@ -69,17 +221,34 @@ func NewCommand(name, group, desc string, setup SetupFunc, run RunFunc) *Command
 }
 
 func (cmd *Command) AppendArg(name, desc string) {
-	cmd.Args = append(cmd.Args, &Arg{name, desc, false})
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc})
 }
 
 func (cmd *Command) AppendVarArg(name, desc string) {
-	cmd.Args = append(cmd.Args, &Arg{name, desc, true})
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc, Variable: true})
+}
+
+// AppendGlobVarArg adds a variable arg whose values are expanded as glob
+// patterns, where "**" matches any number of directories. The files are
+// sorted and de-duplicated, and a pattern matching nothing is an error.
+func (cmd *Command) AppendGlobVarArg(name, desc string) {
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc, Variable: true, Glob: true})
 }
 
 func (cmd *Command) AddEnvArg(name, desc string) {
 	cmd.EnvArgs[name] = desc
 }
 
+// AddOptionalEnvArg documents an environment variable the command reads
+// but doesn't require, such as one overriding a default.
+func (cmd *Command) AddOptionalEnvArg(name, desc string) {
+	if cmd.optionalEnv == nil {
+		cmd.optionalEnv = map[string]string{}
+	}
+
+	cmd.optionalEnv[name] = desc
+}
+
 func (cmd *Command) Arg(name string) Value {
 	for i, ca := range cmd.Args {
 		if ca.Name == name {
@@ -90,14 +259,29 @@ func (cmd *Command) Arg(name string) Value {
 	return ""
 }
 
+// Context returns the context of the current run. It is canceled when the
+// run is interrupted.
+func (cmd *Command) Context() context.Context {
+	if cmd.ctx == nil {
+		return context.Background()
+	}
+
+	return cmd.ctx
+}
+
 func (cmd *Command) EnvArg(name string) Value {
-	return Value(strings.TrimSpace(os.Getenv(name)))
+	return Value(strings.TrimSpace(cmd.getenv(name)))
 }
 
 func (cmd *Command) VarArgs() []Value {
 	ret := []Value{}
 
-	for _, a := range cmd.Flags.Args()[len(cmd.Args)-1:] {
+	start := len(cmd.Args) - 1
+	if start < 0 || start > cmd.Flags.NArg() {
+		return ret
+	}
+
+	for _, a := range cmd.Flags.Args()[start:] {
 		ret = append(ret, Value(a))
 	}
 
@@ -108,27 +292,38 @@ func (cmd *Command) Flag(name string) Value {
 	return Value(cmd.Flags.Lookup(name).Value.String())
 }
 
+// HideFlag leaves the flag called name out of help, descriptions and
+// completion. It can still be given, which suits experimental flags and
+// debugging knobs.
+func (cmd *Command) HideFlag(name string) {
+	if cmd.hiddenFlags == nil {
+		cmd.hiddenFlags = map[string]bool{}
+	}
+
+	cmd.hiddenFlags[name] = true
+}
+
 func (cmd *Command) Parse(args []string) error {
-	cmd.Flags.Parse(args)
+	if err := cmd.Flags.Parse(cmd.negateFlags(args)); err != nil {
+		return cmd.usageErr(err.Error(), err)
+	}
 
-	varArgs := false
-	for _, arg := range cmd.Args {
-		if arg.Variable {
-			varArgs = true
-			break
-		}
+	if err := cmd.expandGlobs(cmd.Flags); err != nil {
+		return err
 	}
 
-	if !varArgs && len(cmd.Flags.Args()) != len(cmd.Args) {
-		return newUsageErr("Wrong number of command arguments", cmd.Usage)
-	} else if varArgs && len(cmd.Flags.Args()) < len(cmd.Args) {
-		return newUsageErr("Wrong number of command arguments", cmd.Usage)
+	if err := cmd.checkArgCount(cmd.Flags.NArg()); err != nil {
+		return err
+	}
+
+	if err := cmd.checkValues(cmd.Flags); err != nil {
+		return err
 	}
 
 	if len(cmd.EnvArgs) > 0 {
 		for n := range cmd.EnvArgs {
 			if cmd.EnvArg(n) == "" {
-				return newUsageErr(fmt.Sprintf("Environment variable %s is unset", n), cmd.Usage)
+				return cmd.usageErr(cmd.app.trf("Environment variable %s is unset", n), ErrEnvUnset)
 			}
 		}
 	}
@@ -136,53 +331,125 @@ func (cmd *Command) Parse(args []string) error {
 	return nil
 }
 
+// reset returns the flags to their defaults and forgets the positional args,
+// provenance and context of the previous run, so every run of a long-lived
+// Command starts clean. Flag values are reset in place, so pointers
+// returned when flags were defined stay valid.
+func (cmd *Command) reset() {
+	fs := flag.NewFlagSet(cmd.Flags.Name(), cmd.Flags.ErrorHandling())
+	fs.SetOutput(cmd.Flags.Output())
+	fs.Usage = cmd.Flags.Usage
+
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		resetValue(f.Value, f.DefValue)
+		fs.Var(f.Value, f.Name, f.Usage)
+		fs.Lookup(f.Name).DefValue = f.DefValue
+	})
+
+	cmd.Flags = fs
+	cmd.provenance = nil
+	cmd.envOverlay = nil
+	cmd.profile = ""
+	cmd.ctx = nil
+}
+
 func (cmd *Command) Usage() {
+	w := cmd.stdout()
+	color := cmd.ColorEnabled()
+	tr, ltr := cmd.app.tr, cmd.app.ltr
+
 	usageStr := ""
 	cmdDesc := ""
 
 	for _, a := range cmd.Args {
+		desc := cmd.argDescription(a)
+		if a.Stdin {
+			desc += " " + tr(`(use "-" to read standard input)`)
+		}
+
 		if a.Variable {
 			usageStr += a.Name + "... "
-			cmdDesc += fmt.Sprintf("    %s[...]: %s\n", a.Name, a.Description)
+			cmdDesc += fmt.Sprintf("    %s: %s\n", ltr(a.Name+"[...]"), desc)
 		} else {
 			usageStr += a.Name + " "
-			cmdDesc += fmt.Sprintf("    %s: %s\n", a.Name, a.Description)
+			cmdDesc += fmt.Sprintf("    %s: %s\n", ltr(a.Name), desc)
 		}
 	}
 
 	fc := 0
-	flagsStr := "Flags:\n"
+	groupStrs := map[string]string{}
 
 	visitFunc := func(flag *flag.Flag) {
-		flagsStr += fmt.Sprintf("    %s: %s\n", flag.Name, flag.Usage)
+		if cmd.hiddenFlags[flag.Name] {
+			return
+		}
+
+		groupStrs[cmd.flagGroups[flag.Name]] += fmt.Sprintf("    %s: %s\n", ltr(cmd.flagLabel(flag)), cmd.flagUsage(flag))
 		fc++
 	}
 
 	cmd.Flags.VisitAll(visitFunc)
 
-	usageflagStr := " [flags]"
+	usageflagStr := " " + tr("[flags]")
 	if fc == 0 {
 		usageflagStr = ""
 	}
 
-	fmt.Printf("usage: %s %s%s %s\n\n", os.Args[0], cmd.Name, usageflagStr, usageStr)
+	fmt.Fprintf(w, "%s %s\n\n", tr("usage:"), ltr(fmt.Sprintf("%s %s%s %s", cmd.app.name(), cmd.Name, usageflagStr, usageStr)))
 
-	fmt.Printf("%s\n\n", cmd.Description)
+	fmt.Fprintf(w, "%s\n\n", cmd.describeStability())
 
 	if len(cmd.Args) > 0 {
-		fmt.Println("Command Arguments:")
-		fmt.Println(cmdDesc)
+		fmt.Fprintln(w, bold(tr("Command Arguments:"), color))
+		fmt.Fprintln(w, cmdDesc)
+	}
+
+	if s := groupStrs[""]; s != "" {
+		fmt.Fprintln(w, bold(tr("Flags:"), color)+"\n"+s)
 	}
 
-	if fc > 0 {
-		fmt.Println(flagsStr)
+	for _, g := range cmd.flagGroupOrder {
+		if s := groupStrs[g]; s != "" {
+			fmt.Fprintln(w, bold(tr(g+":"), color)+"\n"+s)
+		}
+	}
+
+	envSection := func(title string, env map[string]string) {
+		fmt.Fprintln(w, bold(tr(title), color))
+
+		names := make([]string, 0, len(env))
+		for n := range env {
+			names = append(names, n)
+		}
+
+		sort.Strings(names)
+
+		for _, n := range names {
+			fmt.Fprintf(w, "    %s: %s\n", ltr(n), cmd.envArgDescription(n))
+		}
 	}
 
 	if len(cmd.EnvArgs) > 0 {
-		fmt.Println("Required environment variables:")
+		envSection("Required environment variables:", cmd.EnvArgs)
+	}
 
-		for n, d := range cmd.EnvArgs {
-			fmt.Printf("    %s: %s\n", n, d)
+	if len(cmd.optionalEnv) > 0 {
+		if len(cmd.EnvArgs) > 0 {
+			fmt.Fprintln(w)
+		}
+
+		envSection("Optional environment variables:", cmd.optionalEnv)
+	}
+
+	if len(cmd.Examples) > 0 {
+		if len(cmd.EnvArgs) > 0 || len(cmd.optionalEnv) > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w, bold(tr("Examples:"), color))
+
+		for _, e := range cmd.Examples {
+			fmt.Fprintf(w, "    %s\n", ltr(cmd.app.name()+" "+e))
 		}
 	}
 }
@@ -190,15 +457,41 @@ func (cmd *Command) Usage() {
 type UsageErr struct {
 	errMsg    string
 	showUsage func()
+	hint      string
+	err       error
+	cmd       *Command
+	app       *App
 }
 
 func (ue *UsageErr) Error() string {
 	return ue.errMsg
 }
 
+// Unwrap returns the underlying cause, such as ErrArgCount.
+func (ue *UsageErr) Unwrap() error {
+	return ue.err
+}
+
+// Command returns the command the error relates to, or nil when the error
+// occurred before a command was resolved.
+func (ue *UsageErr) Command() *Command {
+	return ue.cmd
+}
+
 func (ue *UsageErr) ShowUsage() {
-	fmt.Println(ue.errMsg)
-	fmt.Println()
+	w := ue.app.stdout()
+	if ue.cmd != nil {
+		w = ue.cmd.stdout()
+	}
+
+	fmt.Fprintln(w, ue.errMsg)
+
+	if ue.hint != "" {
+		fmt.Fprintln(w, ue.hint)
+		return
+	}
+
+	fmt.Fprintln(w)
 
 	if ue.showUsage != nil {
 		ue.showUsage()
@@ -213,62 +506,693 @@ func newUsageErr(msg string, f func()) *UsageErr {
 	return &UsageErr{errMsg: msg, showUsage: f}
 }
 
+func (cmd *Command) usageErr(msg string, err error) *UsageErr {
+	ue := newUsageErr(cmd.app.tr(msg), cmd.Usage)
+	ue.err = err
+	ue.cmd = cmd
+	ue.app = cmd.app
+	return ue
+}
+
 type App struct {
 	Commands    map[string]*Command
 	Description string
+
+	// Name is the program name used in help output and for the app's
+	// directories. It defaults to the base name of os.Args[0].
+	Name string
+
+	// Version is the app's semantic version, e.g. "1.4.2".
+	Version string
+
+	// Stdin, Stdout and Stderr are the app's standard streams. They default
+	// to the process's.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Getenv looks up environment variables. It defaults to os.Getenv.
+	Getenv func(name string) string
+
+	// DataDir overrides the directory used for persistent state. It
+	// defaults to a per-user data directory named after the program.
+	DataDir string
+
+	// RuntimeDir overrides the directory used for lockfiles.
+	RuntimeDir string
+
+	// CacheDir overrides the directory used for cached data. It defaults to
+	// a per-user cache directory named after the program.
+	CacheDir string
+
+	// ConfigFile overrides the path of the config file. It defaults to
+	// config.yaml in a per-user config directory named after the program.
+	ConfigFile string
+
+	// SystemConfigFile and ProjectConfigFile override the paths of the
+	// config files layered under and over ConfigFile. They default to
+	// /etc/<name>/config.yaml (%ProgramData%\<name>\config.yaml on
+	// Windows) and .<name>.yaml in the working directory.
+	SystemConfigFile  string
+	ProjectConfigFile string
+
+	// ProjectConfig enables the project config file. It is off by default
+	// since the file comes from whatever directory the app is run in, and
+	// even when on it can't set the profile, profiles or aliases.
+	ProjectConfig bool
+
+	// Exclusive prevents any two commands of the app from running at the
+	// same time.
+	Exclusive bool
+
+	// HandleSignals installs SIGINT/SIGTERM handlers around each run. A
+	// signal cancels the command's context and runs its OnShutdown hooks;
+	// if the command has not returned within ShutdownGrace the process
+	// exits.
+	HandleSignals bool
+	ShutdownGrace time.Duration
+
+	// WatchInterval is how often --watch checks for changes, and
+	// WatchDebounce how long the files must then stay unchanged before
+	// the command runs again. They default to 250ms and 200ms.
+	WatchInterval time.Duration
+	WatchDebounce time.Duration
+
+	// DaemonStopTimeout bounds how long the stop command added by
+	// EnableDaemon waits for the daemon to exit. It defaults to 10s.
+	DaemonStopTimeout time.Duration
+
+	// UpdateNoticeInterval is how often EnableUpdateNotice checks for a new
+	// release. It defaults to a day.
+	UpdateNoticeInterval time.Duration
+
+	// LogFlags adds --quiet/-q and --verbose/-v flags to every command,
+	// controlling the level of the command's Log.
+	LogFlags bool
+
+	// LogHandler, when set, receives every message logged through a
+	// command's Log instead of the built-in handlers.
+	LogHandler slog.Handler
+
+	// ProfileFlags adds hidden --cpuprofile, --memprofile and --trace flags
+	// to every command, which write profiles of the run to the given files.
+	ProfileFlags bool
+
+	// TimingsFlag adds a --timings flag to every command, which prints how
+	// long the command took once it is done.
+	TimingsFlag bool
+
+	// OnCommandComplete is called after every command run with its result.
+	OnCommandComplete func(CommandResult)
+
+	// ColorFlag adds a --color=auto|always|never flag to every command.
+	ColorFlag bool
+
+	// OutputFlag adds --output/-o flags to every command, selecting the
+	// format Emit renders in. DefaultOutput is the format used when the
+	// flag is not given.
+	OutputFlag    bool
+	DefaultOutput string
+
+	// TimeoutFlag adds a --timeout flag to every command, defaulting to
+	// the command's Timeout.
+	TimeoutFlag bool
+
+	// ContainerImage is suggested to users who run a command on a
+	// platform it does not support.
+	ContainerImage string
+
+	// AssumeYes answers yes to every Confirm, as if --yes was given.
+	AssumeYes bool
+
+	// HideAlpha hides and refuses alpha commands unless --include-alpha is
+	// given or CMD_INCLUDE_ALPHA is set.
+	HideAlpha bool
+
+	// Authorize is called before every command's Run, e.g. to check the
+	// command's Annotations against the user's role. An error stops the run
+	// with a ForbiddenErr.
+	Authorize func(cmd *Command) error
+
+	// HideUnauthorized leaves commands the authorizers reject out of help,
+	// search and completion.
+	HideUnauthorized bool
+
+	// Interactive overrides whether stdin is treated as a terminal for
+	// prompts, and Color whether output is colored.
+	Interactive Toggle
+	Color       Toggle
+
+	// PromptMissing asks for missing arguments and environment variables
+	// when stdin is a terminal, rather than failing with a usage error.
+	PromptMissing bool
+
+	// CompactUsageErrors makes usage errors print a short "--help" hint
+	// instead of the full help text.
+	CompactUsageErrors bool
+
+	// RewriteArgs is called with the full argument list before the command
+	// is resolved, and its result is run instead. Use it for compatibility
+	// shims such as renaming a legacy flag.
+	RewriteArgs func(args []string) []string
+
+	// Before is called once, before the first command the App executes, to
+	// do setup shared by every command such as loading credentials. Steps of
+	// RunAll and RunScript and shell lines share it. If it fails the command
+	// is not run, and it is tried again on the next run.
+	Before func(app *App) error
+
+	// Tracer, when set, records a span for every Run.
+	Tracer Tracer
+
+	// Executor runs commands once they are parsed. When nil they run in
+	// this process.
+	Executor Executor
+
+	// OnError is called with any error Run is about to return. The error it
+	// returns is returned from Run instead, so it can be wrapped, logged or
+	// swallowed by returning nil.
+	OnError func(err error) error
+
+	// Plain makes every run behave as if --plain was given: no color,
+	// spinners, progress bars, or cursor movement. Runs can also be made
+	// plain with --plain, CMD_PLAIN or TERM=dumb.
+	Plain bool
+
+	// Decorations controls the Unicode symbols in the framework's output,
+	// such as check marks in doctor and spinner glyphs. Auto uses them on
+	// terminals with a UTF-8 locale outside plain mode; the CMD_DECORATIONS
+	// environment variable can set auto, always or never.
+	Decorations Toggle
+
+	// Locale selects the translations added with AddMessages, e.g. "de".
+	// It defaults to the locale in LC_ALL, LC_MESSAGES or LANG.
+	Locale string
+
+	palette  bool
+	history  bool
+	aliases  bool
+	profiles bool
+	watch    bool
+	beforeOK bool
+	frozen   bool
+
+	// mu guards one-time setup shared by concurrent runs
+	mu sync.Mutex
+
+	telemetry     TelemetrySink
+	telemetryDone chan struct{}
+	notice        *updateNotice
+	scheduler     scheduler
+	checks        []check
+
+	firstRun     func(app *App) error
+	firstRunDone bool
+	auth         AuthProvider
+	redirects    map[string]string
+	messages     map[string]Messages
+	commonFlags  []commonFlags
+	groupEnvArgs []groupEnvArg
+
+	parent    *App
+	mountedAs string
 }
 
 func NewApp() *App {
-	return &App{
+	app := &App{
 		Commands: make(map[string]*Command),
 	}
+
+	app.addDescribeCommand()
+	app.addCompatCommand()
+
+	return app
 }
 
 func (app *App) AddCommand(cmd *Command) {
+	app.checkFrozen("AddCommand")
+
 	app.Commands[cmd.Name] = cmd
+	cmd.app = app
 	cmd.Setup(cmd)
 }
 
 func (app *App) Run(args []string) error {
+	return app.RunContext(context.Background(), args)
+}
+
+// RunContext is like Run, with ctx as the parent of the command's Context.
+// A RunFunc that runs other commands of its App should pass its own Context
+// so they share its cancellation, trace span and --include-alpha.
+func (app *App) RunContext(ctx context.Context, args []string) error {
+	ctx, endSpan := app.startSpan(ctx, args)
+	err := app.run(ctx, args)
+	endSpan(err)
+
+	if err != nil && app.OnError != nil {
+		err = app.OnError(err)
+	}
+
+	return err
+}
+
+func (app *App) run(ctx context.Context, args []string) (err error) {
+	start := time.Now()
+	verbatim := ctx.Value(verbatimKey{}) != nil
+
+	var global globalOptions
+	if !verbatim {
+		args, global = app.globalArgs(args)
+	}
+
+	trace := global.trace || app.traceFromEnv(ctx)
+
+	if global.includeAlpha {
+		ctx = context.WithValue(ctx, includeAlphaKey{}, true)
+	}
+
+	if global.plain {
+		ctx = context.WithValue(ctx, plainKey{}, true)
+	}
+
+	if global.profile != "" {
+		ctx = context.WithValue(ctx, profileKey{}, global.profile)
+	}
+
+	usage := func() { app.usage(ctx) }
+
+	if app.RewriteArgs != nil && !verbatim {
+		rewritten := app.RewriteArgs(append([]string{}, args...))
+
+		if trace && strings.Join(rewritten, "\x00") != strings.Join(args, "\x00") {
+			tracef(app.stderrIn(ctx), "args rewritten to %q", rewritten)
+		}
+
+		args = rewritten
+	}
+
+	if app.aliases && !verbatim {
+		expanded, err := app.expandAliases(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		if trace && len(args) > 1 && (len(expanded) != len(args) || expanded[1] != args[1]) {
+			tracef(app.stderrIn(ctx), "alias %q expanded to %q", args[1], expanded[1:])
+		}
+
+		args = expanded
+	}
+
+	if len(args) < 2 && app.palette && app.interactive(ctx) {
+		return app.runPalette(ctx)
+	}
+
 	if len(args) < 2 {
-		return newUsageErr("No command given", app.Usage)
+		ue := newUsageErr(app.tr("No command given"), usage)
+		ue.err = ErrNoCommand
+		return app.usageErr(ue, nil)
 	}
 
 	if args[1] == "--help" {
-		app.Usage()
+		if len(args) > 2 {
+			app.printSearch(ctx, strings.Join(args[2:], " "))
+		} else {
+			usage()
+		}
+
 		return nil
 	}
 
-	cmd, ok := app.Commands[args[1]]
+	if to, ok := app.redirect(args[1]); ok {
+		app.warnRedirect(ctx, args[1], to)
+		args = append([]string{args[0], to}, args[2:]...)
+	}
+
+	cmd, ok := app.command(args[1])
 	if !ok {
-		return newUsageErr("Invalid command", app.Usage)
+		if trace {
+			tracef(app.stderrIn(ctx), "no command named %q", args[1])
+		}
+
+		ue := newUsageErr(app.tr("Invalid command"), usage)
+		ue.err = ErrInvalidCommand
+		return app.usageErr(ue, nil)
 	}
 
+	if app.gated(ctx, cmd) {
+		ue := newUsageErr(app.trf("%s is an alpha command; pass %s or set %s to use it", cmd.Name, includeAlphaFlag, includeAlphaEnv), usage)
+		ue.err = ErrInvalidCommand
+		return app.usageErr(ue, nil)
+	}
+
+	if cmd.mount != nil {
+		return app.runMounted(ctx, cmd, args)
+	}
+
+	ctx, release := hold(ctx, cmd)
+	defer release()
+
+	// Recorded while cmd is held, as the args are redacted with its flags
+	if app.history {
+		defer func() {
+			app.recordHistory(cmd, args[2:], start, err)
+		}()
+	}
+
+	cmd.reset()
+	cmd.ctx = ctx
+	app.prepare(cmd)
+
 	for _, arg := range args[2:] {
-		if arg == "--help" {
+		switch arg {
+		case "--help":
 			cmd.Usage()
 			return nil
+		case "-h", "-help":
+			if cmd.Flags.Lookup(strings.TrimLeft(arg, "-")) == nil {
+				cmd.Usage()
+				return nil
+			}
+		case "--help=json":
+			return cmd.printJSONHelp()
+		}
+	}
+
+	if app.profiles && !cmd.noProfile {
+		if err := app.applyProfile(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	if env, ok := ctx.Value(envKey{}).(map[string]string); ok {
+		for n, v := range env {
+			cmd.setenv(n, v)
 		}
 	}
 
-	if err := cmd.Parse(args[2:]); err != nil {
+	app.startVersionCheck()
+	flush := app.startTelemetryFlush()
+
+	runStart := time.Now()
+	err = app.runCommand(ctx, cmd, args[2:], trace)
+	app.complete(cmd, runStart, err)
+	app.finishTelemetryFlush(flush)
+
+	return err
+}
+
+// globalOptions are the App's own flags, given before the command name.
+type globalOptions struct {
+	trace        bool
+	includeAlpha bool
+	plain        bool
+	profile      string
+}
+
+// globalArgs parses the App's own flags from args up to the command name and
+// returns args without them. Everything from the command name on belongs to
+// the command, even if it looks like a global flag.
+func (app *App) globalArgs(args []string) ([]string, globalOptions) {
+	var opts globalOptions
+	if len(args) == 0 {
+		return args, opts
+	}
+
+	i := 1
+
+loop:
+	for ; i < len(args); i++ {
+		a := args[i]
+
+		switch {
+		case a == traceFlag:
+			opts.trace = true
+		case a == includeAlphaFlag:
+			opts.includeAlpha = true
+		case a == plainFlag:
+			opts.plain = true
+		case app.profiles && a == profileOption && i+1 < len(args):
+			i++
+			opts.profile = args[i]
+		case app.profiles && strings.HasPrefix(a, profileOption+"="):
+			opts.profile = a[len(profileOption)+1:]
+		default:
+			break loop
+		}
+	}
+
+	return append([]string{args[0]}, args[i:]...), opts
+}
+
+// runCommand parses cmdArgs and runs cmd.
+func (app *App) runCommand(ctx context.Context, cmd *Command, cmdArgs []string, trace bool) error {
+	if err := app.checkPlatform(cmd); err != nil {
+		return err
+	}
+
+	if app.PromptMissing && app.interactive(ctx) {
+		var err error
+		if cmdArgs, err = cmd.promptMissing(cmdArgs); err != nil {
+			return err
+		}
+	}
+
+	err := cmd.Parse(cmdArgs)
+
+	if trace {
+		cmd.trace(cmdArgs)
+	}
+
+	if err != nil {
+		return app.usageErr(err, cmd)
+	}
+
+	if err := app.setup(); err != nil {
+		return err
+	}
+
+	if err := app.authorize(cmd); err != nil {
+		return err
+	}
+
+	return app.dispatch(ctx, cmd)
+}
+
+// setup runs the first-run hook and Before if they have not succeeded yet.
+func (app *App) setup() error {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if err := app.runFirstRun(); err != nil {
+		return err
+	}
+
+	if app.Before != nil && !app.beforeOK {
+		if err := app.Before(app); err != nil {
+			return err
+		}
+
+		app.beforeOK = true
+	}
+
+	return nil
+}
+
+// prepare adds the flags the App generates for every command. It is safe to
+// call more than once.
+func (app *App) prepare(cmd *Command) {
+	cmd.Flags.SetOutput(cmd.stderr())
+
+	for _, cf := range app.commonFlags[cmd.commonApplied:] {
+		if cf.group == cmd.Group {
+			cmd.addCommonFlags(cf.setup)
+		}
+	}
+	cmd.commonApplied = len(app.commonFlags)
+
+	for _, ea := range app.groupEnvArgs {
+		if _, ok := cmd.EnvArgs[ea.name]; !ok && ea.group == cmd.Group {
+			cmd.EnvArgs[ea.name] = ea.desc
+		}
+	}
+
+	if app.TimeoutFlag && cmd.timeoutFlag == nil && cmd.Flags.Lookup("timeout") == nil {
+		cmd.timeoutFlag = cmd.Flags.Duration("timeout", cmd.Timeout, "Abort the command if it runs longer than this duration")
+	}
+
+	if app.OutputFlag && cmd.outputFlag == nil && cmd.Flags.Lookup("output") == nil {
+		cmd.outputFlag = new(string)
+		cmd.Flags.StringVar(cmd.outputFlag, "output", "", "Output format: table, json or yaml")
+
+		if cmd.Flags.Lookup("o") == nil {
+			cmd.Flags.StringVar(cmd.outputFlag, "o", "", "Shorthand for --output")
+		}
+	}
+
+	if app.LogFlags && cmd.quietFlag == nil && cmd.Flags.Lookup("quiet") == nil && cmd.Flags.Lookup("verbose") == nil {
+		cmd.quietFlag = new(bool)
+		cmd.verboseFlag = new(bool)
+		cmd.Flags.BoolVar(cmd.quietFlag, "quiet", false, "Only log errors")
+		cmd.Flags.BoolVar(cmd.verboseFlag, "verbose", false, "Log debug messages")
+
+		if cmd.Flags.Lookup("q") == nil {
+			cmd.Flags.BoolVar(cmd.quietFlag, "q", false, "Shorthand for --quiet")
+		}
+
+		if cmd.Flags.Lookup("v") == nil {
+			cmd.Flags.BoolVar(cmd.verboseFlag, "v", false, "Shorthand for --verbose")
+		}
+	}
+
+	if app.ColorFlag && cmd.colorFlag == nil && cmd.Flags.Lookup("color") == nil {
+		cmd.colorFlag = new(toggleValue)
+		cmd.Flags.Var(cmd.colorFlag, "color", "Color output: auto, always or never")
+	}
+
+	if app.ProfileFlags && cmd.pprof == nil && cmd.Flags.Lookup("cpuprofile") == nil {
+		cmd.addPprofFlags()
+	}
+
+	if app.TimingsFlag && cmd.timingsFlag == nil && cmd.Flags.Lookup("timings") == nil {
+		cmd.timingsFlag = cmd.Flags.Bool("timings", false, "Print how long the command took")
+	}
+
+	if cmd.CacheTTL > 0 && cmd.noCacheFlag == nil && cmd.Flags.Lookup("no-cache") == nil {
+		cmd.noCacheFlag = cmd.Flags.Bool("no-cache", false, "Run the command instead of using cached output")
+	}
+
+	if app.watch && cmd.watchFlag == nil && cmd.Flags.Lookup("watch") == nil {
+		cmd.watchFlag = cmd.Flags.String("watch", "", "Run again whenever files matching this glob change")
+	}
+
+	if cmd.Repeatable && cmd.repeat == nil && cmd.Flags.Lookup("every") == nil {
+		cmd.addRepeatFlags()
+	}
+
+	if cmd.SoftFail && cmd.keepGoingFlag == nil && cmd.Flags.Lookup("keep-going") == nil {
+		cmd.keepGoingFlag = cmd.Flags.Bool("keep-going", false, "Process every item even if some fail")
+	}
+
+	if cmd.SupportsDryRun && cmd.dryRunFlag == nil && cmd.Flags.Lookup("dry-run") == nil {
+		cmd.dryRunFlag = cmd.Flags.Bool("dry-run", false, "Show what would be done without doing it")
+	}
+
+	if cmd.Destructive && cmd.yesFlag == nil && cmd.Flags.Lookup("yes") == nil {
+		cmd.yesFlag = new(bool)
+		cmd.Flags.BoolVar(cmd.yesFlag, "yes", false, "Answer yes to all confirmations")
+
+		if cmd.Flags.Lookup("y") == nil {
+			cmd.Flags.BoolVar(cmd.yesFlag, "y", false, "Shorthand for --yes")
+		}
+	}
+
+	if cmd.Destructive && cmd.confirmFlag == nil && cmd.Flags.Lookup("confirm") == nil {
+		cmd.confirmFlag = cmd.Flags.String("confirm", "", "Confirmation phrase for operations that require one")
+	}
+}
+
+func (app *App) execute(parent context.Context, cmd *Command) error {
+	lf, err := app.lock(cmd)
+	if err != nil {
+		return err
+	} else if lf != nil {
+		defer lf.release()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	run := cmd.canaryRun()
+
+	if cmd.cacheable() {
+		run = withCache(run, app.resultCachePath(cmd), cmd.CacheTTL)
+	}
+
+	if cmd.Retry != nil && cmd.Retry.Attempts > 1 {
+		run = withRetry(run, cmd.Retry)
+	}
+
+	if cmd.watchFlag != nil && *cmd.watchFlag != "" {
+		interval, debounce := app.watchTimes()
+		run = withWatch(run, *cmd.watchFlag, interval, debounce)
+	}
+
+	if cmd.repeating() {
+		run = withRepeat(run, cmd.repeat)
+	}
+
+	if timeout := cmd.timeout(); timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+
+		run = withTimeout(run, timeout)
+	}
+
+	if cmd.pprof.enabled() {
+		run = withProfiling(run, cmd.pprof)
+	}
+
+	cmd.ctx = ctx
+	cmd.shutdown.reset()
+
+	defer cmd.bindOutput()()
+
+	if cmd.ExecEnv != nil {
+		defer cmd.ExecEnv.apply(cmd)()
+	}
+
+	if app.HandleSignals || cmd.repeating() {
+		return app.runWithSignals(cmd, run, cancel)
+	}
+
+	return run(cmd)
+}
+
+func (app *App) usageErr(err error, cmd *Command) error {
+	ue, ok := err.(*UsageErr)
+	if !ok {
 		return err
 	}
 
-	return cmd.Run(cmd)
+	ue.app = app
+
+	if !app.CompactUsageErrors {
+		return ue
+	}
+
+	if cmd == nil {
+		ue.hint = app.trf("Run '%s --help' for usage.", app.name())
+	} else {
+		ue.hint = app.trf("Run '%s %s --help' for usage.", app.name(), cmd.Name)
+	}
+
+	return ue
 }
 
 func (app *App) Usage() {
-	fmt.Printf("usage: %s cmd [cmd-flags] [cmd-args]\n", os.Args[0])
+	app.usage(context.Background())
+}
+
+func (app *App) usage(ctx context.Context) {
+	w := app.stdoutIn(ctx)
+	color := app.colorEnabled(w) && !app.plainIn(ctx)
+
+	fmt.Fprintf(w, "%s %s\n", app.tr("usage:"), app.ltr(app.name()+" "+app.tr("cmd [cmd-flags] [cmd-args]")))
 
 	if app.Description != "" {
-		fmt.Println()
-		fmt.Println(app.Description)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, app.tr(app.Description))
 	}
 
 	var groupNames sort.StringSlice
 	cmdNamesByGroup := map[string]sort.StringSlice{}
 	for _, cmd := range app.Commands {
+		if !app.listed(ctx, cmd) {
+			continue
+		}
+
 		if _, ok := cmdNamesByGroup[cmd.Group]; !ok {
 			groupNames = append(groupNames, cmd.Group)
 		}
@@ -278,16 +1202,21 @@ func (app *App) Usage() {
 
 	groupNames.Sort()
 
+	width := 0
+	for _, names := range cmdNamesByGroup {
+		width = max(width, nameColumn(names))
+	}
+
 	for _, gn := range groupNames {
-		fmt.Printf("\n%s:\n", gn)
+		fmt.Fprintf(w, "\n%s\n", bold(app.tr(gn)+":", color))
 
 		cmdNamesByGroup[gn].Sort()
 
 		for _, cn := range cmdNamesByGroup[gn] {
 			cmd := app.Commands[cn]
-			fmt.Printf("    %-18s %s\n", cmd.Name, cmd.Description)
+			fmt.Fprintf(w, "    %s %s\n", padRight(app.ltr(cmd.Name), width), cmd.describeStability())
 		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
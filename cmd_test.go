@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -198,3 +203,278 @@ func TestCmdVarArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestAppCompactUsageErrors(t *testing.T) {
+	app := NewApp()
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.AppendArg("a", "an arg")
+	}, nil))
+
+	err := app.Run([]string{"app", "test"})
+	ue, ok := err.(*UsageErr)
+	if !ok {
+		t.Fatalf("Expected a usage error, got %v", err)
+	}
+
+	if ue.hint != "" {
+		t.Fatalf("Expected no hint, got %q", ue.hint)
+	}
+
+	app.CompactUsageErrors = true
+
+	ue = app.Run([]string{"app", "test"}).(*UsageErr)
+	if !strings.HasSuffix(ue.hint, " test --help' for usage.") {
+		t.Fatalf("Unexpected hint %q", ue.hint)
+	}
+
+	ue = app.Run([]string{"app", "nope"}).(*UsageErr)
+	if !strings.HasSuffix(ue.hint, " --help' for usage.") || strings.Contains(ue.hint, "nope") {
+		t.Fatalf("Unexpected hint %q", ue.hint)
+	}
+}
+
+func TestUsageErrWrapping(t *testing.T) {
+	var hooked error
+
+	app := NewApp()
+	app.OnError = func(err error) error {
+		hooked = err
+		return err
+	}
+
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.AppendArg("a", "an arg")
+		c.AddEnvArg("CMD_TEST_UNSET_VAR", "an unset var")
+	}, nil)
+	app.AddCommand(c)
+
+	testCases := []struct {
+		args []string
+		err  error
+		cmd  *Command
+	}{
+		{[]string{"app"}, ErrNoCommand, nil},
+		{[]string{"app", "nope"}, ErrInvalidCommand, nil},
+		{[]string{"app", "test"}, ErrArgCount, c},
+		{[]string{"app", "test", "a"}, ErrEnvUnset, c},
+	}
+
+	for i, tc := range testCases {
+		err := app.Run(tc.args)
+
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("Expected %v from test %d, got %v", tc.err, i, err)
+		}
+
+		var ue *UsageErr
+		if !errors.As(hooked, &ue) {
+			t.Fatalf("Expected OnError to receive a usage error in test %d, got %v", i, hooked)
+		}
+
+		if ue.Command() != tc.cmd {
+			t.Fatalf("Unexpected command for test %d", i)
+		}
+	}
+
+	app.OnError = func(err error) error {
+		return nil
+	}
+
+	if err := app.Run([]string{"app"}); err != nil {
+		t.Fatalf("Expected OnError to swallow the error, got %v", err)
+	}
+}
+
+func TestAppIsolation(t *testing.T) {
+	newApp := func(name string) (*App, *strings.Builder) {
+		var out strings.Builder
+
+		app := NewApp()
+		app.Name = name
+		app.Stdout = &out
+		app.Stderr = &out
+		app.AddCommand(NewCommand("hello", "test-group", "says hello", func(c *Command) {
+			c.AppendArg("who", "who to greet")
+		}, func(c *Command) error {
+			fmt.Fprintf(c.Stdout, "hello from %s", name)
+			return nil
+		}))
+
+		return app, &out
+	}
+
+	a, aOut := newApp("alpha")
+	b, bOut := newApp("beta")
+
+	if err := a.Run([]string{"ignored", "hello", "you"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Run([]string{"ignored", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if aOut.String() != "hello from alpha" {
+		t.Fatalf("Unexpected output from alpha: %q", aOut.String())
+	}
+
+	if !strings.HasPrefix(bOut.String(), "usage: beta cmd") {
+		t.Fatalf("Unexpected output from beta: %q", bOut.String())
+	}
+
+	bOut.Reset()
+
+	err := b.Run([]string{"ignored", "hello"})
+	if ue, ok := err.(*UsageErr); ok {
+		ue.ShowUsage()
+	}
+
+	if !strings.Contains(bOut.String(), "usage: beta hello who") {
+		t.Fatalf("Unexpected usage from beta: %q", bOut.String())
+	}
+
+	if aOut.String() != "hello from alpha" {
+		t.Fatal("Output from beta leaked into alpha")
+	}
+}
+
+func TestAppInjectedEnvAndStdin(t *testing.T) {
+	var out strings.Builder
+
+	env := map[string]string{"TEST_TOKEN": " secret "}
+
+	app := NewApp()
+	app.Stdin = strings.NewReader("world\n")
+	app.Stdout = &out
+	app.Interactive = Always
+	app.Getenv = func(name string) string {
+		return env[name]
+	}
+
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.AddEnvArg("TEST_TOKEN", "a token")
+	}, func(c *Command) error {
+		who, err := c.Prompt("who")
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.Stdout, "%s %s", who, c.EnvArg("TEST_TOKEN"))
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "world secret" {
+		t.Fatalf("Unexpected output: %q", out.String())
+	}
+
+	delete(env, "TEST_TOKEN")
+
+	if err := app.Run([]string{"app", "test"}); !errors.Is(err, ErrEnvUnset) {
+		t.Fatalf("Expected ErrEnvUnset, got %v", err)
+	}
+}
+
+func TestAppRewriteArgs(t *testing.T) {
+	var got string
+
+	app := NewApp()
+	app.RewriteArgs = func(args []string) []string {
+		for i, a := range args {
+			if a == "--colour" {
+				args[i] = "--color"
+			}
+		}
+
+		if len(args) > 1 && args[1] == "old-test" {
+			args[1] = "test"
+		}
+
+		return args
+	}
+
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.Flags.String("color", "", "a color")
+	}, func(c *Command) error {
+		got = c.Flag("color").String()
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "old-test", "--colour", "red"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "red" {
+		t.Fatalf("Expected the rewritten flag to be set, got %q", got)
+	}
+}
+
+func TestCmdReusedAcrossRuns(t *testing.T) {
+	var got []string
+
+	app := NewApp()
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.Flags.Bool("force", false, "force it")
+		c.Flags.String("name", "anon", "a name")
+		c.AppendVarArg("items", "the items")
+	}, func(c *Command) error {
+		set := []string{}
+		c.Flags.Visit(func(f *flag.Flag) { set = append(set, f.Name) })
+
+		got = append(got, fmt.Sprintf("%s %s %d %v", c.Flag("force"), c.Flag("name"), len(c.VarArgs()), set))
+		return nil
+	}))
+
+	for _, args := range [][]string{{"--force", "--name=bob", "a", "b"}, {"c"}} {
+		if err := app.Run(append([]string{"app", "test"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"true bob 2 [force name]", "false anon 1 []"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestCmdHideFlag(t *testing.T) {
+	var debug bool
+
+	app := NewApp()
+	app.AddCommand(NewCommand("sync", "test-group", "syncs", func(c *Command) {
+		c.Flags.Bool("dry", false, "Only print changes")
+		c.Flags.Bool("debug-sync", false, "Dump sync state")
+		c.HideFlag("debug-sync")
+	}, func(c *Command) error {
+		debug, _ = c.Flag("debug-sync").Bool()
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "sync", "--debug-sync"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !debug {
+		t.Fatal("Expected the hidden flag to parse")
+	}
+
+	if c := app.completeLine(context.Background(), "sync --d"); !reflect.DeepEqual(c, []string{"--dry"}) {
+		t.Fatalf("Unexpected completions %v", c)
+	}
+
+	var out strings.Builder
+	cmd, _ := app.command("sync")
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	if strings.Contains(out.String(), "debug-sync") {
+		t.Fatalf("Expected the flag to be hidden:\n%s", out.String())
+	}
+
+	if flags := cmd.Describe().Flags; len(flags) != 1 || flags[0].Name != "dry" {
+		t.Fatalf("Unexpected flags %v", flags)
+	}
+}
@@ -0,0 +1,76 @@
+// Package cmdtest runs apps built with cmd end to end for tests.
+package cmdtest
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/chrismrivera/cmd"
+)
+
+// Result is the outcome of a run.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	Err      error
+	ExitCode int
+}
+
+// Runner configures the environment of a run. Env values are layered over
+// the App's environment, Stdin is the content of standard input and Dir,
+// when set, is the directory relative paths are resolved against. They apply
+// to the run alone, as a cmd.RunEnv, so runs may share an App and run in
+// parallel.
+type Runner struct {
+	Env   map[string]string
+	Stdin string
+	Dir   string
+}
+
+// Run runs args against app with captured output, e.g.
+// cmdtest.Run(app, "deploy", "--force", "web").
+func Run(app *cmd.App, args ...string) *Result {
+	return Runner{}.Run(app, args...)
+}
+
+// Run runs args against app with the Runner's environment.
+func (r Runner) Run(app *cmd.App, args ...string) *Result {
+	var stdout, stderr strings.Builder
+
+	ctx := cmd.WithRunEnv(context.Background(), &cmd.RunEnv{
+		Stdin:  strings.NewReader(r.Stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Getenv: r.getenv(app.Getenv),
+		Dir:    r.Dir,
+	})
+
+	name := app.Name
+	if name == "" {
+		name = "app"
+	}
+
+	err := app.RunContext(ctx, append([]string{name}, args...))
+
+	return &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Err:      err,
+		ExitCode: cmd.ExitCode(err),
+	}
+}
+
+func (r Runner) getenv(parent func(string) string) func(string) string {
+	if parent == nil {
+		parent = os.Getenv
+	}
+
+	return func(name string) string {
+		if v, ok := r.Env[name]; ok {
+			return v
+		}
+
+		return parent(name)
+	}
+}
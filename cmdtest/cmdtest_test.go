@@ -0,0 +1,139 @@
+package cmdtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/chrismrivera/cmd"
+)
+
+func newApp() *cmd.App {
+	app := cmd.NewApp()
+	app.Name = "test-app"
+
+	app.AddCommand(cmd.NewCommand("deploy", "test-group", "deploys things", func(c *cmd.Command) {
+		c.Flags.Bool("force", false, "force it")
+		c.AppendArg("target", "what to deploy")
+		c.AddEnvArg("DEPLOY_TOKEN", "a token")
+	}, func(c *cmd.Command) error {
+		in, err := io.ReadAll(c.Stdin)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.Stdout, "%s %s %s %s", c.Arg("target"), c.Flag("force"), c.EnvArg("DEPLOY_TOKEN"), in)
+		fmt.Fprint(c.Stderr, filepath.Base(c.Dir()))
+
+		if c.Arg("target") == "fail" {
+			return errors.New("failed")
+		}
+
+		return nil
+	}))
+
+	return app
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	r := Runner{
+		Env:   map[string]string{"DEPLOY_TOKEN": "abc"},
+		Stdin: "input",
+		Dir:   dir,
+	}
+
+	res := r.Run(newApp(), "deploy", "--force", "web")
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+
+	if res.Stdout != "web true abc input" {
+		t.Fatalf("Unexpected stdout: %q", res.Stdout)
+	}
+
+	if res.Stderr != filepath.Base(dir) {
+		t.Fatalf("Unexpected stderr: %q", res.Stderr)
+	}
+
+	if res.ExitCode != 0 {
+		t.Fatalf("Unexpected exit code: %d", res.ExitCode)
+	}
+
+	res = r.Run(newApp(), "deploy", "fail")
+	if res.Err == nil || res.ExitCode != 1 {
+		t.Fatalf("Expected a failure, got %v with exit code %d", res.Err, res.ExitCode)
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	app := newApp()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			dir := filepath.Join(t.TempDir(), fmt.Sprint("dir", i))
+			r := Runner{
+				Env:   map[string]string{"DEPLOY_TOKEN": fmt.Sprint("token", i)},
+				Stdin: fmt.Sprint("input", i),
+				Dir:   dir,
+			}
+
+			res := r.Run(app, "deploy", fmt.Sprint("web", i))
+			if res.Err != nil {
+				t.Error(res.Err)
+				return
+			}
+
+			if expected := fmt.Sprintf("web%d false token%d input%d", i, i, i); res.Stdout != expected {
+				t.Errorf("Expected %q, got %q", expected, res.Stdout)
+			}
+
+			if expected := fmt.Sprint("dir", i); res.Stderr != expected {
+				t.Errorf("Expected %q, got %q", expected, res.Stderr)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if now, _ := os.Getwd(); now != wd {
+		t.Fatalf("Working directory changed to %s", now)
+	}
+
+	if app.Stdout != nil || app.Stdin != nil || app.Getenv != nil {
+		t.Fatal("Expected the App to be left alone")
+	}
+}
+
+func TestRunUsageErr(t *testing.T) {
+	t.Setenv("DEPLOY_TOKEN", "")
+
+	res := Run(newApp(), "deploy", "web")
+
+	if !errors.Is(res.Err, cmd.ErrEnvUnset) {
+		t.Fatalf("Expected ErrEnvUnset, got %v", res.Err)
+	}
+
+	if res.ExitCode != 2 {
+		t.Fatalf("Expected exit code 2, got %d", res.ExitCode)
+	}
+}
+
+func TestRunScripts(t *testing.T) {
+	RunScripts(t, "testdata/scripts", newApp)
+}
@@ -0,0 +1,100 @@
+package cmdtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/chrismrivera/cmd"
+)
+
+// updateEnv rewrites golden files when set, for test packages without an
+// -update flag.
+const updateEnv = "CMDTEST_UPDATE"
+
+// updating reports whether golden files should be rewritten: the test binary
+// has an -update flag that is set, or CMDTEST_UPDATE is. The flag is looked
+// up rather than defined so it can't clash with one of the test package's.
+func updating() bool {
+	if v := os.Getenv(updateEnv); v != "" && v != "0" && v != "false" {
+		return true
+	}
+
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
+
+// Usages renders the help of app and of each visible command without color.
+// The app's help is keyed by "" and each command's by its name.
+func Usages(app *cmd.App) map[string]string {
+	color := app.Color
+	app.Color = cmd.Never
+	defer func() {
+		app.Color = color
+	}()
+
+	usages := map[string]string{"": Run(app, "--help").Stdout}
+
+	for name, c := range app.Commands {
+		if c.Hidden {
+			continue
+		}
+
+		usages[name] = Run(app, name, "--help").Stdout
+	}
+
+	return usages
+}
+
+// GoldenUsage compares the help of app and its commands against the golden
+// files usage.golden and usage-<command>.golden in dir. Running the tests with
+// CMDTEST_UPDATE=1, or with -update if the test package defines it, rewrites
+// the files instead.
+func GoldenUsage(t testing.TB, app *cmd.App, dir string) {
+	t.Helper()
+
+	usages := Usages(app)
+	update := updating()
+
+	names := make([]string, 0, len(usages))
+	for n := range usages {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		file := "usage.golden"
+		if n != "" {
+			file = "usage-" + n + ".golden"
+		}
+
+		path := filepath.Join(dir, file)
+
+		if update {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(path, []byte(usages[n]), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			continue
+		}
+
+		expected, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			t.Errorf("Missing golden file %s, run with %s=1 to create it", path, updateEnv)
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(expected) != usages[n] {
+			t.Errorf("Usage differs from %s, run with %s=1 if intended\n--- expected\n%s\n--- got\n%s", path, updateEnv, expected, usages[n])
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cmdtest
+
+import "testing"
+
+func TestGoldenUsage(t *testing.T) {
+	app := newApp()
+	app.Description = "tests golden usage"
+	app.Commands["deploy"].AddEnvArg("API_URL", "the API to deploy with")
+
+	GoldenUsage(t, app, "testdata")
+}
+
+func TestUsages(t *testing.T) {
+	usages := Usages(newApp())
+
+	if len(usages) != 2 {
+		t.Fatalf("Expected app and deploy usage, got %d", len(usages))
+	}
+
+	if _, ok := usages["__describe"]; ok {
+		t.Fatal("Hidden command included")
+	}
+}
+
+func TestGoldenUsageUpdate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(updateEnv, "1")
+
+	GoldenUsage(t, newApp(), dir)
+
+	t.Setenv(updateEnv, "")
+
+	GoldenUsage(t, newApp(), dir)
+}
@@ -0,0 +1,371 @@
+package cmdtest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chrismrivera/cmd"
+)
+
+// RunScripts runs each .txt file in dir as a subtest. A script is a list of
+// commands, one per line, followed by optional files:
+//
+//	# deploy needs a token
+//	env DEPLOY_TOKEN=abc
+//	run deploy --force web
+//	stdout 'deployed web'
+//	! run deploy
+//	exit 2
+//	cmp stdout want.txt
+//
+//	-- want.txt --
+//	...
+//
+// The commands are:
+//
+//	run args...            run the app; prefix with ! to expect failure
+//	stdout/stderr regexp   match the output of the last run
+//	cmp stdout/stderr file compare the output of the last run with a file
+//	exit code              check the exit code of the last run
+//	env KEY=VALUE          set an environment variable for later runs
+//	stdin file             use a file as stdin for the next run
+//	cd dir                 change the working directory of later runs
+//
+// A ! before stdout or stderr negates the match. Words are split on spaces
+// and may be single quoted, and $VAR is expanded from the script's
+// environment. Each script runs in its own temporary directory, $WORK, which
+// also holds HOME and the XDG directories, and each run gets a fresh App
+// from newApp.
+func RunScripts(t *testing.T, dir string, newApp func() *cmd.App) {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) == 0 {
+		t.Fatalf("No scripts in %s", dir)
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			RunScript(t, path, newApp)
+		})
+	}
+}
+
+// RunScript runs a single script. See RunScripts for the format.
+func RunScript(t *testing.T, path string, newApp func() *cmd.App) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script, files := splitScript(string(data))
+
+	s := &scriptState{
+		t:     t,
+		name:  filepath.Base(path),
+		work:  t.TempDir(),
+		app:   newApp,
+		files: files,
+	}
+
+	s.run(script)
+}
+
+type scriptState struct {
+	t     *testing.T
+	name  string
+	line  int
+	work  string
+	app   func() *cmd.App
+	files map[string]string
+
+	runner Runner
+	last   *Result
+}
+
+// splitScript separates the commands of a script from its "-- name --"
+// files.
+func splitScript(data string) ([]string, map[string]string) {
+	files := map[string]string{}
+	var script []string
+	var name string
+	var content strings.Builder
+
+	flush := func() {
+		if name != "" {
+			files[name] = content.String()
+		}
+		content.Reset()
+	}
+
+	for _, l := range strings.SplitAfter(data, "\n") {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "-- ") && strings.HasSuffix(trimmed, " --") && len(trimmed) > 6 {
+			flush()
+			name = strings.TrimSpace(trimmed[3 : len(trimmed)-3])
+			continue
+		}
+
+		if name != "" {
+			content.WriteString(l)
+		} else {
+			script = append(script, strings.TrimRight(l, "\r\n"))
+		}
+	}
+
+	flush()
+
+	return script, files
+}
+
+func (s *scriptState) fatalf(format string, args ...interface{}) {
+	s.t.Helper()
+	s.t.Fatalf("%s:%d: %s", s.name, s.line, fmt.Sprintf(format, args...))
+}
+
+func (s *scriptState) run(script []string) {
+	s.t.Helper()
+
+	for name, content := range s.files {
+		path := filepath.Join(s.work, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			s.t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+
+	s.runner = Runner{
+		Dir: s.work,
+		Env: map[string]string{
+			"WORK":            s.work,
+			"HOME":            s.work,
+			"XDG_DATA_HOME":   filepath.Join(s.work, ".local", "share"),
+			"XDG_RUNTIME_DIR": filepath.Join(s.work, ".run"),
+		},
+	}
+
+	for i, l := range script {
+		s.line = i + 1
+
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		words, err := s.words(l)
+		if err != nil {
+			s.fatalf("%v", err)
+		}
+
+		neg := false
+		if words[0] == "!" {
+			neg = true
+			words = words[1:]
+		}
+
+		if len(words) == 0 {
+			s.fatalf("missing command after !")
+		}
+
+		s.exec(neg, words[0], words[1:])
+	}
+}
+
+func (s *scriptState) exec(neg bool, name string, args []string) {
+	s.t.Helper()
+
+	if neg && name != "run" && name != "stdout" && name != "stderr" {
+		s.fatalf("%s cannot be negated", name)
+	}
+
+	switch name {
+	case "run":
+		// The App is the script's own, so what it reads for itself, such as
+		// its data directories, comes from the script's environment too
+		app := s.app()
+		app.Getenv = s.runner.getenv(app.Getenv)
+
+		s.last = s.runner.Run(app, args...)
+		s.runner.Stdin = ""
+
+		if neg && s.last.Err == nil {
+			s.fatalf("unexpected success\n%s", s.last.Stdout)
+		} else if !neg && s.last.Err != nil {
+			s.fatalf("unexpected failure: %v\n%s", s.last.Err, s.last.Stderr)
+		}
+	case "stdout", "stderr":
+		s.args(name, args, 1)
+
+		re, err := regexp.Compile("(?m)" + args[0])
+		if err != nil {
+			s.fatalf("%v", err)
+		}
+
+		out := s.output(name)
+		if re.MatchString(out) == neg {
+			if neg {
+				s.fatalf("%s unexpectedly matches %q\n%s", name, args[0], out)
+			}
+			s.fatalf("%s does not match %q\n%s", name, args[0], out)
+		}
+	case "cmp":
+		s.args(name, args, 2)
+
+		if args[0] != "stdout" && args[0] != "stderr" {
+			s.fatalf("cmp compares stdout or stderr, not %q", args[0])
+		}
+
+		expected, err := os.ReadFile(s.path(args[1]))
+		if err != nil {
+			s.fatalf("%v", err)
+		}
+
+		if out := s.output(args[0]); out != string(expected) {
+			s.fatalf("%s differs from %s\n--- expected\n%s\n--- got\n%s", args[0], args[1], expected, out)
+		}
+	case "exit":
+		s.args(name, args, 1)
+
+		code, err := strconv.Atoi(args[0])
+		if err != nil {
+			s.fatalf("invalid exit code %q", args[0])
+		}
+
+		if s.last == nil {
+			s.fatalf("exit before any run")
+		}
+
+		if s.last.ExitCode != code {
+			s.fatalf("expected exit code %d, got %d", code, s.last.ExitCode)
+		}
+	case "env":
+		s.args(name, args, 1)
+
+		kv := strings.SplitN(args[0], "=", 2)
+		if len(kv) != 2 {
+			s.fatalf("env expects KEY=VALUE, got %q", args[0])
+		}
+
+		s.runner.Env[kv[0]] = kv[1]
+	case "stdin":
+		s.args(name, args, 1)
+
+		data, err := os.ReadFile(s.path(args[0]))
+		if err != nil {
+			s.fatalf("%v", err)
+		}
+
+		s.runner.Stdin = string(data)
+	case "cd":
+		s.args(name, args, 1)
+
+		dir := s.path(args[0])
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			s.fatalf("%s is not a directory", args[0])
+		}
+
+		s.runner.Dir = dir
+	default:
+		s.fatalf("unknown command %q", name)
+	}
+}
+
+func (s *scriptState) args(name string, args []string, n int) {
+	s.t.Helper()
+
+	if len(args) != n {
+		s.fatalf("%s expects %d argument(s), got %d", name, n, len(args))
+	}
+}
+
+func (s *scriptState) output(name string) string {
+	s.t.Helper()
+
+	if s.last == nil {
+		s.fatalf("%s before any run", name)
+	}
+
+	if name == "stderr" {
+		return s.last.Stderr
+	}
+
+	return s.last.Stdout
+}
+
+func (s *scriptState) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(s.runner.Dir, name)
+}
+
+// words splits a script line on spaces, honoring single quotes and
+// expanding $VAR outside of them.
+func (s *scriptState) words(line string) ([]string, error) {
+	var words []string
+	var word bytes.Buffer
+	inWord, quoted := false, false
+	start := 0
+
+	expand := func(str string) string {
+		return os.Expand(str, func(name string) string {
+			if v, ok := s.runner.Env[name]; ok {
+				return v
+			}
+
+			return os.Getenv(name)
+		})
+	}
+
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || (!quoted && (line[i] == ' ' || line[i] == '\t')) || line[i] == '\'' {
+			if !quoted {
+				word.WriteString(expand(line[start:i]))
+			} else if i < len(line) {
+				word.WriteString(line[start:i])
+			}
+
+			if i == len(line) {
+				if quoted {
+					return nil, fmt.Errorf("unterminated quote")
+				}
+			} else if line[i] == '\'' {
+				quoted = !quoted
+				inWord = true
+				start = i + 1
+				continue
+			}
+
+			if inWord || word.Len() > 0 {
+				words = append(words, word.String())
+			}
+
+			word.Reset()
+			inWord = false
+			start = i + 1
+			continue
+		}
+
+		inWord = true
+	}
+
+	return words, nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import "flag"
+
+type commonFlags struct {
+	group string
+	setup func(fs *flag.FlagSet)
+}
+
+// AddCommonFlags gives every command in group the flags that setup
+// defines, e.g. a --dsn flag for all "db" commands. A command's own flag of
+// the same name takes precedence.
+func (app *App) AddCommonFlags(group string, setup func(fs *flag.FlagSet)) {
+	app.checkFrozen("AddCommonFlags")
+
+	app.commonFlags = append(app.commonFlags, commonFlags{group, setup})
+}
+
+type groupEnvArg struct {
+	group, name, desc string
+}
+
+// AddGroupEnvArg makes the environment variable called name required by
+// every command in group, as if each had called AddEnvArg.
+func (app *App) AddGroupEnvArg(group, name, desc string) {
+	app.checkFrozen("AddGroupEnvArg")
+
+	app.groupEnvArgs = append(app.groupEnvArgs, groupEnvArg{group, name, desc})
+}
+
+func (cmd *Command) addCommonFlags(setup func(fs *flag.FlagSet)) {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	setup(fs)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if cmd.Flags.Lookup(f.Name) == nil {
+			cmd.Flags.Var(f.Value, f.Name, f.Usage)
+			cmd.Flags.Lookup(f.Name).DefValue = f.DefValue
+		}
+	})
+}
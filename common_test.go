@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestAppCommonFlags(t *testing.T) {
+	got := map[string]string{}
+
+	run := func(c *Command) error {
+		if f := c.Flags.Lookup("dsn"); f != nil {
+			got[c.Name] = f.Value.String()
+		}
+		return nil
+	}
+
+	app := NewApp()
+	app.AddCommonFlags("db", func(fs *flag.FlagSet) {
+		fs.String("dsn", "postgres://localhost", "Database to connect to")
+	})
+	app.AddCommand(NewCommand("migrate", "db", "migrates", func(c *Command) {}, run))
+	app.AddCommand(NewCommand("seed", "db", "seeds", func(c *Command) {
+		c.Flags.String("dsn", "sqlite://seed.db", "Database to seed")
+	}, run))
+	app.AddCommand(NewCommand("deploy", "ops", "deploys", func(c *Command) {}, run))
+
+	testCases := []struct {
+		args []string
+		dsn  string
+	}{
+		{[]string{"migrate", "--dsn", "postgres://prod"}, "postgres://prod"},
+		{[]string{"migrate"}, "postgres://localhost"},
+		{[]string{"seed"}, "sqlite://seed.db"},
+	}
+
+	for _, tc := range testCases {
+		if err := app.Run(append([]string{"app"}, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+
+		if dsn := got[tc.args[0]]; dsn != tc.dsn {
+			t.Fatalf("Expected %s for %q, got %q", tc.dsn, tc.args, dsn)
+		}
+	}
+
+	if err := app.Run([]string{"app", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := got["deploy"]; ok {
+		t.Fatal("Expected deploy not to get the db flags")
+	}
+}
+
+func TestAppGroupEnvArg(t *testing.T) {
+	env := map[string]string{}
+
+	app := NewApp()
+	app.Getenv = func(name string) string { return env[name] }
+	app.AddGroupEnvArg("aws", "AWS_PROFILE", "The AWS profile to use")
+	app.AddCommand(NewCommand("buckets", "aws", "lists buckets", func(c *Command) {}, func(c *Command) error { return nil }))
+	app.AddCommand(NewCommand("deploy", "ops", "deploys", func(c *Command) {}, func(c *Command) error { return nil }))
+
+	if err := app.Run([]string{"app", "buckets"}); !errors.Is(err, ErrEnvUnset) {
+		t.Fatalf("Expected an unset env error, got %v", err)
+	}
+
+	if err := app.Run([]string{"app", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env["AWS_PROFILE"] = "prod"
+	if err := app.Run([]string{"app", "buckets"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	cmd, _ := app.command("buckets")
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	if !strings.Contains(out.String(), "AWS_PROFILE: The AWS profile to use") {
+		t.Fatalf("Expected the env arg in usage:\n%s", out.String())
+	}
+}
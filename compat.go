@@ -0,0 +1,41 @@
+package cmd
+
+import "fmt"
+
+// CompatErr is returned by __compat when the app is older than required.
+type CompatErr struct {
+	Version string
+	Min     string
+}
+
+func (ce *CompatErr) Error() string {
+	return fmt.Sprintf("version %s is older than the required %s", ce.Version, ce.Min)
+}
+
+func (app *App) addCompatCommand() {
+	c := NewCommand("__compat", "", "Exit 0 if the app's version is at least min-version, 1 otherwise",
+		func(cmd *Command) {
+			cmd.AppendArg("min-version", "The minimum required semantic version")
+		},
+		func(cmd *Command) error {
+			min := cmd.Arg("min-version").String()
+
+			if _, err := parseSemver(min); err != nil {
+				return cmd.usageErr(fmt.Sprintf("Invalid version %q", min), err)
+			}
+
+			c, err := compareVersions(app.Version, min)
+			if err != nil {
+				return fmt.Errorf("app version: %v", err)
+			}
+
+			if c < 0 {
+				return &CompatErr{Version: app.Version, Min: min}
+			}
+
+			return nil
+		})
+	c.Hidden = true
+
+	app.AddCommand(c)
+}
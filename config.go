@@ -0,0 +1,457 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The config file is a small subset of YAML: nested maps of scalars, one
+// key per line, indented with spaces, with # comments. Values are addressed
+// by dotted keys such as "aliases.dp".
+
+type configEntry struct {
+	value string
+	line  int
+}
+
+type configFile struct {
+	path  string
+	lines []string
+
+	values   map[string]configEntry
+	sections map[string]int
+
+	// files holds the file each value came from in a merged config
+	files map[string]string
+}
+
+// ConfigErr reports a malformed config file.
+type ConfigErr struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (ce *ConfigErr) Error() string {
+	return fmt.Sprintf("%s:%d: %s", ce.File, ce.Line, ce.Msg)
+}
+
+func (app *App) configPath() string {
+	if app != nil && app.ConfigFile != "" {
+		return app.ConfigFile
+	}
+
+	if app != nil && app.parent != nil {
+		return app.parent.configPath()
+	}
+
+	return filepath.Join(userConfigDir(app.getenv), app.name(), "config.yaml")
+}
+
+func userConfigDir(getenv func(string) string) string {
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		if dir := getenv("AppData"); dir != "" {
+			return dir
+		}
+		return filepath.Join(home, "AppData", "Roaming")
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support")
+	}
+
+	return filepath.Join(home, ".config")
+}
+
+func (app *App) loadConfig() (*configFile, error) {
+	return loadConfigFile(app.configPath())
+}
+
+type configLayer struct {
+	name, path string
+
+	// disabled is set for the project file unless App.ProjectConfig is
+	disabled bool
+}
+
+// projectForbidden are the config sections the project file can't set. A
+// checkout isn't trusted to pick the profile or define aliases, as either
+// could redirect commands the user runs in it.
+var projectForbidden = []string{"profile", profileSection, aliasSection}
+
+// configLayers returns the config files in order of precedence, lowest
+// first: system, user and project, which is found in the run's Dir.
+func (app *App) configLayers(ctx context.Context) []configLayer {
+	if app.parent != nil {
+		return app.parent.configLayers(ctx)
+	}
+
+	system := app.SystemConfigFile
+	if system == "" {
+		dir := "/etc"
+		if runtime.GOOS == "windows" {
+			if dir = app.getenv("ProgramData"); dir == "" {
+				dir = `C:\ProgramData`
+			}
+		}
+
+		system = filepath.Join(dir, app.name(), "config.yaml")
+	}
+
+	project := app.ProjectConfigFile
+	if project == "" {
+		project = "." + app.name() + ".yaml"
+	}
+
+	return []configLayer{
+		{name: "system", path: system},
+		{name: "user", path: app.configPath()},
+		{name: "project", path: inDir(dirIn(ctx), project), disabled: !app.ProjectConfig},
+	}
+}
+
+// resolveConfig merges the config layers, each value coming from the last
+// file that sets it. The result is read-only; changes are made to the user
+// file from loadConfig.
+func (app *App) resolveConfig(ctx context.Context) (*configFile, error) {
+	merged := &configFile{
+		path:     app.configPath(),
+		values:   map[string]configEntry{},
+		sections: map[string]int{},
+		files:    map[string]string{},
+	}
+
+	for _, l := range app.configLayers(ctx) {
+		if l.disabled {
+			continue
+		}
+
+		cf, err := loadConfigFile(l.path)
+		if err != nil {
+			return nil, err
+		}
+
+		if l.name == "project" {
+			if err := checkProjectConfig(cf); err != nil {
+				return nil, err
+			}
+		}
+
+		for k, e := range cf.values {
+			merged.values[k] = e
+			merged.files[k] = cf.path
+		}
+
+		for k, i := range cf.sections {
+			if _, ok := merged.sections[k]; !ok {
+				merged.sections[k] = i
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// checkProjectConfig rejects a project file setting a forbidden section.
+func checkProjectConfig(cf *configFile) error {
+	keys := make([]string, 0, len(cf.values))
+	for k := range cf.values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		top := strings.SplitN(k, ".", 2)[0]
+
+		for _, f := range projectForbidden {
+			if top == f {
+				return &ConfigErr{File: cf.path, Line: cf.values[k].line, Msg: fmt.Sprintf("%s can't be set in the project config", top)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// fileOf returns the file the value of key came from.
+func (cf *configFile) fileOf(key string) string {
+	if f, ok := cf.files[key]; ok {
+		return f
+	}
+
+	return cf.path
+}
+
+// loadConfigFile reads and parses path. A missing file is an empty config.
+func loadConfigFile(path string) (*configFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return parseConfigFile(path, b)
+}
+
+// parseConfigFile parses b as the contents of the config file at path.
+func parseConfigFile(path string, b []byte) (*configFile, error) {
+	cf := &configFile{path: path}
+
+	if len(b) > 0 {
+		cf.lines = strings.Split(strings.TrimRight(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n"), "\n")
+	}
+
+	if err := cf.parse(); err != nil {
+		return nil, err
+	}
+
+	return cf, nil
+}
+
+func (cf *configFile) parse() error {
+	cf.values = map[string]configEntry{}
+	cf.sections = map[string]int{}
+
+	type level struct {
+		indent int
+		key    string
+	}
+
+	stack := []level{}
+
+	for i, l := range cf.lines {
+		content := strings.TrimRight(stripConfigComment(l), " \t")
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		indent := len(content) - len(strings.TrimLeft(content, " "))
+		if strings.HasPrefix(content[indent:], "\t") {
+			return &ConfigErr{cf.path, i + 1, "tabs cannot be used for indentation"}
+		}
+
+		colon := strings.Index(content, ":")
+		if colon < 0 {
+			return &ConfigErr{cf.path, i + 1, "expected key: value"}
+		}
+
+		key := strings.TrimSpace(content[indent:colon])
+		if key == "" || strings.ContainsAny(key, ". ") {
+			return &ConfigErr{cf.path, i + 1, fmt.Sprintf("invalid key %q", key)}
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		path := make([]string, 0, len(stack)+1)
+		for _, s := range stack {
+			path = append(path, s.key)
+		}
+		full := strings.Join(append(path, key), ".")
+
+		raw := strings.TrimSpace(content[colon+1:])
+		if raw == "" {
+			cf.sections[full] = i
+			stack = append(stack, level{indent, key})
+			continue
+		}
+
+		v, err := unquoteConfigValue(raw)
+		if err != nil {
+			return &ConfigErr{cf.path, i + 1, err.Error()}
+		}
+
+		cf.values[full] = configEntry{v, i + 1}
+	}
+
+	return nil
+}
+
+// stripConfigComment removes a # comment that is outside of quotes and
+// starts the line or follows a space.
+func stripConfigComment(l string) string {
+	var quote byte
+
+	for i := 0; i < len(l); i++ {
+		c := l[i]
+
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || l[i-1] == ' ' || l[i-1] == '\t'):
+			return l[:i]
+		}
+	}
+
+	return l
+}
+
+func unquoteConfigValue(raw string) (string, error) {
+	switch raw[0] {
+	case '"':
+		v, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", raw)
+		}
+		return v, nil
+	case '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return "", fmt.Errorf("invalid quoted value %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+
+	return raw, nil
+}
+
+func quoteConfigValue(v string) string {
+	if v == "" || v != strings.TrimSpace(v) || strings.ContainsAny(v, "#:\"'\n\\") || strings.ContainsAny(v[:1], "-?,[]{}&*!|>%@`") {
+		return strconv.Quote(v)
+	}
+
+	return v
+}
+
+func (cf *configFile) get(key string) (string, bool) {
+	e, ok := cf.values[key]
+	return e.value, ok
+}
+
+// keys returns the names of the values directly inside section, sorted.
+func (cf *configFile) keys(section string) []string {
+	prefix := section + "."
+	names := []string{}
+
+	for k := range cf.values {
+		if strings.HasPrefix(k, prefix) && !strings.Contains(k[len(prefix):], ".") {
+			names = append(names, k[len(prefix):])
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// blockEnd returns the index of the line after the block started by the
+// section header at line index i, or after the file when i is -1.
+func (cf *configFile) blockEnd(i int) int {
+	if i < 0 {
+		return len(cf.lines)
+	}
+
+	indent := len(cf.lines[i]) - len(strings.TrimLeft(cf.lines[i], " "))
+	end := i + 1
+
+	for j := i + 1; j < len(cf.lines); j++ {
+		content := strings.TrimSpace(stripConfigComment(cf.lines[j]))
+		if content == "" {
+			continue
+		}
+
+		if len(cf.lines[j])-len(strings.TrimLeft(cf.lines[j], " ")) <= indent {
+			break
+		}
+
+		end = j + 1
+	}
+
+	return end
+}
+
+// set stores value under key, editing the line in place when the key exists
+// and otherwise adding it, and any missing sections, at the end of its
+// section.
+func (cf *configFile) set(key, value string) error {
+	if _, ok := cf.sections[key]; ok {
+		return fmt.Errorf("%s is a section, not a value", key)
+	}
+
+	parts := strings.Split(key, ".")
+	name := parts[len(parts)-1]
+
+	if e, ok := cf.values[key]; ok {
+		l := cf.lines[e.line-1]
+		indent := l[:len(l)-len(strings.TrimLeft(l, " "))]
+		cf.lines[e.line-1] = indent + name + ": " + quoteConfigValue(value)
+		return cf.parse()
+	}
+
+	// Find the deepest existing section to add to
+	depth := len(parts) - 1
+	at := -1
+	for ; depth > 0; depth-- {
+		if i, ok := cf.sections[strings.Join(parts[:depth], ".")]; ok {
+			at = i
+			break
+		}
+	}
+
+	indent := ""
+	if at >= 0 {
+		l := cf.lines[at]
+		indent = l[:len(l)-len(strings.TrimLeft(l, " "))] + "  "
+	}
+
+	insert := []string{}
+	for _, p := range parts[depth : len(parts)-1] {
+		insert = append(insert, indent+p+":")
+		indent += "  "
+	}
+	insert = append(insert, indent+name+": "+quoteConfigValue(value))
+
+	end := cf.blockEnd(at)
+
+	lines := make([]string, 0, len(cf.lines)+len(insert))
+	lines = append(lines, cf.lines[:end]...)
+	lines = append(lines, insert...)
+	cf.lines = append(lines, cf.lines[end:]...)
+
+	return cf.parse()
+}
+
+// delete removes key and reports whether it existed.
+func (cf *configFile) delete(key string) (bool, error) {
+	e, ok := cf.values[key]
+	if !ok {
+		return false, nil
+	}
+
+	cf.lines = append(cf.lines[:e.line-1], cf.lines[e.line:]...)
+
+	return true, cf.parse()
+}
+
+func (cf *configFile) save() error {
+	if cf.files != nil {
+		return fmt.Errorf("cannot save a merged config")
+	}
+
+	data := ""
+	if len(cf.lines) > 0 {
+		data = strings.Join(cf.lines, "\n") + "\n"
+	}
+
+	return writeFileAtomic(cf.path, []byte(data))
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	data := `# settings
+region: eu-west-1 # the default region
+aliases:
+  dp: "deploy --env prod"
+  st: 'status ''all'''
+
+    # nested
+deploy:
+  retries: 3
+`
+
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]configEntry{
+		"region":         {"eu-west-1", 2},
+		"aliases.dp":     {"deploy --env prod", 4},
+		"aliases.st":     {"status 'all'", 5},
+		"deploy.retries": {"3", 9},
+	}
+
+	if !reflect.DeepEqual(cf.values, expected) {
+		t.Fatalf("Expected %v, got %v", expected, cf.values)
+	}
+
+	if keys := cf.keys("aliases"); !reflect.DeepEqual(keys, []string{"dp", "st"}) {
+		t.Fatalf("Unexpected alias keys: %v", keys)
+	}
+
+	for k, v := range map[string]string{
+		"aliases.dp":         "deploy # prod",
+		"aliases.ls":         "list",
+		"deploy.retries":     "5",
+		"deploy.canary.rate": "0.1",
+		"new.section.key":    "value",
+	} {
+		if err := cf.set(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ok, err := cf.delete("aliases.st"); !ok || err != nil {
+		t.Fatalf("Expected aliases.st to be deleted, got %t and %v", ok, err)
+	}
+
+	if err := cf.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedData := `# settings
+region: eu-west-1 # the default region
+aliases:
+  dp: "deploy # prod"
+  ls: list
+
+    # nested
+deploy:
+  retries: 5
+  canary:
+    rate: 0.1
+new:
+  section:
+    key: value
+`
+
+	if string(b) != expectedData {
+		t.Fatalf("Unexpected config file:\n%s", b)
+	}
+
+	if err := cf.set("deploy", "x"); err == nil {
+		t.Fatal("Expected setting a section to fail")
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	for data, line := range map[string]int{
+		"a: 1\nnot a pair\n": 2,
+		"a:\n\tb: 1\n":       2,
+		"a: \"open\n":        1,
+		"a.b: 1\n":           1,
+	} {
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		var ce *ConfigErr
+		if _, err := loadConfigFile(path); !errors.As(err, &ce) || ce.Line != line {
+			t.Fatalf("Expected an error on line %d for %q, got %v", line, data, err)
+		}
+	}
+
+	cf, err := loadConfigFile(filepath.Join(dir, "missing.yaml"))
+	if err != nil || len(cf.values) != 0 {
+		t.Fatalf("Expected an empty config for a missing file, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"text/tabwriter"
+)
+
+// EnableConfig adds a config command to manage the config file:
+//
+//	app config init [--force]   write a commented template
+//	app config show             print every value and where it is set
+//	app config sources          list the config files in precedence order
+//	app config edit             edit the file in $VISUAL or $EDITOR
+//
+// Values are read from the system, user and project config files, each
+// overriding the ones before it, so an organization can ship defaults that
+// users and repositories adjust. The project file is only read when
+// App.ProjectConfig is set. init and edit work on the user file.
+// Edits are checked before they are saved, so a typo can't leave the file
+// unreadable.
+func (app *App) EnableConfig() {
+	var force *bool
+
+	c := NewCommand("config", "Shell", "Manage the config file: init, show, sources or edit",
+		func(cmd *Command) {
+			cmd.AppendArg("action", "init, show, sources or edit")
+			force = cmd.Flags.Bool("force", false, "Overwrite an existing config file on init")
+		},
+		func(cmd *Command) error {
+			switch cmd.Arg("action") {
+			case "init":
+				return app.initConfig(cmd, *force)
+			case "show":
+				return app.showConfig(cmd)
+			case "sources":
+				return app.showConfigSources(cmd)
+			case "edit":
+				return app.editConfig(cmd)
+			}
+
+			return cmd.usageErr("Invalid config action", ErrArgCount)
+		})
+	c.noProfile = true
+
+	app.AddCommand(c)
+}
+
+func (app *App) configTemplate() string {
+	return fmt.Sprintf(`# Configuration for %s.
+#
+# Values are keys with a colon, one per line, nested by indenting with
+# spaces. Lines starting with # are comments.
+#
+# Aliases expand to a command line:
+#
+# aliases:
+#   dp: deploy --env prod
+`, app.name())
+}
+
+func (app *App) initConfig(cmd *Command, force bool) error {
+	path := app.configPath()
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return errors.New(cmd.app.trf("%s already exists; use --force to overwrite it", path))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(app.configTemplate()), 0600); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.stdout(), cmd.app.trf("Wrote %s", path))
+	return nil
+}
+
+func (app *App) showConfig(cmd *Command) error {
+	cf, err := app.resolveConfig(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(cf.values))
+	for k := range cf.values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(cmd.stdout(), 0, 4, 2, ' ', 0)
+	for _, k := range keys {
+		e := cf.values[k]
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", k, e.value, Provenance{Source: SourceConfig, File: cf.fileOf(k), Line: e.line})
+	}
+
+	return tw.Flush()
+}
+
+func (app *App) showConfigSources(cmd *Command) error {
+	tw := tabwriter.NewWriter(cmd.stdout(), 0, 4, 2, ' ', 0)
+
+	for _, l := range app.configLayers(cmd.Context()) {
+		status := app.tr("not found")
+
+		if l.disabled {
+			status = app.tr("disabled")
+		} else if _, err := os.Stat(l.path); err == nil {
+			cf, err := loadConfigFile(l.path)
+			if err != nil {
+				return err
+			}
+
+			if status = app.trf("%d values", len(cf.values)); len(cf.values) == 1 {
+				status = app.tr("1 value")
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", l.name, l.path, status)
+	}
+
+	return tw.Flush()
+}
+
+// editor returns the user's editor command line.
+func (app *App) editor() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if words, err := splitArgs(app.getenv(name)); err == nil && len(words) > 0 {
+			return words
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+
+	return []string{"vi"}
+}
+
+// editConfig opens a copy of the config file in the editor and replaces the
+// file with it once it parses, offering to edit again when it doesn't.
+func (app *App) editConfig(cmd *Command) error {
+	path := app.configPath()
+
+	orig, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		orig = []byte(app.configTemplate())
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "edit-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(orig)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		argv := append(app.editor(), tmp.Name())
+
+		c := exec.CommandContext(cmd.Context(), argv[0], argv[1:]...)
+		c.Stdin = cmd.stdin()
+		c.Stdout = cmd.stdout()
+		c.Stderr = cmd.stderr()
+
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("editor: %v", err)
+		}
+
+		b, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(b, orig) {
+			return nil
+		}
+
+		_, err = parseConfigFile(path, b)
+		if err == nil {
+			return os.Rename(tmp.Name(), path)
+		}
+
+		fmt.Fprintln(cmd.stderr(), err)
+
+		if !cmd.Confirm(app.tr("Edit again?")) {
+			return fmt.Errorf("config not saved: %v", err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAppConfigCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test editor is a shell command")
+	}
+
+	env := map[string]string{}
+
+	app := NewApp()
+	app.Getenv = func(name string) string { return env[name] }
+	app.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	app.Stdin = strings.NewReader("")
+	app.EnableConfig()
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	if err := app.Run([]string{"app", "config", "init"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := os.ReadFile(app.ConfigFile); string(b) != app.configTemplate() {
+		t.Fatalf("Expected the template, got %q", b)
+	}
+
+	if err := app.Run([]string{"app", "config", "init"}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Expected init to refuse an existing file, got %v", err)
+	}
+
+	env["EDITOR"] = `sh -c 'printf "aliases:\n  dp: deploy --env prod\n" > "$0"'`
+	if err := app.Run([]string{"app", "config", "edit"}); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"app", "config", "show"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "aliases.dp  deploy --env prod  config (" + app.ConfigFile + ":2)\n"; out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+
+	env["EDITOR"] = `sh -c 'printf "aliases\n" > "$0"'`
+	if err := app.Run([]string{"app", "config", "edit"}); err == nil || !strings.Contains(err.Error(), "config not saved") {
+		t.Fatalf("Expected the bad edit to be rejected, got %v", err)
+	}
+
+	if b, _ := os.ReadFile(app.ConfigFile); string(b) != "aliases:\n  dp: deploy --env prod\n" {
+		t.Fatalf("Expected the config to be unchanged, got %q", b)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(app.ConfigFile), "edit-*"))
+	if len(matches) > 0 {
+		t.Fatalf("Expected the edit copy to be removed, got %v", matches)
+	}
+}
+
+func TestAppConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+
+	app := NewApp()
+	app.SystemConfigFile = filepath.Join(dir, "system.yaml")
+	app.ConfigFile = filepath.Join(dir, "user.yaml")
+	app.ProjectConfigFile = filepath.Join(dir, "missing.yaml")
+	app.EnableAliases()
+	app.EnableConfig()
+
+	files := map[string]string{
+		app.SystemConfigFile: "aliases:\n  dp: deploy --env prod\n  st: status\n",
+		app.ConfigFile:       "aliases:\n  dp: deploy --env staging\n",
+	}
+
+	for path, data := range files {
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	if err := app.Run([]string{"app", "config", "show"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "aliases.dp  deploy --env staging  config (" + app.ConfigFile + ":2)\n" +
+		"aliases.st  status                config (" + app.SystemConfigFile + ":3)\n"
+
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"app", "config", "sources"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"system   " + app.SystemConfigFile, "2 values", "user     " + app.ConfigFile, "1 value\n", "project  " + app.ProjectConfigFile, "disabled"} {
+		if !strings.Contains(out.String(), line) {
+			t.Fatalf("Expected %q in:\n%s", line, out.String())
+		}
+	}
+
+	expanded, err := app.expandAliases(context.Background(), []string{"app", "st"})
+	if err != nil || !reflect.DeepEqual(expanded, []string{"app", "status"}) {
+		t.Fatalf("Expected the system alias to expand, got %q %v", expanded, err)
+	}
+	app.ProjectConfig = true
+
+	out.Reset()
+	if err := app.Run([]string{"app", "config", "sources"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "not found") {
+		t.Fatalf("Expected the enabled project file to be looked for:\n%s", out.String())
+	}
+
+	if err := os.WriteFile(app.ProjectConfigFile, []byte("aliases:\n  st: rm -rf\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var ce *ConfigErr
+	if _, err := app.expandAliases(context.Background(), []string{"app", "st"}); !errors.As(err, &ce) || ce.Line != 2 {
+		t.Fatalf("Expected aliases in the project file to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (cmd *Command) assumeYes() bool {
+	if cmd.app != nil && cmd.app.AssumeYes {
+		return true
+	}
+
+	return cmd.yesFlag != nil && *cmd.yesFlag
+}
+
+// Confirm asks the user a yes/no question on the terminal. It returns true
+// without asking when --yes was given or the App assumes yes, and false when
+// stdin is not a terminal.
+func (cmd *Command) Confirm(msg string) bool {
+	if cmd.assumeYes() {
+		return true
+	}
+
+	if !cmd.Interactive() {
+		return false
+	}
+
+	fmt.Fprintf(cmd.stderr(), "%s %s: ", msg, cmd.app.tr("[y/N]"))
+
+	line, err := readLine(cmd.stdin())
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+
+	return false
+}
+
+// ConfirmPhrase asks the user to type expected, such as the name of the
+// resource about to be destroyed. Unlike Confirm, --yes is not enough; the
+// phrase can be given non-interactively with --confirm=<phrase> instead.
+func (cmd *Command) ConfirmPhrase(expected string) bool {
+	if cmd.confirmFlag != nil && *cmd.confirmFlag != "" {
+		return *cmd.confirmFlag == expected
+	}
+
+	if !cmd.Interactive() {
+		return false
+	}
+
+	fmt.Fprint(cmd.stderr(), cmd.app.trf("Type %q to confirm: ", expected))
+
+	line, err := readLine(cmd.stdin())
+	if err != nil {
+		return false
+	}
+
+	return strings.TrimSpace(line) == expected
+}
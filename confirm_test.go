@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCmdConfirm(t *testing.T) {
+	if isTerminal(os.Stdin) {
+		t.Skip("stdin is a terminal")
+	}
+
+	var confirmed bool
+
+	app := NewApp()
+
+	c := NewCommand("delete", "test-group", "deletes things", func(c *Command) {}, func(c *Command) error {
+		confirmed = c.Confirm("Delete everything?")
+		return nil
+	})
+	c.Destructive = true
+	app.AddCommand(c)
+
+	testCases := []struct {
+		args      []string
+		assumeYes bool
+		confirmed bool
+	}{
+		{[]string{"app", "delete"}, false, false},
+		{[]string{"app", "delete", "--yes"}, false, true},
+		{[]string{"app", "delete", "-y=false"}, false, false},
+		{[]string{"app", "delete", "-y"}, false, true},
+		{[]string{"app", "delete", "-y=false"}, true, true},
+	}
+
+	for i, tc := range testCases {
+		app.AssumeYes = tc.assumeYes
+
+		if err := app.Run(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if confirmed != tc.confirmed {
+			t.Fatalf("Expected confirmed: %t from test %d", tc.confirmed, i)
+		}
+	}
+}
+
+func TestCmdConfirmPhrase(t *testing.T) {
+	if isTerminal(os.Stdin) {
+		t.Skip("stdin is a terminal")
+	}
+
+	var confirmed bool
+
+	app := NewApp()
+
+	c := NewCommand("drop", "test-group", "drops a database", func(c *Command) {}, func(c *Command) error {
+		confirmed = c.ConfirmPhrase("prod-db")
+		return nil
+	})
+	c.Destructive = true
+	app.AddCommand(c)
+
+	testCases := []struct {
+		args      []string
+		confirmed bool
+	}{
+		{[]string{"app", "drop"}, false},
+		{[]string{"app", "drop", "--yes"}, false},
+		{[]string{"app", "drop", "--confirm", "staging-db"}, false},
+		{[]string{"app", "drop", "--confirm", "prod-db"}, true},
+	}
+
+	for i, tc := range testCases {
+		if err := app.Run(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if confirmed != tc.confirmed {
+			t.Fatalf("Expected confirmed: %t from test %d", tc.confirmed, i)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const defaultDaemonStopTimeout = 10 * time.Second
+
+// NotRunningErr is returned by the status and stop commands when the daemon
+// is not running.
+type NotRunningErr struct {
+	Name string
+}
+
+func (nr *NotRunningErr) Error() string {
+	return fmt.Sprintf("%s is not running", nr.Name)
+}
+
+// ExitCode is 3, "program is not running" in the LSB init script
+// conventions.
+func (nr *NotRunningErr) ExitCode() int {
+	return 3
+}
+
+// DaemonOptions controls Daemonize.
+type DaemonOptions struct {
+	// Args are passed to the program's own executable.
+	Args []string
+
+	// Env is added to the environment of the background process.
+	Env []string
+
+	// PIDFile, when set, gets the background process's pid. Daemonize fails
+	// with a LockedErr if it names a process that is still running.
+	PIDFile string
+
+	// LogFile receives the background process's stdout and stderr. When
+	// empty they are discarded.
+	LogFile string
+
+	// Dir is the background process's working directory, "/" by default so
+	// it does not keep a mount busy.
+	Dir string
+}
+
+// Daemonize starts the program's executable with opts.Args as a background
+// process detached from the terminal and returns its pid.
+func Daemonize(opts DaemonOptions) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.PIDFile != "" {
+		if pid, ok := daemonPID(opts.PIDFile); ok {
+			return 0, &LockedErr{Command: filepath.Base(exe), Path: opts.PIDFile, PID: pid}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(opts.PIDFile), 0700); err != nil {
+			return 0, err
+		}
+	}
+
+	c := exec.Command(exe, opts.Args...)
+	c.Env = append(os.Environ(), opts.Env...)
+	c.Dir = opts.Dir
+	c.SysProcAttr = detachedAttr()
+
+	if c.Dir == "" {
+		c.Dir = string(filepath.Separator)
+	}
+
+	if opts.LogFile != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.LogFile), 0700); err != nil {
+			return 0, err
+		}
+
+		f, err := os.OpenFile(opts.LogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+
+		c.Stdout, c.Stderr = f, f
+	}
+
+	if err := c.Start(); err != nil {
+		return 0, err
+	}
+
+	pid := c.Process.Pid
+
+	if opts.PIDFile != "" {
+		if err := os.WriteFile(opts.PIDFile, []byte(fmt.Sprintf("%d\n", pid)), 0600); err != nil {
+			stopProcess(c.Process)
+			return 0, err
+		}
+	}
+
+	return pid, c.Process.Release()
+}
+
+// EnableDaemon registers start, stop and status commands for a background
+// process whose work is done by run. start detaches a copy of the program
+// that runs run until stopped, logging to a file in the data dir; with
+// --foreground run is called directly. run should return once the command's
+// Context is canceled, which happens on SIGINT or SIGTERM. The daemon's pid
+// is kept in a pidfile in the runtime dir.
+func (app *App) EnableDaemon(run RunFunc) {
+	pidFile := func() (string, error) {
+		dir, err := app.runtimeDir()
+		return filepath.Join(dir, app.name()+".pid"), err
+	}
+
+	app.AddCommand(NewCommand("start", "Shell", "Start the background process",
+		func(cmd *Command) {
+			cmd.Flags.Bool("foreground", false, "Run in the foreground instead of detaching")
+		},
+		func(cmd *Command) error {
+			path, err := pidFile()
+			if err != nil {
+				return err
+			}
+
+			if on, _ := cmd.Flag("foreground").Bool(); on {
+				// A detached start already wrote our pid
+				lf := &lockFile{path: path}
+				if pid, _ := daemonPID(lf.path); pid != os.Getpid() {
+					var err error
+					if lf, err = acquireLock(lf.path, app.name()); err != nil {
+						return err
+					}
+				}
+				defer lf.release()
+
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+
+				cmd.ctx = ctx
+				return run(cmd)
+			}
+
+			logFile := filepath.Join(app.dataDir(), app.name()+".log")
+
+			pid, err := Daemonize(DaemonOptions{
+				Args:    append(app.mountPath(), "start", "--foreground"),
+				PIDFile: path,
+				LogFile: logFile,
+			})
+			if err != nil {
+				if le, ok := err.(*LockedErr); ok {
+					le.Command, le.app = app.name(), app
+				}
+
+				return err
+			}
+
+			fmt.Fprintln(cmd.Stdout, app.trf("Started %s (pid %d), logging to %s", app.name(), pid, logFile))
+			return nil
+		}))
+
+	app.AddCommand(NewCommand("stop", "Shell", "Stop the background process", func(*Command) {}, func(cmd *Command) error {
+		path, err := pidFile()
+		if err != nil {
+			return err
+		}
+
+		pid, ok := daemonPID(path)
+		if !ok {
+			return &NotRunningErr{Name: app.name()}
+		}
+
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+
+		if err := stopProcess(p); err != nil {
+			return err
+		}
+
+		timeout := app.DaemonStopTimeout
+		if timeout <= 0 {
+			timeout = defaultDaemonStopTimeout
+		}
+
+		for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if _, ok := daemonPID(path); !ok {
+				fmt.Fprintln(cmd.Stdout, app.trf("Stopped %s (pid %d)", app.name(), pid))
+				return nil
+			}
+		}
+
+		return errors.New(app.trf("%s (pid %d) did not stop within %s", app.name(), pid, timeout))
+	}))
+
+	app.AddCommand(NewCommand("status", "Shell", "Show whether the background process is running", func(*Command) {}, func(cmd *Command) error {
+		path, err := pidFile()
+		if err != nil {
+			return err
+		}
+
+		pid, ok := daemonPID(path)
+		if !ok {
+			return &NotRunningErr{Name: app.name()}
+		}
+
+		fmt.Fprintln(cmd.Stdout, app.trf("%s is running (pid %d)", app.name(), pid))
+		return nil
+	}))
+}
+
+// mountPath returns the command names leading to app when it is mounted in
+// another app.
+func (app *App) mountPath() []string {
+	var path []string
+	for a := app; a.parent != nil; a = a.parent {
+		path = append([]string{a.mountedAs}, path...)
+	}
+
+	return path
+}
+
+// daemonPID returns the pid in the pidfile at path if that process is alive.
+func daemonPID(path string) (int, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+
+	return pid, pid > 0 && processAlive(pid)
+}
@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+func detachedAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+func stopProcess(p *os.Process) error {
+	return p.Kill()
+}
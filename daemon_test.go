@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDaemonHelperProcess(t *testing.T) {
+	if os.Getenv("CMD_DAEMON_HELPER") == "" {
+		return
+	}
+
+	fmt.Println("daemon says hi")
+	os.Exit(0)
+}
+
+func TestDaemonize(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no detached processes in this test on windows")
+	}
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "d.log")
+	pidFile := filepath.Join(dir, "d.pid")
+
+	pid, err := Daemonize(DaemonOptions{
+		Args:    []string{"-test.run=TestDaemonHelperProcess"},
+		Env:     []string{"CMD_DAEMON_HELPER=1"},
+		PIDFile: pidFile,
+		LogFile: logFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := os.ReadFile(pidFile); strings.TrimSpace(string(b)) != fmt.Sprint(pid) {
+		t.Fatalf("Expected pid %d in the pidfile, got %q", pid, b)
+	}
+
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		if b, _ := os.ReadFile(logFile); strings.Contains(string(b), "daemon says hi") {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the daemon's output in the log file")
+		}
+	}
+}
+
+func TestEnableDaemon(t *testing.T) {
+	started := make(chan struct{})
+
+	app := NewApp()
+	app.Name = "daemontest"
+	app.RuntimeDir = t.TempDir()
+	app.EnableDaemon(func(cmd *Command) error {
+		close(started)
+		<-cmd.Context().Done()
+		return nil
+	})
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	err := app.Run([]string{"daemontest", "status"})
+	if ec, ok := err.(interface{ ExitCode() int }); !ok || ec.ExitCode() != 3 {
+		t.Fatalf("Expected a not running error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- app.RunContext(ctx, []string{"daemontest", "start", "--foreground"})
+	}()
+	<-started
+
+	if err := app.Run([]string{"daemontest", "status"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := fmt.Sprintf("daemontest is running (pid %d)\n", os.Getpid()); out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := daemonPID(filepath.Join(app.RuntimeDir, "daemontest.pid")); ok {
+		t.Fatal("Expected the pidfile to be removed")
+	}
+}
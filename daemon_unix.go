@@ -0,0 +1,16 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+func stopProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+const detachedProcess = 0x00000008
+
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
+
+func stopProcess(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"strings"
+)
+
+const decorationsEnv = "CMD_DECORATIONS"
+
+// Symbols are the glyphs used to decorate output, in Unicode or in their
+// ASCII fallbacks.
+type Symbols struct {
+	OK      string
+	Warn    string
+	Fail    string
+	Bullet  string
+	Pointer string
+	Spinner []string
+}
+
+var (
+	unicodeSymbols = Symbols{
+		OK:      "✔",
+		Warn:    "⚠",
+		Fail:    "✘",
+		Bullet:  "•",
+		Pointer: "❯",
+		Spinner: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	}
+
+	asciiSymbols = Symbols{
+		OK:      "[ OK ]",
+		Warn:    "[WARN]",
+		Fail:    "[FAIL]",
+		Bullet:  "*",
+		Pointer: ">",
+		Spinner: []string{"|", "/", "-", "\\"},
+	}
+)
+
+// Symbols returns the glyphs the command should decorate its output with,
+// e.g. Symbols().Bullet for list items. See App.Decorations.
+func (cmd *Command) Symbols() Symbols {
+	return cmd.symbolsFor(cmd.stdout())
+}
+
+func (cmd *Command) symbolsFor(w io.Writer) Symbols {
+	if cmd.app.decorations(cmd.Context(), w) {
+		return unicodeSymbols
+	}
+
+	return asciiSymbols
+}
+
+// decorations decides whether Unicode symbols are written to w. In order of
+// precedence: App.Decorations, CMD_DECORATIONS, plain mode, and finally
+// whether w is a terminal with a UTF-8 locale that can render them.
+func (app *App) decorations(ctx context.Context, w io.Writer) bool {
+	t := Auto
+	if app != nil {
+		t = app.Decorations
+	}
+
+	if t == Auto {
+		t, _ = parseToggle(app.getenvIn(ctx, decorationsEnv))
+	}
+
+	switch t {
+	case Always:
+		return true
+	case Never:
+		return false
+	}
+
+	if app.plainIn(ctx) || !IsTerminal(w) {
+		return false
+	}
+
+	return app.unicodeTerminal()
+}
+
+func (app *App) unicodeTerminal() bool {
+	if runtime.GOOS == "windows" {
+		// Windows Terminal renders them, the legacy console does not
+		return app.getenv("WT_SESSION") != ""
+	}
+
+	if app.getenv("TERM") == "linux" {
+		return false
+	}
+
+	for _, v := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if l := strings.ToLower(app.getenv(v)); l != "" {
+			return strings.Contains(l, "utf-8") || strings.Contains(l, "utf8")
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDoctorDecorations(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Decorations = Always
+
+	app.AddCheck("config readable", func(ctx context.Context) error {
+		return nil
+	})
+	app.AddCheck("docker reachable", func(ctx context.Context) error {
+		return CheckFailure(errors.New("connection refused"), "Start the docker daemon")
+	})
+
+	app.Run([]string{"app", "doctor"})
+
+	expected := "✔ config readable\n✘ docker reachable: connection refused\n  Start the docker daemon\n"
+	if out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestAppDecorations(t *testing.T) {
+	env := map[string]string{}
+
+	app := NewApp()
+	app.Getenv = func(name string) string { return env[name] }
+
+	if app.decorations(context.Background(), &strings.Builder{}) {
+		t.Fatal("Expected no decorations when not writing to a terminal")
+	}
+
+	env[decorationsEnv] = "always"
+	if !app.decorations(context.Background(), &strings.Builder{}) {
+		t.Fatal("Expected CMD_DECORATIONS=always to force decorations")
+	}
+
+	env[decorationsEnv] = "never"
+	app.Decorations = Always
+	if !app.decorations(context.Background(), &strings.Builder{}) {
+		t.Fatal("Expected App.Decorations to take precedence")
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	env["LANG"] = "en_US.UTF-8"
+	if !app.unicodeTerminal() {
+		t.Fatal("Expected a UTF-8 locale to render Unicode")
+	}
+
+	env["LC_ALL"] = "C"
+	if app.unicodeTerminal() {
+		t.Fatal("Expected LC_ALL=C to fall back to ASCII")
+	}
+}
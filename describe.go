@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"sort"
+	"time"
+)
+
+type ArgInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Variable    bool   `json:"variable,omitempty"`
+	Stdin       bool   `json:"stdin,omitempty"`
+
+	// Complete tells shells to complete the arg with a "file" or "dir",
+	// limited to Extensions.
+	Complete   string   `json:"complete,omitempty"`
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+type FlagInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+	Metavar     string `json:"metavar,omitempty"`
+	Group       string `json:"group,omitempty"`
+}
+
+type EnvArgInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Secret      bool   `json:"secret,omitempty"`
+	Optional    bool   `json:"optional,omitempty"`
+}
+
+// CommandInfo is the machine-readable description of a command.
+type CommandInfo struct {
+	Name        string       `json:"name"`
+	Group       string       `json:"group"`
+	Description string       `json:"description"`
+	Args        []ArgInfo    `json:"args"`
+	Flags       []FlagInfo   `json:"flags"`
+	EnvArgs     []EnvArgInfo `json:"env_args"`
+	Keywords    []string     `json:"keywords,omitempty"`
+	Examples    []string     `json:"examples,omitempty"`
+
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Stability   Stability         `json:"stability,omitempty"`
+}
+
+func (cmd *Command) Describe() CommandInfo {
+	info := CommandInfo{
+		Name:        cmd.Name,
+		Group:       cmd.Group,
+		Description: cmd.Description,
+		Args:        []ArgInfo{},
+		Flags:       []FlagInfo{},
+		EnvArgs:     []EnvArgInfo{},
+		Keywords:    cmd.Keywords,
+		Examples:    cmd.Examples,
+		Annotations: cmd.Annotations,
+		Stability:   cmd.Stability,
+	}
+
+	for _, a := range cmd.Args {
+		ai := ArgInfo{Name: a.Name, Description: a.Description, Variable: a.Variable, Stdin: a.Stdin}
+		if a.path != nil {
+			ai.Complete = a.path.kind()
+			ai.Extensions = a.path.opts.Extensions
+		}
+
+		info.Args = append(info.Args, ai)
+	}
+
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if cmd.hiddenFlags[f.Name] {
+			return
+		}
+
+		info.Flags = append(info.Flags, FlagInfo{
+			Name:        f.Name,
+			Type:        flagType(f),
+			Default:     f.DefValue,
+			Description: f.Usage,
+			Metavar:     cmd.metavars[f.Name],
+			Group:       cmd.flagGroups[f.Name],
+		})
+	})
+
+	for n, d := range cmd.EnvArgs {
+		info.EnvArgs = append(info.EnvArgs, EnvArgInfo{Name: n, Description: d, Secret: cmd.isSecret(n)})
+	}
+
+	for n, d := range cmd.optionalEnv {
+		if _, ok := cmd.EnvArgs[n]; ok {
+			continue
+		}
+
+		info.EnvArgs = append(info.EnvArgs, EnvArgInfo{Name: n, Description: d, Secret: cmd.isSecret(n), Optional: true})
+	}
+
+	sort.Slice(info.EnvArgs, func(i, j int) bool {
+		return info.EnvArgs[i].Name < info.EnvArgs[j].Name
+	})
+
+	return info
+}
+
+func flagType(f *flag.Flag) string {
+	switch f.Value.(type) {
+	case *bytesValue:
+		return "bytes"
+	case *longDurationValue:
+		return "duration"
+	case *listValue:
+		return "list"
+	}
+
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return "value"
+	}
+
+	switch g.Get().(type) {
+	case bool:
+		return "bool"
+	case int, int64:
+		return "int"
+	case uint, uint64:
+		return "uint"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case time.Duration:
+		return "duration"
+	}
+
+	return "value"
+}
+
+func (cmd *Command) printJSONHelp() error {
+	enc := json.NewEncoder(cmd.stdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(cmd.Describe())
+}
+
+// AppInfo is the machine-readable description of an app and all of its
+// visible commands.
+type AppInfo struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Commands    []CommandInfo `json:"commands"`
+}
+
+func (app *App) Describe() AppInfo {
+	info := AppInfo{
+		Name:        app.name(),
+		Description: app.Description,
+		Commands:    []CommandInfo{},
+	}
+
+	for name, cmd := range app.Commands {
+		if app.listed(context.Background(), cmd) {
+			cmd, _ = app.command(name)
+
+			_, release := hold(context.Background(), cmd)
+			info.Commands = append(info.Commands, cmd.Describe())
+			release()
+		}
+	}
+
+	sort.Slice(info.Commands, func(i, j int) bool {
+		return info.Commands[i].Name < info.Commands[j].Name
+	})
+
+	return info
+}
+
+func (app *App) addDescribeCommand() {
+	c := NewCommand("__describe", "", "Print a JSON description of all commands", func(cmd *Command) {}, func(cmd *Command) error {
+		enc := json.NewEncoder(cmd.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(app.Describe())
+	})
+	c.Hidden = true
+
+	app.AddCommand(c)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCmdDescribe(t *testing.T) {
+	c := NewCommand("deploy", "test-group", "deploys things", nil, nil)
+	c.AppendArg("service", "the service")
+	c.AppendVarArg("hosts", "the hosts")
+	c.AddEnvArg("TOKEN_B", "a token")
+	c.AddSecretEnvArg("TOKEN_A", "a secret token")
+	c.Flags.Bool("force", false, "force it")
+	c.Flags.Duration("wait", time.Second, "how long to wait")
+	c.Examples = []string{"deploy web host1"}
+	c.Annotations = map[string]string{"requires-auth": "true"}
+
+	info := c.Describe()
+
+	expected := CommandInfo{
+		Name:        "deploy",
+		Group:       "test-group",
+		Description: "deploys things",
+		Args: []ArgInfo{
+			{Name: "service", Description: "the service"},
+			{Name: "hosts", Description: "the hosts", Variable: true},
+		},
+		Flags: []FlagInfo{
+			{Name: "force", Type: "bool", Default: "false", Description: "force it"},
+			{Name: "wait", Type: "duration", Default: "1s", Description: "how long to wait"},
+		},
+		EnvArgs: []EnvArgInfo{
+			{Name: "TOKEN_A", Description: "a secret token", Secret: true},
+			{Name: "TOKEN_B", Description: "a token"},
+		},
+		Examples:    []string{"deploy web host1"},
+		Annotations: map[string]string{"requires-auth": "true"},
+	}
+
+	if !reflect.DeepEqual(info, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, info)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Toggle is a three-way setting: decided automatically, or forced on or off.
+type Toggle int
+
+const (
+	Auto Toggle = iota
+	Always
+	Never
+)
+
+var ciEnvVars = []string{
+	"CI",
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"BUILDKITE",
+	"CIRCLECI",
+	"TRAVIS",
+	"JENKINS_URL",
+	"TEAMCITY_VERSION",
+	"TF_BUILD",
+}
+
+// IsCI reports whether the process appears to run under a CI system.
+func IsCI() bool {
+	return (*App)(nil).isCI()
+}
+
+func (app *App) isCI() bool {
+	for _, n := range ciEnvVars {
+		if v := app.getenv(n); v != "" && v != "false" && v != "0" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTerminal reports whether w is connected to a terminal.
+func IsTerminal(w interface{}) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// interactiveWriter reports whether w is a terminal outside of CI and plain
+// mode, where in-place redraws are appropriate.
+func (cmd *Command) interactiveWriter(w io.Writer) bool {
+	return IsTerminal(w) && !cmd.app.isCI() && !cmd.Plain()
+}
+
+// interactive reports whether the user can be prompted on the stdin of the
+// run in ctx, honoring App.Interactive.
+func (app *App) interactive(ctx context.Context) bool {
+	return app.interactiveOn(app.stdinIn(ctx))
+}
+
+func (app *App) interactiveOn(r io.Reader) bool {
+	if app != nil {
+		switch app.Interactive {
+		case Always:
+			return true
+		case Never:
+			return false
+		}
+	}
+
+	return IsTerminal(r)
+}
+
+// Interactive reports whether the command may prompt the user.
+func (cmd *Command) Interactive() bool {
+	return cmd.app.interactiveOn(cmd.stdin())
+}
+
+func (t Toggle) String() string {
+	switch t {
+	case Always:
+		return "always"
+	case Never:
+		return "never"
+	}
+
+	return "auto"
+}
+
+func parseToggle(s string) (Toggle, error) {
+	switch strings.ToLower(s) {
+	case "auto", "":
+		return Auto, nil
+	case "always", "on", "true", "yes":
+		return Always, nil
+	case "never", "off", "false", "no":
+		return Never, nil
+	}
+
+	return Auto, fmt.Errorf("invalid value %q, use auto, always or never", s)
+}
+
+// toggleValue is a flag.Value for auto|always|never flags.
+type toggleValue Toggle
+
+func (tv *toggleValue) String() string {
+	return Toggle(*tv).String()
+}
+
+func (tv *toggleValue) Set(s string) error {
+	t, err := parseToggle(s)
+	*tv = toggleValue(t)
+	return err
+}
+
+func (tv *toggleValue) Get() interface{} {
+	return Toggle(*tv).String()
+}
+
+// ColorEnabled reports whether the command's output should be colored. See
+// colorDecision for how this is decided; plain mode turns color off unless
+// --color=always is given.
+func (cmd *Command) ColorEnabled() bool {
+	flagged := Auto
+	if cmd.colorFlag != nil {
+		flagged = Toggle(*cmd.colorFlag)
+	}
+
+	if flagged == Auto && cmd.Plain() {
+		return false
+	}
+
+	return cmd.app.colorDecision(flagged, cmd.stdout())
+}
+
+func (app *App) colorEnabled(w io.Writer) bool {
+	return app.colorDecision(Auto, w)
+}
+
+// colorDecision is the single place color is decided. In order of
+// precedence: the --color flag, App.Color, NO_COLOR, CLICOLOR_FORCE,
+// CLICOLOR=0, and finally whether w is a terminal other than TERM=dumb.
+func (app *App) colorDecision(flagged Toggle, w io.Writer) bool {
+	switch flagged {
+	case Always:
+		return true
+	case Never:
+		return false
+	}
+
+	if app != nil {
+		switch app.Color {
+		case Always:
+			return true
+		case Never:
+			return false
+		}
+	}
+
+	if app.getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	if v := app.getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+
+	if app.getenv("CLICOLOR") == "0" {
+		return false
+	}
+
+	return IsTerminal(w) && app.getenv("TERM") != "dumb"
+}
+
+func bold(s string, color bool) string {
+	if !color {
+		return s
+	}
+
+	return "\x1b[1m" + s + "\x1b[0m"
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIsCI(t *testing.T) {
+	for _, n := range ciEnvVars {
+		t.Setenv(n, "")
+	}
+
+	if IsCI() {
+		t.Fatal("Expected no CI with every variable cleared")
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	if !IsCI() {
+		t.Fatal("Expected CI to be detected")
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "false")
+
+	if IsCI() {
+		t.Fatal("Expected a false value to be ignored")
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if IsTerminal(&strings.Builder{}) {
+		t.Fatal("A strings.Builder is not a terminal")
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if IsTerminal(f) {
+		t.Fatal("A regular file is not a terminal")
+	}
+}
+
+func TestAppOverrides(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.Flags.Bool("flag", false, "a flag")
+	}, nil)
+	app.AddCommand(c)
+
+	if c.ColorEnabled() {
+		t.Fatal("Expected no color when writing to a buffer")
+	}
+
+	app.Color = Always
+	app.Interactive = Never
+
+	if !c.ColorEnabled() || c.Interactive() {
+		t.Fatal("Expected the App overrides to apply")
+	}
+
+	c.Usage()
+
+	if !strings.Contains(out.String(), "\x1b[1mFlags:\x1b[0m") {
+		t.Fatalf("Expected a bold header, got %q", out.String())
+	}
+}
+
+func TestColorDecision(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("CLICOLOR", "")
+
+	var out strings.Builder
+	enabled := false
+
+	app := NewApp()
+	app.Stdout = &out
+	app.ColorFlag = true
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {}, func(c *Command) error {
+		enabled = c.ColorEnabled()
+		return nil
+	}))
+
+	testCases := []struct {
+		env     map[string]string
+		app     Toggle
+		flag    string
+		enabled bool
+	}{
+		{nil, Auto, "", false},
+		{map[string]string{"CLICOLOR_FORCE": "1"}, Auto, "", true},
+		{map[string]string{"CLICOLOR_FORCE": "1", "NO_COLOR": "1"}, Auto, "", false},
+		{map[string]string{"NO_COLOR": "1"}, Always, "", true},
+		{map[string]string{"CLICOLOR_FORCE": "1"}, Never, "", false},
+		{map[string]string{"NO_COLOR": "1"}, Never, "always", true},
+		{map[string]string{"CLICOLOR_FORCE": "1"}, Always, "never", false},
+	}
+
+	for i, tc := range testCases {
+		for _, n := range []string{"NO_COLOR", "CLICOLOR_FORCE"} {
+			os.Setenv(n, tc.env[n])
+		}
+
+		app.Color = tc.app
+
+		flag := tc.flag
+		if flag == "" {
+			flag = "auto"
+		}
+
+		if err := app.Run([]string{"app", "test", "--color", flag}); err != nil {
+			t.Fatal(err)
+		}
+
+		if enabled != tc.enabled {
+			t.Fatalf("Expected color enabled: %t from test %d", tc.enabled, i)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+func (app *App) name() string {
+	if app != nil && app.parent != nil {
+		return app.parent.name() + " " + app.mountedAs
+	}
+
+	if app != nil && app.Name != "" {
+		return app.Name
+	}
+
+	return progName(os.Args[0])
+}
+
+// progName turns argv[0] into a short program name, dropping any directory
+// and a Windows executable extension.
+func progName(arg0 string) string {
+	name := filepath.Base(arg0)
+
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = name[:len(name)-len(ext)]
+	}
+
+	return name
+}
+
+func (app *App) dataDir() string {
+	if app != nil && app.DataDir != "" {
+		return app.DataDir
+	}
+
+	if app != nil && app.parent != nil {
+		return filepath.Join(app.parent.dataDir(), app.mountedAs)
+	}
+
+	return filepath.Join(userDataDir(app.getenv), app.name())
+}
+
+func userDataDir(getenv func(string) string) string {
+	if dir := getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		if dir := getenv("LocalAppData"); dir != "" {
+			return dir
+		}
+		return filepath.Join(home, "AppData", "Local")
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support")
+	}
+
+	return filepath.Join(home, ".local", "share")
+}
+
+// runtimeDir is where pidfiles, locks and sockets go. Without
+// XDG_RUNTIME_DIR it falls back to a directory in the shared temp dir, which
+// must be private to the current user as its name is predictable.
+func (app *App) runtimeDir() (string, error) {
+	if app != nil && app.RuntimeDir != "" {
+		return app.RuntimeDir, nil
+	}
+
+	if app != nil && app.parent != nil {
+		dir, err := app.parent.runtimeDir()
+		return filepath.Join(dir, app.mountedAs), err
+	}
+
+	if dir := app.getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, app.name()), nil
+	}
+
+	name := app.name()
+	if uid := os.Getuid(); uid >= 0 {
+		name += "-" + strconv.Itoa(uid)
+	}
+
+	dir := filepath.Join(os.TempDir(), name)
+	return dir, privateDir(dir)
+}
+
+// privateDir creates dir for the current user alone, or checks that an
+// existing dir is theirs alone, so no one else can plant files in it.
+func privateDir(dir string) error {
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !fi.IsDir() || !privateToUser(fi) {
+		return fmt.Errorf("%s is not a directory private to the current user; remove it or set XDG_RUNTIME_DIR", dir)
+	}
+
+	return nil
+}
+
+func (app *App) cacheDir() string {
+	if app != nil && app.CacheDir != "" {
+		return app.CacheDir
+	}
+
+	if app != nil && app.parent != nil {
+		return filepath.Join(app.parent.cacheDir(), app.mountedAs)
+	}
+
+	return filepath.Join(userCacheDir(app.getenv), app.name())
+}
+
+func userCacheDir(getenv func(string) string) string {
+	if dir := getenv("XDG_CACHE_HOME"); dir != "" {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		if dir := getenv("LocalAppData"); dir != "" {
+			return dir
+		}
+		return filepath.Join(home, "AppData", "Local")
+	case "darwin":
+		return filepath.Join(home, "Library", "Caches")
+	}
+
+	return filepath.Join(home, ".cache")
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// privateToUser reports whether fi is private to the current user. The temp
+// dir the runtime dir falls back to is already per user here.
+func privateToUser(fi os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+func TestProgName(t *testing.T) {
+	testCases := []struct {
+		arg0 string
+		name string
+	}{
+		{"tool", "tool"},
+		{"/usr/local/bin/tool", "tool"},
+		{"./tool", "tool"},
+		{"tool.exe", "tool"},
+		{"tool.EXE", "tool"},
+		{"tool.sh", "tool.sh"},
+	}
+
+	for _, tc := range testCases {
+		if n := progName(tc.arg0); n != tc.name {
+			t.Fatalf("Expected %s for %s, got %s", tc.name, tc.arg0, n)
+		}
+	}
+
+	app := NewApp()
+	app.Name = "override"
+
+	if n := app.name(); n != "override" {
+		t.Fatalf("Expected App.Name to be used, got %s", n)
+	}
+}
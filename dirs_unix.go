@@ -0,0 +1,15 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// privateToUser reports whether fi is owned by the current user and not
+// open to anyone else.
+func privateToUser(fi os.FileInfo) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid() && fi.Mode().Perm()&0077 == 0
+}
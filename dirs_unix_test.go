@@ -0,0 +1,38 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrivateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+
+	if err := privateDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm() != 0700 {
+		t.Fatalf("Expected a dir only we can use, got %v", err)
+	}
+
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := privateDir(dir); err == nil {
+		t.Fatal("Expected a dir open to others to be refused")
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(t.TempDir(), link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := privateDir(link); err == nil {
+		t.Fatal("Expected a symlink to be refused")
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CheckFunc is a diagnostic run by the doctor command. Returning nil passes
+// the check; errors fail it unless made with CheckWarning.
+type CheckFunc func(ctx context.Context) error
+
+// CheckErr is a check failure with a remediation hint.
+type CheckErr struct {
+	Err     error
+	Hint    string
+	Warning bool
+}
+
+func (ce *CheckErr) Error() string {
+	return ce.Err.Error()
+}
+
+func (ce *CheckErr) Unwrap() error {
+	return ce.Err
+}
+
+// CheckFailure fails a check with a hint on how to fix it.
+func CheckFailure(err error, hint string) error {
+	return &CheckErr{Err: err, Hint: hint}
+}
+
+// CheckWarning reports a problem that does not fail the check.
+func CheckWarning(err error, hint string) error {
+	return &CheckErr{Err: err, Hint: hint, Warning: true}
+}
+
+// DoctorErr is returned by the doctor command when checks failed.
+type DoctorErr struct {
+	Failed int
+	Total  int
+}
+
+func (de *DoctorErr) Error() string {
+	return fmt.Sprintf("%d of %d checks failed", de.Failed, de.Total)
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// AddCheck registers a diagnostic run by the doctor command, which is added
+// to the App with the first check.
+func (app *App) AddCheck(name string, fn CheckFunc) {
+	app.checkFrozen("AddCheck")
+
+	app.checks = append(app.checks, check{name, fn})
+	app.addDoctorCommand()
+}
+
+// AddCheck registers a diagnostic for this command, run by the doctor command
+// under the command's name.
+func (cmd *Command) AddCheck(name string, fn CheckFunc) {
+	cmd.checks = append(cmd.checks, check{name, fn})
+
+	if cmd.app != nil {
+		cmd.app.addDoctorCommand()
+	}
+}
+
+func (app *App) addDoctorCommand() {
+	if _, ok := app.Commands["doctor"]; ok {
+		return
+	}
+
+	app.AddCommand(NewCommand("doctor", "Shell", "Check that everything the app needs is working",
+		func(cmd *Command) {},
+		func(cmd *Command) error {
+			return app.runChecks(cmd)
+		}))
+}
+
+func (app *App) runChecks(cmd *Command) error {
+	checks := append([]check{}, app.checks...)
+
+	names := make([]string, 0, len(app.Commands))
+	for n := range app.Commands {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		cmd, _ := app.command(n)
+
+		for _, c := range cmd.checks {
+			checks = append(checks, check{n + ": " + c.name, c.fn})
+		}
+	}
+
+	failed := 0
+	sym := cmd.Symbols()
+	indent := strings.Repeat(" ", displayWidth(sym.Fail)+1)
+
+	for _, c := range checks {
+		err := c.fn(cmd.Context())
+
+		var ce *CheckErr
+		errors.As(err, &ce)
+
+		switch {
+		case err == nil:
+			fmt.Fprintf(cmd.Stdout, "%s %s\n", sym.OK, c.name)
+			continue
+		case ce != nil && ce.Warning:
+			fmt.Fprintf(cmd.Stdout, "%s %s: %v\n", sym.Warn, c.name, err)
+		default:
+			fmt.Fprintf(cmd.Stdout, "%s %s: %v\n", sym.Fail, c.name, err)
+			failed++
+		}
+
+		if ce != nil && ce.Hint != "" {
+			fmt.Fprintf(cmd.Stdout, "%s%s\n", indent, ce.Hint)
+		}
+	}
+
+	if failed > 0 {
+		return &DoctorErr{Failed: failed, Total: len(checks)}
+	}
+
+	return nil
+}
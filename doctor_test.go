@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+
+	app.AddCheck("config readable", func(ctx context.Context) error {
+		return nil
+	})
+	app.AddCheck("disk space", func(ctx context.Context) error {
+		return CheckWarning(errors.New("only 1GB free"), "Free up some disk space")
+	})
+
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.AddCheck("docker reachable", func(ctx context.Context) error {
+			return CheckFailure(errors.New("connection refused"), "Start the docker daemon")
+		})
+	}, func(c *Command) error {
+		return nil
+	}))
+
+	err := app.Run([]string{"app", "doctor"})
+
+	var de *DoctorErr
+	if !errors.As(err, &de) || de.Failed != 1 || de.Total != 3 || ExitCode(err) != 1 {
+		t.Fatalf("Expected 1 of 3 checks to fail, got %v", err)
+	}
+
+	expected := `[ OK ] config readable
+[WARN] disk space: only 1GB free
+       Free up some disk space
+[FAIL] deploy: docker reachable: connection refused
+       Start the docker daemon
+`
+
+	if out.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import "fmt"
+
+// DryRun reports whether --dry-run was given.
+func (cmd *Command) DryRun() bool {
+	return cmd.dryRunFlag != nil && *cmd.dryRunFlag
+}
+
+// Action describes something the command is about to do. During a dry run
+// it prints the description and returns false, so commands can write
+//
+//	if cmd.Action("would delete %s", path) {
+//		os.Remove(path)
+//	}
+//
+// Otherwise it prints nothing and returns true.
+func (cmd *Command) Action(format string, args ...interface{}) bool {
+	if !cmd.DryRun() {
+		return true
+	}
+
+	fmt.Fprintf(cmd.stdout(), "[dry-run] %s\n", fmt.Sprintf(format, args...))
+	return false
+}
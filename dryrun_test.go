@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	var out strings.Builder
+	deleted := []string{}
+
+	app := NewApp()
+	app.Stdout = &out
+
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.AppendVarArg("files", "files to delete")
+	}, func(c *Command) error {
+		for _, f := range c.VarArgs() {
+			if c.Action("would delete %s", f) {
+				deleted = append(deleted, f.String())
+			}
+		}
+
+		return nil
+	})
+	c.SupportsDryRun = true
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "test", "--dry-run", "a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deleted) != 0 {
+		t.Fatalf("Expected nothing deleted, got %v", deleted)
+	}
+
+	if out.String() != "[dry-run] would delete a\n[dry-run] would delete b\n" {
+		t.Fatalf("Unexpected output: %q", out.String())
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"app", "test", "--dry-run=false", "a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deleted) != 2 || out.Len() != 0 {
+		t.Fatalf("Expected a and b deleted silently, got %v and %q", deleted, out.String())
+	}
+}
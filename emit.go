@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// OutputFormat returns the format Emit renders in: the --output flag when
+// the App adds it, otherwise the App's DefaultOutput, otherwise table.
+func (cmd *Command) OutputFormat() string {
+	if cmd.outputFlag != nil && *cmd.outputFlag != "" {
+		return *cmd.outputFlag
+	}
+
+	if cmd.app != nil && cmd.app.DefaultOutput != "" {
+		return cmd.app.DefaultOutput
+	}
+
+	return OutputTable
+}
+
+// Emit writes v to the command's stdout in the selected output format.
+// Slices of structs or maps render as tables with a column per field; a
+// single struct or map renders as key/value rows.
+func (cmd *Command) Emit(v interface{}) error {
+	format := cmd.OutputFormat()
+
+	switch format {
+	case OutputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cmd.stdout(), "%s\n", b)
+		return err
+	case OutputYAML:
+		n, err := toNode(v)
+		if err != nil {
+			return err
+		}
+
+		n.writeYAML(cmd.stdout())
+		return nil
+	case OutputTable:
+		n, err := toNode(v)
+		if err != nil {
+			return err
+		}
+
+		return writeTable(cmd.stdout(), n)
+	}
+
+	return cmd.usageErr(fmt.Sprintf("Invalid output format %q; use table, json or yaml", format), nil)
+}
+
+func writeTable(w io.Writer, n *node) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	switch n.kind {
+	case nodeArray:
+		columns := []string{}
+		seen := map[string]bool{}
+
+		for _, row := range n.values {
+			for _, k := range row.keys {
+				if !seen[k] {
+					seen[k] = true
+					columns = append(columns, k)
+				}
+			}
+		}
+
+		if len(columns) == 0 {
+			for _, row := range n.values {
+				fmt.Fprintln(tw, row.text())
+			}
+
+			break
+		}
+
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = strings.ToUpper(c)
+		}
+
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+		for _, row := range n.values {
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = cellText(row.get(c))
+			}
+
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	case nodeObject:
+		for i, k := range n.keys {
+			fmt.Fprintf(tw, "%s:\t%s\n", k, cellText(n.values[i]))
+		}
+	default:
+		fmt.Fprintln(tw, n.text())
+	}
+
+	return tw.Flush()
+}
+
+func cellText(n *node) string {
+	return strings.Replace(strings.Replace(n.text(), "\t", " ", -1), "\n", " ", -1)
+}
+
+// EmitStream writes each value received from ch, which must be a channel, as
+// soon as it arrives, until ch is closed or the run is interrupted. With the
+// json format each value is written as one line of JSON (JSON Lines); with
+// yaml each value is its own document; tables print a header from the first
+// value and then one tab-separated row per value.
+func (cmd *Command) EmitStream(ch interface{}) error {
+	cv := reflect.ValueOf(ch)
+	if cv.Kind() != reflect.Chan || cv.Type().ChanDir()&reflect.RecvDir == 0 {
+		return fmt.Errorf("EmitStream needs a receivable channel, got %T", ch)
+	}
+
+	format := cmd.OutputFormat()
+	switch format {
+	case OutputJSON, OutputYAML, OutputTable:
+	default:
+		return cmd.usageErr(fmt.Sprintf("Invalid output format %q; use table, json or yaml", format), nil)
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(cmd.Context().Done())},
+		{Dir: reflect.SelectRecv, Chan: cv},
+	}
+
+	w := cmd.stdout()
+	var columns []string
+
+	for {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 || !ok {
+			return nil
+		}
+
+		n, err := toNode(v.Interface())
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case OutputJSON:
+			n.writeJSON(w)
+			io.WriteString(w, "\n")
+		case OutputYAML:
+			io.WriteString(w, "---\n")
+			n.writeYAML(w)
+		case OutputTable:
+			if n.kind != nodeObject {
+				fmt.Fprintln(w, cellText(n))
+				continue
+			}
+
+			if columns == nil {
+				columns = n.keys
+
+				headers := make([]string, len(columns))
+				for i, c := range columns {
+					headers[i] = strings.ToUpper(c)
+				}
+
+				fmt.Fprintln(w, strings.Join(headers, "\t"))
+			}
+
+			cells := make([]string, len(columns))
+			for i, c := range columns {
+				cells[i] = cellText(n.get(c))
+			}
+
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+	}
+}
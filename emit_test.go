@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+type emitRegion struct {
+	Name    string            `json:"name"`
+	Zones   int               `json:"zones"`
+	Enabled bool              `json:"enabled"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+func TestCmdEmit(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+	app.OutputFlag = true
+
+	regions := []emitRegion{
+		{Name: "us-east-1", Zones: 6, Enabled: true, Tags: map[string]string{"tier": "1"}},
+		{Name: "no", Zones: 3},
+	}
+
+	app.AddCommand(NewCommand("regions", "test-group", "lists regions", func(c *Command) {}, func(c *Command) error {
+		return c.Emit(regions)
+	}))
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args: []string{"app", "regions"},
+			expected: "NAME       ZONES  ENABLED  TAGS\n" +
+				"us-east-1  6      true     {\"tier\":\"1\"}\n" +
+				"no         3      false    \n",
+		},
+		{
+			args: []string{"app", "regions", "-o", "yaml"},
+			expected: "- name: us-east-1\n" +
+				"  zones: 6\n" +
+				"  enabled: true\n" +
+				"  tags:\n" +
+				"    tier: \"1\"\n" +
+				"- name: \"no\"\n" +
+				"  zones: 3\n" +
+				"  enabled: false\n",
+		},
+		{
+			args:     []string{"app", "regions", "--output", "json"},
+			expected: "[\n  {\n    \"name\": \"us-east-1\",\n    \"zones\": 6,\n    \"enabled\": true,\n    \"tags\": {\n      \"tier\": \"1\"\n    }\n  },\n  {\n    \"name\": \"no\",\n    \"zones\": 3,\n    \"enabled\": false\n  }\n]\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		out.Reset()
+
+		if err := app.Run(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != tc.expected {
+			t.Fatalf("Unexpected output for test %d:\n%s", i, out.String())
+		}
+	}
+
+	err := app.Run([]string{"app", "regions", "-o", "xml"})
+	if _, ok := err.(*UsageErr); !ok {
+		t.Fatalf("Expected a usage error for an invalid format, got %v", err)
+	}
+}
+
+func TestYAMLNested(t *testing.T) {
+	n, err := toNode(map[string]interface{}{
+		"empty": []string{},
+		"list":  []interface{}{"a", []int{1, 2}},
+		"none":  nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	n.writeYAML(&out)
+
+	expected := "empty: []\n" +
+		"list:\n" +
+		"  - a\n" +
+		"  -\n" +
+		"    - 1\n" +
+		"    - 2\n" +
+		"none: null\n"
+
+	if out.String() != expected {
+		t.Fatalf("Unexpected YAML:\n%s", out.String())
+	}
+}
+
+func TestCmdEmitStream(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+	app.OutputFlag = true
+
+	app.AddCommand(NewCommand("watch", "test-group", "watches regions", func(c *Command) {}, func(c *Command) error {
+		ch := make(chan emitRegion)
+
+		go func() {
+			ch <- emitRegion{Name: "a", Zones: 1}
+			ch <- emitRegion{Name: "b", Zones: 2}
+			close(ch)
+		}()
+
+		return c.EmitStream(ch)
+	}))
+
+	if err := app.Run([]string{"app", "watch", "-o", "json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\"name\":\"a\",\"zones\":1,\"enabled\":false}\n{\"name\":\"b\",\"zones\":2,\"enabled\":false}\n"
+	if out.String() != expected {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"app", "watch", "-o", "table"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "NAME\tZONES\tENABLED\na\t1\tfalse\nb\t2\tfalse\n"
+	if out.String() != expected {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+
+	c := NewCommand("bad", "test-group", "bad", nil, nil)
+	if err := c.EmitStream([]int{1}); err == nil {
+		t.Fatal("Expected an error for a non-channel")
+	}
+}
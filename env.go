@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EnableEnv adds an env command listing every environment variable the
+// app's commands read, whether it is set, and which commands use it.
+// Values are never shown, and secret ones are reported as redacted.
+func (app *App) EnableEnv() {
+	app.AddCommand(NewCommand("env", "Shell", "List the environment variables commands read", func(cmd *Command) {}, func(cmd *Command) error {
+		users := map[string][]string{}
+		secret := map[string]bool{}
+
+		names := make([]string, 0, len(app.Commands))
+		for name := range app.Commands {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			c, _ := app.command(name)
+			if c.mount != nil || !app.listed(cmd.Context(), c) {
+				continue
+			}
+
+			_, release := hold(cmd.Context(), c)
+			app.prepare(c)
+
+			for n := range c.EnvArgs {
+				users[n] = append(users[n], name)
+				secret[n] = secret[n] || c.isSecret(n)
+			}
+
+			for n := range c.optionalEnv {
+				if _, ok := c.EnvArgs[n]; !ok {
+					users[n] = append(users[n], name+" "+app.tr("(optional)"))
+					secret[n] = secret[n] || c.isSecret(n)
+				}
+			}
+
+			release()
+		}
+
+		vars := make([]string, 0, len(users))
+		for n := range users {
+			vars = append(vars, n)
+		}
+
+		sort.Strings(vars)
+		width := nameColumn(vars)
+
+		for _, n := range vars {
+			status := "set"
+			switch {
+			case strings.TrimSpace(cmd.getenv(n)) == "":
+				status = "unset"
+			case secret[n]:
+				status = "redacted"
+			}
+
+			fmt.Fprintf(cmd.stdout(), "%s %s %s\n", padRight(n, width), padRight(app.tr(status), 8), strings.Join(users[n], ", "))
+		}
+
+		return nil
+	}))
+}
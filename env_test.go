@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAppEnv(t *testing.T) {
+	env := map[string]string{"AWS_PROFILE": "prod", "API_TOKEN": "hunter2"}
+	noop := func(c *Command) error { return nil }
+
+	app := NewApp()
+	app.Getenv = func(name string) string { return env[name] }
+	app.AddGroupEnvArg("aws", "AWS_PROFILE", "The AWS profile")
+	app.AddCommand(NewCommand("buckets", "aws", "lists buckets", func(c *Command) {}, noop))
+	app.AddCommand(NewCommand("deploy", "ops", "deploys", func(c *Command) {
+		c.AddSecretEnvArg("API_TOKEN", "The API token")
+		c.AddOptionalEnvArg("LOG_LEVEL", "How much to log")
+	}, noop))
+	app.AddCommand(NewCommand("sync", "aws", "syncs", func(c *Command) {
+		c.AddOptionalEnvArg("LOG_LEVEL", "How much to log")
+	}, noop))
+	app.EnableEnv()
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	if err := app.Run([]string{"app", "env"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "API_TOKEN          redacted deploy\n" +
+		"AWS_PROFILE        set      buckets, sync\n" +
+		"LOG_LEVEL          unset    deploy (optional), sync (optional)\n"
+
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	cmd, _ := app.command("deploy")
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	if !strings.Contains(out.String(), "Optional environment variables:\n    LOG_LEVEL: How much to log\n") {
+		t.Fatalf("Expected optional env args in usage:\n%s", out.String())
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExecEnv controls the environment a command runs in. The environment
+// settings apply to the command's own view of it, through EnvArg and
+// Environ, which child processes should be started with; the process
+// environment is left untouched.
+type ExecEnv struct {
+	// Umask is applied when ForceUmask is set. It is ignored on platforms
+	// without umask support. The umask is process-wide, so it is set for
+	// the duration of Run and runs that force it are serialized; it can't
+	// be relied on by other commands running concurrently.
+	Umask      os.FileMode
+	ForceUmask bool
+
+	// AllowEnv lists the environment variables kept for the run; all
+	// others are unset. Entries ending in "*" match by prefix. A nil list
+	// keeps the environment untouched. The command's EnvArgs are always
+	// kept.
+	AllowEnv []string
+
+	// Locale, when set, is forced through LC_ALL.
+	Locale string
+}
+
+// umaskMu serializes runs that force the umask.
+var umaskMu sync.Mutex
+
+func (ee *ExecEnv) allowed(name string, cmd *Command) bool {
+	if _, ok := cmd.EnvArgs[name]; ok {
+		return true
+	}
+
+	for _, a := range ee.AllowEnv {
+		if a == name || (strings.HasSuffix(a, "*") && strings.HasPrefix(name, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getenv returns the value ee forces for name, if any.
+func (ee *ExecEnv) getenv(name string, cmd *Command) (string, bool) {
+	if ee.Locale != "" && name == "LC_ALL" {
+		return ee.Locale, true
+	}
+
+	if ee.AllowEnv != nil && !ee.allowed(name, cmd) {
+		return "", true
+	}
+
+	return "", false
+}
+
+func (ee *ExecEnv) apply(cmd *Command) (restore func()) {
+	if !ee.ForceUmask {
+		return func() {}
+	}
+
+	umaskMu.Lock()
+	old := setUmask(int(ee.Umask))
+
+	return func() {
+		setUmask(old)
+		umaskMu.Unlock()
+	}
+}
+
+// Environ returns the environment, as "key=value" strings, that child
+// processes started by the command should run with: the non-empty
+// variables the command sees, including its profile's env and ExecEnv.
+func (cmd *Command) Environ() []string {
+	names := map[string]bool{}
+	for _, kv := range os.Environ() {
+		names[strings.SplitN(kv, "=", 2)[0]] = true
+	}
+
+	for n := range cmd.EnvArgs {
+		names[n] = true
+	}
+
+	for n := range cmd.envOverlay {
+		names[n] = true
+	}
+
+	if cmd.ExecEnv != nil && cmd.ExecEnv.Locale != "" {
+		names["LC_ALL"] = true
+	}
+
+	env := []string{}
+	for n := range names {
+		if v := cmd.getenv(n); v != "" {
+			env = append(env, n+"="+v)
+		}
+	}
+
+	sort.Strings(env)
+	return env
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCmdExecEnv(t *testing.T) {
+	os.Setenv("CMD_TEST_KEEP", "keep")
+	os.Setenv("CMD_TEST_PREFIX_A", "a")
+	os.Setenv("CMD_TEST_DROP", "drop")
+	os.Setenv("CMD_TEST_REQUIRED", "required")
+	defer os.Unsetenv("CMD_TEST_KEEP")
+	defer os.Unsetenv("CMD_TEST_PREFIX_A")
+	defer os.Unsetenv("CMD_TEST_DROP")
+	defer os.Unsetenv("CMD_TEST_REQUIRED")
+
+	seen := map[string]string{}
+	osDrop := ""
+
+	app := NewApp()
+
+	c := NewCommand("env", "test-group", "reads the env", func(c *Command) {
+		c.AddEnvArg("CMD_TEST_REQUIRED", "a required var")
+	}, func(c *Command) error {
+		for _, n := range []string{"CMD_TEST_KEEP", "CMD_TEST_PREFIX_A", "CMD_TEST_DROP", "CMD_TEST_REQUIRED", "LC_ALL"} {
+			seen[n] = c.EnvArg(n).String()
+		}
+
+		for _, kv := range c.Environ() {
+			if strings.HasPrefix(kv, "CMD_TEST_DROP=") {
+				t.Errorf("Expected %s to be left out of the child environment", kv)
+			}
+		}
+
+		osDrop = os.Getenv("CMD_TEST_DROP")
+		return nil
+	})
+	c.ExecEnv = &ExecEnv{
+		AllowEnv: []string{"CMD_TEST_KEEP", "CMD_TEST_PREFIX_*"},
+		Locale:   "C",
+	}
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "env"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"CMD_TEST_KEEP":     "keep",
+		"CMD_TEST_PREFIX_A": "a",
+		"CMD_TEST_DROP":     "",
+		"CMD_TEST_REQUIRED": "required",
+		"LC_ALL":            "C",
+	}
+
+	for n, v := range expected {
+		if seen[n] != v {
+			t.Fatalf("Expected %s=%q during run, got %q", n, v, seen[n])
+		}
+	}
+
+	if osDrop != "drop" {
+		t.Fatal("Expected the process environment to be left untouched")
+	}
+}
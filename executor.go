@@ -0,0 +1,31 @@
+package cmd
+
+import "context"
+
+// Executor runs a parsed command. An App can be given an Executor that,
+// for example, sends cmd.Invocation() to a container or a remote host and
+// copies the output back, keeping the same command line interface.
+type Executor interface {
+	// Execute runs cmd in ctx. The command's args and flags are parsed and
+	// its Stdin, Stdout and Stderr are set.
+	Execute(ctx context.Context, cmd *Command) error
+}
+
+// LocalExecutor runs commands in this process. It is the default.
+type LocalExecutor struct{}
+
+func (LocalExecutor) Execute(ctx context.Context, cmd *Command) error {
+	return cmd.app.execute(ctx, cmd)
+}
+
+// dispatch runs cmd with the App's Executor.
+func (app *App) dispatch(ctx context.Context, cmd *Command) error {
+	ex := app.Executor
+	if ex == nil {
+		ex = LocalExecutor{}
+	}
+
+	defer cmd.bindOutput()()
+
+	return ex.Execute(ctx, cmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testExecutor struct {
+	sent []string
+}
+
+func (te *testExecutor) Execute(ctx context.Context, cmd *Command) error {
+	b, err := json.Marshal(cmd.Invocation().Redacted())
+	if err != nil {
+		return err
+	}
+
+	te.sent = append(te.sent, string(b))
+	fmt.Fprintf(cmd.Stdout, "ran %s remotely\n", cmd.Name)
+
+	return nil
+}
+
+func TestExecutor(t *testing.T) {
+	var out strings.Builder
+	ranLocally := false
+
+	app := NewApp()
+	app.Stdout = &out
+	app.AddCommand(NewCommand("build", "test-group", "builds things", func(c *Command) {
+		c.AppendArg("target", "the target")
+	}, func(c *Command) error {
+		ranLocally = true
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "build", "web"}); err != nil || !ranLocally {
+		t.Fatalf("Expected a local run, got %v", err)
+	}
+
+	ranLocally = false
+	ex := &testExecutor{}
+	app.Executor = ex
+
+	if err := app.Run([]string{"app", "build", "api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ranLocally || out.String() != "ran build remotely\n" || len(ex.sent) != 1 || !strings.Contains(ex.sent[0], `"target":{"value":"api"`) {
+		t.Fatalf("Expected the executor to run build, got %q and %v", out.String(), ex.sent)
+	}
+}
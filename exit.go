@@ -0,0 +1,32 @@
+package cmd
+
+import "errors"
+
+// ExitCoder is implemented by errors that map to a specific process exit
+// code.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitCode returns the process exit code for an error returned by App.Run:
+// 0 for nil, the code of the first ExitCoder in the chain, and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+
+	return 1
+}
+
+func (ue *UsageErr) ExitCode() int {
+	return 2
+}
+
+func (ie *InterruptErr) ExitCode() int {
+	return 130
+}
@@ -0,0 +1,64 @@
+package cmd
+
+// AddCommandFactory registers a command whose construction is deferred until
+// it is run or inspected, so large apps only pay for the Setup of the command
+// actually invoked. Help listings and search use name, group and desc.
+func (app *App) AddCommandFactory(name, group, desc string, factory func() *Command) {
+	app.checkFrozen("AddCommandFactory")
+
+	cmd := NewCommand(name, group, desc, func(*Command) {}, nil)
+	cmd.factory = factory
+
+	app.Commands[name] = cmd
+	cmd.app = app
+}
+
+// command returns the command called name, building it on first use if it
+// was added with AddCommandFactory.
+func (app *App) command(name string) (*Command, bool) {
+	cmd, ok := app.Commands[name]
+	if !ok || cmd.factory == nil {
+		return cmd, ok
+	}
+
+	cmd.once.Do(func() {
+		cmd.built = app.build(cmd)
+	})
+
+	return cmd.built, true
+}
+
+// build makes the command for the factory placeholder cmd, keeping the
+// listing metadata given when it was added.
+func (app *App) build(cmd *Command) *Command {
+	name := cmd.Name
+	built := cmd.factory()
+	built.Name = name
+
+	if built.Group == "" {
+		built.Group = cmd.Group
+	}
+
+	if built.Description == "" {
+		built.Description = cmd.Description
+	}
+
+	if built.Stability == StabilityStable {
+		built.Stability = cmd.Stability
+	}
+
+	for k, v := range cmd.Annotations {
+		if _, ok := built.Annotations[k]; !ok {
+			if built.Annotations == nil {
+				built.Annotations = map[string]string{}
+			}
+
+			built.Annotations[k] = v
+		}
+	}
+
+	built.app = app
+	built.Setup(built)
+
+	return built
+}
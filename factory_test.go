@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddCommandFactory(t *testing.T) {
+	var out strings.Builder
+	built := []string{}
+
+	app := NewApp()
+	app.Stdout = &out
+
+	for _, name := range []string{"alpha", "beta"} {
+		name := name
+		app.AddCommandFactory(name, "test-group", "the "+name+" command", func() *Command {
+			built = append(built, name)
+
+			return NewCommand(name, "", "", func(c *Command) {
+				c.AppendArg("who", "who to greet")
+			}, func(c *Command) error {
+				out.WriteString(name + " greets " + c.Arg("who").String())
+				return nil
+			})
+		})
+	}
+
+	if err := app.Run([]string{"app", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(built) != 0 || !strings.Contains(out.String(), "the beta command") {
+		t.Fatalf("Expected the listing without building commands, built %v:\n%s", built, out.String())
+	}
+
+	out.Reset()
+
+	app.Commands["beta"].Annotations = map[string]string{"stability": "beta"}
+
+	for i := 0; i < 2; i++ {
+		if err := app.Run([]string{"app", "beta", "you"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(built) != 1 || built[0] != "beta" || out.String() != "beta greets youbeta greets you" {
+		t.Fatalf("Expected beta to be built once, built %v with %q", built, out.String())
+	}
+
+	if cmd := app.Commands["beta"]; cmd.Group != "test-group" || cmd.Description != "the beta command" || cmd.Annotations["stability"] != "beta" {
+		t.Fatalf("Expected listing metadata to be kept, got %q, %q and %v", cmd.Group, cmd.Description, cmd.Annotations)
+	}
+}
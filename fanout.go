@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ItemErr is the failure of one item of a batch.
+type ItemErr struct {
+	Item string
+	Err  error
+}
+
+func (ie *ItemErr) Error() string {
+	return fmt.Sprintf("%s: %v", ie.Item, ie.Err)
+}
+
+func (ie *ItemErr) Unwrap() error {
+	return ie.Err
+}
+
+// MultiErr collects the failed items of a batch run with --keep-going.
+type MultiErr struct {
+	Errors []*ItemErr
+	Total  int
+}
+
+func (me *MultiErr) Error() string {
+	msgs := make([]string, len(me.Errors))
+	for i, e := range me.Errors {
+		msgs[i] = "    " + e.Error()
+	}
+
+	return fmt.Sprintf("%d of %d items failed:\n%s", len(me.Errors), me.Total, strings.Join(msgs, "\n"))
+}
+
+func (me *MultiErr) Unwrap() []error {
+	errs := make([]error, len(me.Errors))
+	for i, e := range me.Errors {
+		errs[i] = e
+	}
+
+	return errs
+}
+
+// KeepGoing reports whether --keep-going was given.
+func (cmd *Command) KeepGoing() bool {
+	return cmd.keepGoingFlag != nil && *cmd.keepGoingFlag
+}
+
+// EachVarArg calls fn for each var arg in order. By default it stops at the
+// first error; with --keep-going every item is processed and the failures
+// are returned together as a *MultiErr.
+func (cmd *Command) EachVarArg(fn func(Value) error) error {
+	items := cmd.VarArgs()
+	me := &MultiErr{Total: len(items)}
+
+	for _, v := range items {
+		if err := cmd.Context().Err(); err != nil {
+			return err
+		}
+
+		if err := fn(v); err != nil {
+			ie := &ItemErr{Item: v.String(), Err: err}
+
+			if !cmd.KeepGoing() {
+				return ie
+			}
+
+			fmt.Fprintf(cmd.stderr(), "%s: %v\n", cmd.Name, ie)
+			me.Errors = append(me.Errors, ie)
+		}
+	}
+
+	if len(me.Errors) > 0 {
+		return me
+	}
+
+	return nil
+}
+
+// ForEachVarArg is like EachVarArg but calls fn for up to parallelism var
+// args at once. Without --keep-going no new items are started after the
+// first failure, which is returned; with it the failures are returned as a
+// *MultiErr in argument order. Canceling the command's context stops
+// starting new items. On a terminal a progress bar tracks completed items.
+func (cmd *Command) ForEachVarArg(parallelism int, fn func(Value) error) error {
+	items := cmd.VarArgs()
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var progress *Progress
+	if len(items) > 1 && cmd.interactiveWriter(cmd.stderr()) {
+		progress = cmd.Progress(len(items))
+		defer progress.Done()
+	}
+
+	errs := make([]*ItemErr, len(items))
+
+	var mu sync.Mutex
+	var first *ItemErr
+	stop := false
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, v := range items {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stopped := stop
+		mu.Unlock()
+
+		if stopped {
+			<-sem
+			break
+		}
+
+		if err := cmd.Context().Err(); err != nil {
+			<-sem
+			wg.Wait()
+			return err
+		}
+
+		wg.Add(1)
+		go func(i int, v Value) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := fn(v)
+
+			if progress != nil {
+				progress.Add(1)
+			}
+
+			if err == nil {
+				return
+			}
+
+			ie := &ItemErr{Item: v.String(), Err: err}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			errs[i] = ie
+
+			if !cmd.KeepGoing() {
+				if first == nil {
+					first = ie
+				}
+				stop = true
+				return
+			}
+
+			fmt.Fprintf(cmd.stderr(), "%s: %v\n", cmd.Name, ie)
+		}(i, v)
+	}
+
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+
+	me := &MultiErr{Total: len(items)}
+	for _, ie := range errs {
+		if ie != nil {
+			me.Errors = append(me.Errors, ie)
+		}
+	}
+
+	if len(me.Errors) > 0 {
+		return me
+	}
+
+	return nil
+}
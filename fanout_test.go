@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errOdd = errors.New("odd")
+
+func TestCmdEachVarArg(t *testing.T) {
+	var processed []string
+
+	app := NewApp()
+	app.Stderr = io.Discard
+
+	c := NewCommand("process", "test-group", "processes items", func(c *Command) {
+		c.AppendVarArg("items", "the items")
+	}, func(c *Command) error {
+		processed = nil
+
+		return c.EachVarArg(func(v Value) error {
+			processed = append(processed, v.String())
+
+			if n, _ := v.Int(); n%2 == 1 {
+				return errOdd
+			}
+
+			return nil
+		})
+	})
+	c.SoftFail = true
+	app.AddCommand(c)
+
+	err := app.Run([]string{"app", "process", "2", "3", "4", "5"})
+
+	var ie *ItemErr
+	if !errors.As(err, &ie) || ie.Item != "3" {
+		t.Fatalf("Expected item 3 to fail, got %v", err)
+	}
+
+	if len(processed) != 2 {
+		t.Fatalf("Expected to stop at the first failure, processed %v", processed)
+	}
+
+	err = app.Run([]string{"app", "process", "--keep-going", "2", "3", "4", "5"})
+
+	me, ok := err.(*MultiErr)
+	if !ok {
+		t.Fatalf("Expected a multi error, got %v", err)
+	}
+
+	if len(processed) != 4 || len(me.Errors) != 2 || me.Total != 4 {
+		t.Fatalf("Unexpected result: processed %v, errors %v", processed, me)
+	}
+
+	if !errors.Is(err, errOdd) {
+		t.Fatal("Expected the multi error to wrap the item errors")
+	}
+
+	if ExitCode(err) == 0 {
+		t.Fatal("Expected a non-zero exit code")
+	}
+}
+
+func TestCmdForEachVarArg(t *testing.T) {
+	var mu sync.Mutex
+	var processed, running, maxRunning int
+
+	app := NewApp()
+	app.Stderr = io.Discard
+
+	c := NewCommand("process", "test-group", "processes items", func(c *Command) {
+		c.AppendVarArg("items", "the items")
+	}, func(c *Command) error {
+		processed, maxRunning = 0, 0
+
+		return c.ForEachVarArg(3, func(v Value) error {
+			mu.Lock()
+			processed++
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			if n, _ := v.Int(); n%2 == 1 {
+				return errOdd
+			}
+
+			return nil
+		})
+	})
+	c.SoftFail = true
+	app.AddCommand(c)
+
+	items := []string{}
+	for i := 0; i < 20; i++ {
+		items = append(items, strconv.Itoa(i*2))
+	}
+
+	if err := app.Run(append([]string{"app", "process"}, items...)); err != nil {
+		t.Fatal(err)
+	}
+
+	if processed != 20 || maxRunning != 3 {
+		t.Fatalf("Expected 20 items 3 at a time, got %d with %d at once", processed, maxRunning)
+	}
+
+	items[5], items[12] = "5", "13"
+
+	err := app.Run(append([]string{"app", "process", "--keep-going"}, items...))
+
+	var me *MultiErr
+	if !errors.As(err, &me) || len(me.Errors) != 2 || me.Errors[0].Item != "5" || me.Errors[1].Item != "13" {
+		t.Fatalf("Expected failures for 5 and 13 in order, got %v", err)
+	}
+
+	items[1] = "1"
+
+	err = app.Run(append([]string{"app", "process", "--keep-going=false"}, items...))
+
+	var ie *ItemErr
+	if !errors.As(err, &ie) || !errors.Is(err, errOdd) || processed == 20 {
+		t.Fatalf("Expected to stop early with an item error, got %v after %d items", err, processed)
+	}
+}
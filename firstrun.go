@@ -0,0 +1,50 @@
+package cmd
+
+import "os"
+
+const firstRunKey = "first-run/done"
+
+// OnFirstRun sets fn to be called before the first command run on this
+// machine, detected by the app having neither a data dir nor a config file.
+// It can create a config, ask for consent or print a welcome. Completion is
+// recorded in the data dir; if fn fails the command is not run and fn is
+// tried again next time.
+func (app *App) OnFirstRun(fn func(app *App) error) {
+	app.checkFrozen("OnFirstRun")
+
+	app.firstRun = fn
+}
+
+func (app *App) runFirstRun() error {
+	if app.firstRun == nil || app.firstRunDone {
+		return nil
+	}
+
+	store := stateStoreFor(app.dataDir())
+
+	done := false
+	if _, err := store.get(firstRunKey, &done); err != nil {
+		return err
+	}
+
+	if !done && !exists(app.dataDir()) && !exists(app.configPath()) {
+		if err := app.firstRun(app); err != nil {
+			return err
+		}
+	}
+
+	if !done {
+		if err := store.set(firstRunKey, true); err != nil {
+			return err
+		}
+	}
+
+	app.firstRunDone = true
+
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
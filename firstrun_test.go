@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestOnFirstRun(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+
+	newApp := func() *App {
+		app := NewApp()
+		app.DataDir = filepath.Join(dir, "data")
+		app.ConfigFile = filepath.Join(dir, "config.yaml")
+		app.OnFirstRun(func(app *App) error {
+			calls++
+			if calls == 1 {
+				return errors.New("declined")
+			}
+			return nil
+		})
+
+		app.AddCommand(NewCommand("work", "test-group", "works", func(c *Command) {}, func(c *Command) error {
+			return nil
+		}))
+
+		return app
+	}
+
+	if err := newApp().Run([]string{"app", "work"}); err == nil {
+		t.Fatal("Expected the first run error")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := newApp().Run([]string{"app", "work"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected setup to run until it succeeds, got %d calls", calls)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import "flag"
+
+// SetMetavar names the value of the flag called name in usage, so it is
+// listed as "region <REGION>" rather than just "region".
+func (cmd *Command) SetMetavar(name, metavar string) {
+	if cmd.metavars == nil {
+		cmd.metavars = map[string]string{}
+	}
+
+	cmd.metavars[name] = metavar
+}
+
+// SetFlagGroup lists the named flags under their own heading, like
+// "Output options", in usage. Groups are shown after the other flags, in
+// the order they were first used.
+func (cmd *Command) SetFlagGroup(group string, names ...string) {
+	if cmd.flagGroups == nil {
+		cmd.flagGroups = map[string]string{}
+	}
+
+	for _, n := range names {
+		cmd.flagGroups[n] = group
+	}
+
+	for _, g := range cmd.flagGroupOrder {
+		if g == group {
+			return
+		}
+	}
+
+	cmd.flagGroupOrder = append(cmd.flagGroupOrder, group)
+}
+
+// flagLabel is how f is named in the flag list, with its metavar or the
+// "[no-]" prefix of a negatable flag.
+func (cmd *Command) flagLabel(f *flag.Flag) string {
+	label := f.Name
+	if cmd.negatable("no-"+f.Name) != nil {
+		label = "[no-]" + label
+	}
+
+	if m := cmd.metavars[f.Name]; m != "" {
+		label += " <" + m + ">"
+	}
+
+	return label
+}
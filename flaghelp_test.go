@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCmdMetavar(t *testing.T) {
+	cmd := NewCommand("deploy", "test-group", "deploys", nil, nil)
+	cmd.Flags.String("region", "us", "Where to deploy")
+	cmd.Flags.Bool("force", false, "Skip checks")
+	cmd.SetMetavar("region", "REGION")
+
+	var out strings.Builder
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	for _, line := range []string{"    region <REGION>: Where to deploy\n", "    force: Skip checks\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Fatalf("Expected %q in usage:\n%s", line, out.String())
+		}
+	}
+
+	if m := cmd.Describe().Flags[1].Metavar; m != "REGION" {
+		t.Fatalf("Expected REGION, got %q", m)
+	}
+}
+
+func TestCmdFlagGroups(t *testing.T) {
+	cmd := NewCommand("query", "test-group", "queries", nil, nil)
+	cmd.Flags.String("host", "", "Server host")
+	cmd.Flags.String("format", "", "Output format")
+	cmd.Flags.Bool("wide", false, "Show all columns")
+	cmd.Flags.Bool("force", false, "Skip checks")
+	cmd.SetFlagGroup("Output options", "format", "wide")
+	cmd.SetFlagGroup("Connection options", "host")
+
+	var out strings.Builder
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	expected := "Flags:\n    force: Skip checks\n\n" +
+		"Output options:\n    format: Output format\n    wide: Show all columns\n\n" +
+		"Connection options:\n    host: Server host\n\n"
+
+	if !strings.Contains(out.String(), expected) {
+		t.Fatalf("Expected grouped flags in usage:\n%s", out.String())
+	}
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	followMinBackoff = time.Second
+	followMaxBackoff = 30 * time.Second
+)
+
+// FollowSource opens a stream of lines starting at since. Follow calls it
+// again to reconnect whenever the stream ends or fails.
+type FollowSource func(ctx context.Context, since time.Time) (io.ReadCloser, error)
+
+type followFlags struct {
+	since string
+	grep  string
+	json  bool
+}
+
+// AddFollowFlags registers the --since, --grep and --json flags that Follow
+// and FollowSource use to filter and format lines.
+func (cmd *Command) AddFollowFlags() {
+	cmd.follow = &followFlags{}
+
+	cmd.Flags.StringVar(&cmd.follow.since, "since", "", "Only show lines newer than a duration (e.g. 10m) or RFC3339 time")
+	cmd.Flags.StringVar(&cmd.follow.grep, "grep", "", "Only show lines matching this regular expression")
+	cmd.Flags.BoolVar(&cmd.follow.json, "json", false, "Print each line as a JSON object")
+}
+
+type lineFilter struct {
+	since time.Time
+	grep  *regexp.Regexp
+	json  bool
+}
+
+func (cmd *Command) lineFilter() (*lineFilter, error) {
+	lf := &lineFilter{}
+
+	if cmd.follow == nil {
+		return lf, nil
+	}
+
+	lf.json = cmd.follow.json
+
+	if s := cmd.follow.since; s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			lf.since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			lf.since = t
+		} else {
+			return nil, cmd.usageErr(fmt.Sprintf("Invalid --since value %q", s), err)
+		}
+	}
+
+	if g := cmd.follow.grep; g != "" {
+		re, err := regexp.Compile(g)
+		if err != nil {
+			return nil, cmd.usageErr(fmt.Sprintf("Invalid --grep pattern %q", g), err)
+		}
+
+		lf.grep = re
+	}
+
+	return lf, nil
+}
+
+// lineTime returns the timestamp a line starts with, if any.
+func lineTime(line string) (time.Time, bool) {
+	field := line
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		field = line[:i]
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, field)
+	return t, err == nil
+}
+
+// format returns the line to print, or false when it is filtered out.
+func (lf *lineFilter) format(line string) (string, bool) {
+	if lf.grep != nil && !lf.grep.MatchString(line) {
+		return "", false
+	}
+
+	t, hasTime := lineTime(line)
+	if !lf.since.IsZero() && hasTime && t.Before(lf.since) {
+		return "", false
+	}
+
+	if !lf.json {
+		return line, true
+	}
+
+	if trimmed := strings.TrimSpace(line); json.Valid([]byte(trimmed)) && strings.HasPrefix(trimmed, "{") {
+		var buf bytes.Buffer
+		json.Compact(&buf, []byte(trimmed))
+		return buf.String(), true
+	}
+
+	rec := struct {
+		Time *time.Time `json:"time,omitempty"`
+		Line string     `json:"line"`
+	}{Line: line}
+
+	if hasTime {
+		rec.Time = &t
+	}
+
+	b, _ := json.Marshal(rec)
+	return string(b), true
+}
+
+// followCursor tracks how far a stream has been copied, so a reconnect that
+// resumes from the last timestamp doesn't print that timestamp's lines again.
+type followCursor struct {
+	last   time.Time
+	atLast map[string]int
+
+	// replay holds the lines at last still expected again after a
+	// reconnect
+	replay map[string]int
+}
+
+// resume prepares for a stream reopened from fc.last.
+func (fc *followCursor) resume() {
+	fc.replay = map[string]int{}
+	for line, n := range fc.atLast {
+		fc.replay[line] = n
+	}
+}
+
+// seen records a line stamped t, reporting false for one already copied.
+func (fc *followCursor) seen(line string, t time.Time) bool {
+	if fc.replay != nil {
+		if t.Equal(fc.last) && fc.replay[line] > 0 {
+			fc.replay[line]--
+			return false
+		}
+
+		if !t.Before(fc.last) {
+			fc.replay = nil
+		}
+	}
+
+	if t.After(fc.last) {
+		fc.last = t
+		fc.atLast = map[string]int{}
+	}
+
+	if t.Equal(fc.last) {
+		fc.atLast[line]++
+	}
+
+	return true
+}
+
+// copyLines copies filtered lines from r to the command's stdout until r is
+// exhausted, tracking timestamped lines in fc.
+func (cmd *Command) copyLines(r io.Reader, lf *lineFilter, fc *followCursor) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for sc.Scan() {
+		line := sc.Text()
+
+		if t, ok := lineTime(line); ok && !fc.seen(line, t) {
+			continue
+		}
+
+		if out, ok := lf.format(line); ok {
+			fmt.Fprintln(cmd.stdout(), out)
+		}
+	}
+
+	return sc.Err()
+}
+
+// Follow prints the lines read from r, filtered by the follow flags, until r
+// ends or the run is interrupted.
+func (cmd *Command) Follow(r io.Reader) error {
+	lf, err := cmd.lineFilter()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	if c, ok := r.(io.Closer); ok {
+		stop := context.AfterFunc(ctx, func() {
+			c.Close()
+		})
+		defer stop()
+	}
+
+	err = cmd.copyLines(r, lf, &followCursor{})
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+// FollowSource is like Follow but reopens the stream with increasing
+// backoff whenever it ends or fails, resuming from the last timestamp seen
+// without repeating the lines already printed with it.
+// It returns when the run is interrupted.
+func (cmd *Command) FollowSource(open FollowSource) error {
+	lf, err := cmd.lineFilter()
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	fc := &followCursor{}
+	backoff := followMinBackoff
+
+	for {
+		since := lf.since
+		if !fc.last.IsZero() {
+			since = fc.last
+			fc.resume()
+		}
+
+		rc, err := open(ctx, since)
+		if err == nil {
+			stop := context.AfterFunc(ctx, func() {
+				rc.Close()
+			})
+
+			last := fc.last
+			err = cmd.copyLines(rc, lf, fc)
+
+			stop()
+			rc.Close()
+
+			if fc.last.After(last) {
+				backoff = followMinBackoff
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			fmt.Fprintln(cmd.stderr(), cmd.app.trf("%s: stream failed: %v; reconnecting in %s", cmd.Name, err, backoff))
+		} else {
+			fmt.Fprintln(cmd.stderr(), cmd.app.trf("%s: stream ended; reconnecting in %s", cmd.Name, backoff))
+		}
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > followMaxBackoff {
+			backoff = followMaxBackoff
+		}
+	}
+}
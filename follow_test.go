@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCmdFollowFilters(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+
+	input := strings.Join([]string{
+		"2020-01-01T00:00:00Z old error",
+		time.Now().Format(time.RFC3339) + " new error",
+		time.Now().Format(time.RFC3339) + " new info",
+		"untimed error",
+	}, "\n")
+
+	app.AddCommand(NewCommand("logs", "test-group", "shows logs", func(c *Command) {
+		c.AddFollowFlags()
+	}, func(c *Command) error {
+		return c.Follow(strings.NewReader(input))
+	}))
+
+	if err := app.Run([]string{"app", "logs", "--since", "1h", "--grep", "error"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "new error") || lines[1] != "untimed error" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"app", "logs", "--since", "0s", "--grep", "untimed", "--json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "{\"line\":\"untimed error\"}\n" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+
+	err := app.Run([]string{"app", "logs", "--grep", "("})
+	if _, ok := err.(*UsageErr); !ok {
+		t.Fatalf("Expected a usage error for a bad pattern, got %v", err)
+	}
+}
+
+func TestCmdFollowSourceReconnects(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Stderr = io.Discard
+
+	opens := 0
+
+	app.AddCommand(NewCommand("logs", "test-group", "shows logs", func(c *Command) {
+		c.Timeout = 3 * time.Second
+	}, func(c *Command) error {
+		ctx, cancel := context.WithCancel(c.Context())
+		c.ctx = ctx
+
+		return c.FollowSource(func(ctx context.Context, since time.Time) (io.ReadCloser, error) {
+			opens++
+
+			switch opens {
+			case 1:
+				return nil, errors.New("connection refused")
+			case 2:
+				cancel()
+			}
+
+			return io.NopCloser(strings.NewReader("line\n")), nil
+		})
+	}))
+
+	if err := app.Run([]string{"app", "logs"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if opens != 2 {
+		t.Fatalf("Expected 2 opens, got %d", opens)
+	}
+}
+
+func TestCmdFollowSourceResumes(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Stderr = io.Discard
+
+	const t1, t2, t3 = "2024-01-01T00:00:01Z", "2024-01-01T00:00:02Z", "2024-01-01T00:00:03Z"
+	var sinces []string
+
+	app.AddCommand(NewCommand("logs", "test-group", "shows logs", func(c *Command) {
+		c.Timeout = 3 * time.Second
+	}, func(c *Command) error {
+		ctx, cancel := context.WithCancel(c.Context())
+		c.ctx = ctx
+
+		return c.FollowSource(func(ctx context.Context, since time.Time) (io.ReadCloser, error) {
+			sinces = append(sinces, since.Format(time.RFC3339))
+
+			if len(sinces) == 1 {
+				return io.NopCloser(strings.NewReader(t1 + " a\n" + t2 + " b\n" + t2 + " c\n")), nil
+			}
+
+			// The source resumes inclusively from since
+			cancel()
+			return io.NopCloser(strings.NewReader(t2 + " b\n" + t2 + " c\n" + t2 + " c\n" + t3 + " d\n")), nil
+		})
+	}))
+
+	if err := app.Run([]string{"app", "logs"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := t1 + " a\n" + t2 + " b\n" + t2 + " c\n" + t2 + " c\n" + t3 + " d\n"
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	if len(sinces) != 2 || sinces[1] != t2 {
+		t.Fatalf("Expected to resume from %s, got %q", t2, sinces)
+	}
+}
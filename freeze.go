@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Freeze ends registration: adding commands, redirects, checks or mounts
+// afterwards panics. Once frozen an App can be run from several goroutines
+// at a time. Runs of the same command are serialized.
+func (app *App) Freeze() {
+	app.frozen = true
+
+	for _, cmd := range app.Commands {
+		if cmd.mount != nil {
+			cmd.mount.Freeze()
+		}
+	}
+}
+
+func (app *App) checkFrozen(what string) {
+	if app.frozen {
+		panic(fmt.Sprintf("cmd: %s called on a frozen App", what))
+	}
+}
+
+// heldKey holds the commands locked by a run and the runs it started.
+type heldKey struct{}
+
+type heldCommand struct {
+	cmd  *Command
+	next *heldCommand
+}
+
+// hold locks cmd for the run in ctx, unless a run ctx descends from already
+// holds it, as when a shell line or script step runs a command while the
+// shell or script is running. It returns the context to run cmd in and a
+// func releasing the lock.
+func hold(ctx context.Context, cmd *Command) (context.Context, func()) {
+	held, _ := ctx.Value(heldKey{}).(*heldCommand)
+
+	for h := held; h != nil; h = h.next {
+		if h.cmd == cmd {
+			return ctx, func() {}
+		}
+	}
+
+	cmd.runMu.Lock()
+
+	return context.WithValue(ctx, heldKey{}, &heldCommand{cmd, held}), cmd.runMu.Unlock
+}
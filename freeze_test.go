@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	var mu sync.Mutex
+	results := map[string]int{}
+
+	app := NewApp()
+	app.Stdout = io.Discard
+	app.TimingsFlag = true
+
+	for _, name := range []string{"add", "mul"} {
+		name := name
+		app.AddCommand(NewCommand(name, "test-group", name+" two numbers", func(c *Command) {
+			c.AppendArg("a", "a number")
+			c.AppendArg("b", "a number")
+		}, func(c *Command) error {
+			a, _ := c.Arg("a").Int()
+			b, _ := c.Arg("b").Int()
+
+			r := a + b
+			if name == "mul" {
+				r = a * b
+			}
+
+			mu.Lock()
+			results[fmt.Sprintf("%s %d %d", name, a, b)] = r
+			mu.Unlock()
+
+			return nil
+		}))
+	}
+
+	app.AddCommandFactory("lazy", "test-group", "built on first use", func() *Command {
+		return NewCommand("lazy", "", "", func(*Command) {}, func(*Command) error { return nil })
+	})
+
+	app.Freeze()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for _, args := range [][]string{{"add", fmt.Sprint(i), "1"}, {"mul", fmt.Sprint(i), "2"}, {"lazy"}, {"--help"}} {
+				if err := app.Run(append([]string{"app"}, args...)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		if results[fmt.Sprintf("add %d 1", i)] != i+1 || results[fmt.Sprintf("mul %d 2", i)] != i*2 {
+			t.Fatalf("Unexpected results for %d: %v", i, results)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected AddCommand on a frozen App to panic")
+		}
+	}()
+
+	app.AddCommand(NewCommand("late", "test-group", "too late", func(*Command) {}, nil))
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// globFiles returns the files matching pattern, which is a filepath.Match
+// pattern where a "**" path element matches any number of directories.
+func globFiles(pattern string) ([]string, error) {
+	pattern = filepath.Clean(pattern)
+
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+
+	root := globRoot(pattern)
+	matches := []string{}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+
+		if matchGlob(pattern, path) {
+			matches = append(matches, path)
+		}
+
+		return nil
+	})
+
+	sort.Strings(matches)
+
+	return matches, err
+}
+
+// globRoot returns the directory part of pattern before its first wildcard.
+func globRoot(pattern string) string {
+	elems := strings.Split(pattern, string(filepath.Separator))
+
+	for i, e := range elems {
+		if strings.ContainsAny(e, "*?[") {
+			root := strings.Join(elems[:i], string(filepath.Separator))
+			if root == "" && filepath.IsAbs(pattern) {
+				return string(filepath.Separator)
+			} else if root == "" {
+				return "."
+			}
+
+			return root
+		}
+	}
+
+	return pattern
+}
+
+// matchGlob reports whether path matches pattern element by element, with
+// "**" matching zero or more elements.
+func matchGlob(pattern, path string) bool {
+	return matchElems(strings.Split(pattern, string(filepath.Separator)), strings.Split(filepath.Clean(path), string(filepath.Separator)))
+}
+
+func matchElems(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchElems(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+
+		if ok, err := filepath.Match(pattern[0], path[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern, path = pattern[1:], path[1:]
+	}
+
+	return len(path) == 0
+}
+
+// expandGlobs replaces the values of a Glob arg in fs with the files they
+// match.
+func (cmd *Command) expandGlobs(fs *flag.FlagSet) error {
+	i := len(cmd.Args) - 1
+	if i < 0 || !cmd.Args[i].Glob || fs.NArg() <= i {
+		return nil
+	}
+
+	args := append([]string{}, fs.Args()[:i]...)
+	seen := map[string]bool{}
+	dir := cmd.Dir()
+
+	for _, v := range fs.Args()[i:] {
+		matches := []string{v}
+
+		if strings.ContainsAny(v, "*?[") {
+			var err error
+			if matches, err = globFiles(inDir(dir, v)); err != nil {
+				return cmd.usageErr(fmt.Sprintf("%s: %v", v, err), ErrInvalidValue)
+			} else if len(matches) == 0 {
+				return cmd.usageErr(fmt.Sprintf("%s: no files match", v), ErrInvalidValue)
+			}
+		}
+
+		for _, m := range matches {
+			// Matches are given as the pattern would be, relative to dir
+			if !filepath.IsAbs(v) {
+				if rel, err := filepath.Rel(dir, m); err == nil {
+					m = rel
+				}
+			}
+
+			if !seen[m] {
+				seen[m] = true
+				args = append(args, m)
+			}
+		}
+	}
+
+	// Parsing just the args after "--" replaces them without touching
+	// the flags
+	return fs.Parse(append([]string{"--"}, args...))
+}
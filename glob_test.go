@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGlobFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, f := range []string{"a.go", "b.txt", "sub/c.go", "sub/deep/d.go", "sub/deep/e.txt"} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		pattern string
+		matches []string
+	}{
+		{"*.go", []string{"a.go"}},
+		{"**/*.go", []string{"a.go", "sub/c.go", "sub/deep/d.go"}},
+		{"sub/**/*.txt", []string{"sub/deep/e.txt"}},
+		{"sub/*/*", []string{"sub/deep/d.go", "sub/deep/e.txt"}},
+		{"nope/**/*.go", []string{}},
+	}
+
+	for _, tc := range testCases {
+		matches, err := globFiles(filepath.Join(dir, tc.pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rel := []string{}
+		for _, m := range matches {
+			r, _ := filepath.Rel(dir, m)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+
+		if !reflect.DeepEqual(rel, tc.matches) {
+			t.Fatalf("Expected %v for %s, got %v", tc.matches, tc.pattern, rel)
+		}
+	}
+}
+
+func TestGlobVarArg(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, f := range []string{"b.log", "a.log", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	var follow bool
+
+	app := NewApp()
+	app.AddCommand(NewCommand("tail", "test-group", "tails logs", func(c *Command) {
+		c.Flags.Bool("follow", false, "keep reading")
+		c.AppendArg("host", "the host")
+		c.AppendGlobVarArg("files", "the files")
+	}, func(c *Command) error {
+		got = c.Flags.Args()
+		follow, _ = c.Flag("follow").Bool()
+		return nil
+	}))
+
+	p := func(f string) string { return filepath.Join(dir, f) }
+
+	if err := app.Run([]string{"app", "tail", "--follow", "web", p("*.log"), p("a.log"), p("c.txt")}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"web", p("a.log"), p("b.log"), p("c.txt")}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+
+	if !follow {
+		t.Fatal("Expected --follow to keep its value")
+	}
+
+	err := app.Run([]string{"app", "tail", "web", p("*.gz")})
+	if !errors.Is(err, ErrInvalidValue) || err.Error() != p("*.gz")+": no files match" {
+		t.Fatalf("Expected a no match error, got %v", err)
+	}
+}
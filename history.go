@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	historyMax      = 1000
+	historyRedacted = "***"
+)
+
+// HistoryEntry is one recorded invocation.
+type HistoryEntry struct {
+	Time     time.Time     `json:"time"`
+	Command  string        `json:"command"`
+	Args     []string      `json:"args"`
+	Redacted bool          `json:"redacted,omitempty"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+}
+
+// EnableHistory records every command invocation in the app's data dir and
+// registers a history command to list and re-run them. Values of secret
+// flags and args are redacted.
+func (app *App) EnableHistory() {
+	app.history = true
+
+	app.AddCommand(NewCommand("history", "Shell", "List or re-run previous commands",
+		func(cmd *Command) {
+			cmd.Flags.Int("limit", 20, "Number of entries to list")
+			cmd.Flags.Int("run", 0, "Re-run the entry with this number")
+		},
+		func(cmd *Command) error {
+			entries, err := app.loadHistory()
+			if err != nil {
+				return err
+			}
+
+			if n, _ := cmd.Flag("run").Int(); n != 0 {
+				if n < 1 || n > len(entries) {
+					return fmt.Errorf("no history entry %d", n)
+				}
+
+				e := entries[n-1]
+				if e.Redacted {
+					return fmt.Errorf("history entry %d has redacted values and cannot be re-run", n)
+				}
+
+				return app.RunContext(cmd.Context(), append([]string{app.name(), e.Command}, e.Args...))
+			}
+
+			limit, _ := cmd.Flag("limit").Int()
+
+			start := 0
+			if limit > 0 && len(entries) > limit {
+				start = len(entries) - limit
+			}
+
+			for i, e := range entries[start:] {
+				fmt.Fprintf(cmd.Stdout, "%4d  %s  %3d  %8s  %s\n", start+i+1, e.Time.Local().Format("2006-01-02 15:04:05"),
+					e.ExitCode, e.Duration.Round(time.Millisecond), strings.Join(append([]string{e.Command}, e.Args...), " "))
+			}
+
+			return nil
+		}))
+}
+
+func (app *App) historyPath() string {
+	return filepath.Join(app.dataDir(), "history.jsonl")
+}
+
+func (app *App) loadHistory() ([]HistoryEntry, error) {
+	f, err := os.Open(app.historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []HistoryEntry{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+
+	for sc.Scan() {
+		var e HistoryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, sc.Err()
+}
+
+// recordHistory appends a run of cmd with args, as resolved from aliases
+// and global flags, to the history. Only visible commands are recorded and
+// failures to record are ignored.
+func (app *App) recordHistory(cmd *Command, args []string, start time.Time, err error) {
+	if cmd.Hidden || cmd.Name == "history" {
+		return
+	}
+
+	cmdArgs, redacted := cmd.redactArgs(args)
+
+	entries, _ := app.loadHistory()
+	entries = append(entries, HistoryEntry{
+		Time:     start.UTC(),
+		Command:  cmd.Name,
+		Args:     cmdArgs,
+		Redacted: redacted,
+		ExitCode: ExitCode(err),
+		Duration: time.Since(start),
+	})
+
+	if len(entries) > historyMax {
+		entries = entries[len(entries)-historyMax:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		enc.Encode(e)
+	}
+
+	writeFileAtomic(app.historyPath(), buf.Bytes())
+}
+
+// writeFileAtomic replaces path with b through a temporary file in the same
+// directory, so readers never see a partial file and concurrent writers
+// don't clobber each other's temporary files.
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// redactArgs replaces the values of secret flags and args in args, which are
+// the command's arguments as given on the command line.
+func (cmd *Command) redactArgs(args []string) ([]string, bool) {
+	out := make([]string, len(args))
+	copy(out, args)
+
+	redacted := false
+	redact := func(i int) {
+		out[i] = historyRedacted
+		redacted = true
+	}
+
+	pos := 0
+	flags := true
+
+	for i := 0; i < len(out); i++ {
+		a := out[i]
+
+		if flags && a == "--" {
+			flags = false
+			continue
+		}
+
+		if flags && len(a) > 1 && a[0] == '-' {
+			name := strings.TrimLeft(a, "-")
+			value := ""
+			hasValue := false
+
+			if eq := strings.Index(name, "="); eq >= 0 {
+				name, value, hasValue = name[:eq], name[eq+1:], true
+			}
+
+			f := cmd.Flags.Lookup(name)
+			if f == nil {
+				continue
+			}
+
+			bf, isBool := f.Value.(interface{ IsBoolFlag() bool })
+			isBool = isBool && bf.IsBoolFlag()
+
+			if !cmd.isSecret(name) {
+				if !hasValue && !isBool {
+					i++
+				}
+				continue
+			}
+
+			if hasValue {
+				out[i] = a[:len(a)-len(value)] + historyRedacted
+				redacted = true
+			} else if !isBool && i+1 < len(out) {
+				i++
+				redact(i)
+			}
+
+			continue
+		}
+
+		flags = false
+
+		name := ""
+		if pos < len(cmd.Args) {
+			name = cmd.Args[pos].Name
+		} else if n := len(cmd.Args); n > 0 && cmd.Args[n-1].Variable {
+			name = cmd.Args[n-1].Name
+		}
+
+		if name != "" && cmd.isSecret(name) {
+			redact(i)
+		}
+
+		pos++
+	}
+
+	return out, redacted
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactArgs(t *testing.T) {
+	c := NewCommand("login", "test-group", "logs in", func(c *Command) {}, nil)
+	c.Flags.String("password", "", "the password")
+	c.Flags.String("user", "", "the user")
+	c.Flags.Bool("force", false, "force it")
+	c.AppendArg("host", "the host")
+	c.AppendVarArg("tokens", "the tokens")
+	c.MarkSecret("password")
+	c.MarkSecret("tokens")
+
+	args, redacted := c.redactArgs([]string{"--user", "me", "-password", "hunter2", "--force", "example.com", "t1", "t2"})
+	expected := []string{"--user", "me", "-password", "***", "--force", "example.com", "***", "***"}
+
+	if !redacted || !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected %q, got %q", expected, args)
+	}
+
+	args, redacted = c.redactArgs([]string{"--password=hunter2", "--", "-host"})
+	expected = []string{"--password=***", "--", "-host"}
+
+	if !redacted || !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected %q, got %q", expected, args)
+	}
+
+	if _, redacted := c.redactArgs([]string{"--user=me", "example.com"}); redacted {
+		t.Fatal("Expected nothing redacted")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var out strings.Builder
+	greeted := []string{}
+
+	app := NewApp()
+	app.DataDir = t.TempDir()
+	app.Stdout = &out
+	app.EnableHistory()
+
+	app.AddCommand(NewCommand("greet", "test-group", "greets", func(c *Command) {
+		c.Flags.String("token", "", "a token")
+		c.AppendArg("who", "who to greet")
+		c.MarkSecret("token")
+	}, func(c *Command) error {
+		greeted = append(greeted, c.Arg("who").String())
+		return nil
+	}))
+
+	for _, args := range [][]string{{"greet", "alice"}, {"greet", "--token", "abc", "bob"}, {"greet"}} {
+		app.Run(append([]string{"app"}, args...))
+	}
+
+	entries, err := app.loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+
+	if entries[1].Args[1] != "***" || !entries[1].Redacted || entries[2].ExitCode != 2 {
+		t.Fatalf("Unexpected entries: %+v", entries)
+	}
+
+	if err := app.Run([]string{"app", "history", "--limit", "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "2 ") || !strings.HasSuffix(lines[1], "greet") {
+		t.Fatalf("Unexpected listing:\n%s", out.String())
+	}
+
+	if err := app.Run([]string{"app", "history", "--run", "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Run([]string{"app", "history", "--run", "2"}); err == nil {
+		t.Fatal("Expected redacted entry to be refused")
+	}
+
+	if !reflect.DeepEqual(greeted, []string{"alice", "bob", "alice"}) {
+		t.Fatalf("Unexpected runs: %q", greeted)
+	}
+}
+
+func TestHistoryResolved(t *testing.T) {
+	dir := t.TempDir()
+
+	app := NewApp()
+	app.DataDir = dir
+	app.ConfigFile = filepath.Join(dir, "config.yaml")
+	app.EnableHistory()
+	app.EnableAliases()
+
+	if err := os.WriteFile(app.ConfigFile, []byte("aliases:\n  hi: greet --loud\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app.AddCommand(NewCommand("greet", "test-group", "greets", func(c *Command) {
+		c.Flags.Bool("loud", false, "shout")
+		c.AppendArg("who", "who to greet")
+	}, func(c *Command) error {
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "--plain", "hi", "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := app.loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Command != "greet" || !reflect.DeepEqual(entries[0].Args, []string{"--loud", "alice"}) {
+		t.Fatalf("Expected the resolved command to be recorded, got %+v", entries)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, ".*"))
+	if len(files) != 0 {
+		t.Fatalf("Expected no temporary files left behind, got %q", files)
+	}
+}
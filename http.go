@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxHTTPRunBody caps the size of an HTTPRunRequest.
+const maxHTTPRunBody = 1 << 20
+
+// verbatimKey marks the context of a run whose args are taken as given: no
+// global flags, RewriteArgs or aliases, so a remote client can't use them to
+// reach past the commands it is allowed.
+type verbatimKey struct{}
+
+// HTTPRunRequest is the body POSTed to run a command: its name followed by
+// its flags and args, without the program name.
+type HTTPRunRequest struct {
+	Args []string `json:"args"`
+}
+
+// HTTPRunResponse reports a finished run. Err is empty when the command
+// succeeded.
+type HTTPRunResponse struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	Err      string `json:"error,omitempty"`
+}
+
+// NewHTTPHandler exposes the commands named in allowed as a small API:
+// GET /commands returns their description, like __describe, and POST /run
+// runs one from an HTTPRunRequest and returns an HTTPRunResponse, with
+// status 400 when its flags or args are invalid. The args are passed to the
+// command as they are, without global flags, RewriteArgs or aliases.
+// Commands read no input and cannot prompt. Other commands are refused with
+// 403.
+func NewHTTPHandler(app *App, allowed ...string) http.Handler {
+	allow := map[string]bool{}
+	for _, name := range allowed {
+		allow[name] = true
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/commands", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		info := app.Describe()
+
+		cmds := []CommandInfo{}
+		for _, c := range info.Commands {
+			if allow[c.Name] {
+				cmds = append(cmds, c)
+			}
+		}
+
+		for name := range allow {
+			if c, ok := app.command(name); ok && c.Hidden {
+				_, release := hold(r.Context(), c)
+				cmds = append(cmds, c.Describe())
+				release()
+			}
+		}
+
+		sort.Slice(cmds, func(i, j int) bool {
+			return cmds[i].Name < cmds[j].Name
+		})
+
+		info.Commands = cmds
+		writeJSON(w, http.StatusOK, info)
+	})
+
+	mux.HandleFunc("/run", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxHTTPRunBody)
+
+		var req HTTPRunRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Args) == 0 {
+			http.Error(w, "expected a JSON body with a non-empty args list", http.StatusBadRequest)
+			return
+		}
+
+		c, ok := app.command(req.Args[0])
+		if !ok || !allow[req.Args[0]] {
+			http.Error(w, "command not allowed", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), verbatimKey{}, true)
+		res, err := app.runForHTTP(ctx, c, req.Args)
+
+		status := http.StatusOK
+		if ue := (*UsageErr)(nil); errors.As(err, &ue) {
+			status = http.StatusBadRequest
+		}
+
+		writeJSON(w, status, res)
+	})
+
+	return mux
+}
+
+// runForHTTP runs args, which start with the name of cmd, capturing the
+// command's output. The run's error is returned too.
+func (app *App) runForHTTP(ctx context.Context, cmd *Command, args []string) (HTTPRunResponse, error) {
+	var stdout, stderr strings.Builder
+
+	err := app.runWithStreams(ctx, cmd, args, strings.NewReader(""), &stdout, &stderr)
+
+	res := HTTPRunResponse{ExitCode: ExitCode(err), Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		res.Err = err.Error()
+	}
+
+	return res, err
+}
+
+// runWithStreams runs args, which start with the name of cmd, with the
+// given streams in place of the command's own.
+func (app *App) runWithStreams(ctx context.Context, cmd *Command, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	ctx, release := hold(ctx, cmd)
+	defer release()
+
+	in, out, errOut := cmd.Stdin, cmd.Stdout, cmd.Stderr
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+
+	defer func() {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = in, out, errOut
+	}()
+
+	return app.RunContext(ctx, append([]string{app.name()}, args...))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
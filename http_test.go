@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	app := NewApp()
+	app.AddCommand(NewCommand("greet", "test-group", "greets someone", func(c *Command) {
+		c.AppendArg("who", "who to greet")
+	}, func(c *Command) error {
+		fmt.Fprintf(c.Stdout, "hello %s\n", c.Arg("who"))
+
+		if c.Arg("who") == "nobody" {
+			return errors.New("nobody is there")
+		}
+
+		return nil
+	}))
+	app.AddCommand(NewCommand("purge", "test-group", "deletes everything", func(*Command) {}, func(*Command) error {
+		t.Fatal("purge should not run")
+		return nil
+	}))
+
+	srv := httptest.NewServer(NewHTTPHandler(app, "greet"))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/commands")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var info AppInfo
+	json.NewDecoder(resp.Body).Decode(&info)
+	resp.Body.Close()
+
+	if len(info.Commands) != 1 || info.Commands[0].Name != "greet" {
+		t.Fatalf("Expected only greet to be described, got %+v", info.Commands)
+	}
+
+	run := func(body string) (int, HTTPRunResponse) {
+		resp, err := http.Post(srv.URL+"/run", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var res HTTPRunResponse
+		json.NewDecoder(resp.Body).Decode(&res)
+
+		return resp.StatusCode, res
+	}
+
+	if status, res := run(`{"args": ["greet", "world"]}`); status != http.StatusOK || res.ExitCode != 0 || res.Stdout != "hello world\n" {
+		t.Fatalf("Unexpected response %d %+v", status, res)
+	}
+
+	if status, res := run(`{"args": ["greet", "nobody"]}`); status != http.StatusOK || res.ExitCode != 1 || res.Err != "nobody is there" {
+		t.Fatalf("Unexpected response %d %+v", status, res)
+	}
+
+	if status, _ := run(`{"args": ["purge"]}`); status != http.StatusForbidden {
+		t.Fatalf("Expected purge to be refused, got %d", status)
+	}
+
+	if status, _ := run(`{}`); status != http.StatusBadRequest {
+		t.Fatalf("Expected a bad request, got %d", status)
+	}
+
+	if status, res := run(`{"args": ["greet", "--bogus", "world"]}`); status != http.StatusBadRequest || !strings.Contains(res.Err, "-bogus") {
+		t.Fatalf("Expected an unknown flag to be a bad request, got %d %+v", status, res)
+	}
+
+	if status, res := run(`{"args": ["greet", "again"]}`); status != http.StatusOK || res.Stdout != "hello again\n" {
+		t.Fatalf("Expected the server to keep running, got %d %+v", status, res)
+	}
+
+	if status, res := run(`{"args": ["greet", "--include-alpha", "world"]}`); status != http.StatusBadRequest || !strings.Contains(res.Err, "-include-alpha") {
+		t.Fatalf("Expected a global flag to be refused, got %d %+v", status, res)
+	}
+
+	app.RewriteArgs = func([]string) []string { return []string{"app", "purge"} }
+
+	if status, res := run(`{"args": ["greet", "world"]}`); status != http.StatusOK || res.Stdout != "hello world\n" {
+		t.Fatalf("Expected the args to be run as given, got %d %+v", status, res)
+	}
+
+	if status, _ := run(`{"args": ["greet", "` + strings.Repeat("x", maxHTTPRunBody) + `"]}`); status != http.StatusBadRequest {
+		t.Fatalf("Expected an oversized body to be refused, got %d", status)
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Messages translates English text for one locale. Keys are the messages
+// the framework prints, such as "Flags:" or "Wrong number of command
+// arguments", and the app's own descriptions and group names.
+type Messages map[string]string
+
+// AddMessages adds translations for locale, e.g. "de" or "pt_BR". Messages
+// for a language, like "pt", are used for all of its regional locales that
+// have no translation of their own.
+func (app *App) AddMessages(locale string, msgs Messages) {
+	app.checkFrozen("AddMessages")
+
+	if app.messages == nil {
+		app.messages = map[string]Messages{}
+	}
+
+	locale = normalizeLocale(locale)
+	if app.messages[locale] == nil {
+		app.messages[locale] = Messages{}
+	}
+
+	for k, v := range msgs {
+		app.messages[locale][k] = v
+	}
+}
+
+// locale is App.Locale or, when it is empty, the locale selected by the
+// LC_ALL, LC_MESSAGES or LANG environment variables.
+func (app *App) locale() string {
+	if app.Locale != "" {
+		return normalizeLocale(app.Locale)
+	}
+
+	if app.parent != nil {
+		return app.parent.locale()
+	}
+
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if l := app.getenv(v); l != "" {
+			return normalizeLocale(l)
+		}
+	}
+
+	return ""
+}
+
+// normalizeLocale turns "pt-BR" and "pt_BR.UTF-8@euro" into "pt_BR". The C
+// and POSIX locales are untranslated.
+func normalizeLocale(l string) string {
+	l, _, _ = strings.Cut(l, ".")
+	l, _, _ = strings.Cut(l, "@")
+	l = strings.ReplaceAll(l, "-", "_")
+
+	if l == "C" || l == "POSIX" {
+		return ""
+	}
+
+	return l
+}
+
+// tr translates s into the app's locale, falling back to s.
+func (app *App) tr(s string) string {
+	if app == nil || s == "" {
+		return s
+	}
+
+	locale := app.locale()
+	if locale == "" {
+		return s
+	}
+
+	lang, _, _ := strings.Cut(locale, "_")
+
+	for a := app; a != nil; a = a.parent {
+		if t, ok := a.messages[locale][s]; ok {
+			return t
+		}
+
+		if t, ok := a.messages[lang][s]; ok {
+			return t
+		}
+	}
+
+	return s
+}
+
+// trf translates format and formats it with args.
+func (app *App) trf(format string, args ...interface{}) string {
+	return fmt.Sprintf(app.tr(format), args...)
+}
+
+// CommandText is a translation of a command's help. Args, Flags and EnvArgs
+// map names to their descriptions.
+type CommandText struct {
+	Description string
+	Args        map[string]string
+	Flags       map[string]string
+	EnvArgs     map[string]string
+}
+
+// Localize sets the help shown for cmd in locale, e.g. "ar" or "pt_BR".
+// Texts it leaves out fall back to the app's Messages, then to English.
+func (cmd *Command) Localize(locale string, text CommandText) {
+	if cmd.texts == nil {
+		cmd.texts = map[string]CommandText{}
+	}
+
+	cmd.texts[normalizeLocale(locale)] = text
+}
+
+func (cmd *Command) localized(english string, pick func(CommandText) string) string {
+	if cmd.app == nil {
+		return english
+	}
+
+	if locale := cmd.app.locale(); locale != "" {
+		lang, _, _ := strings.Cut(locale, "_")
+
+		for _, l := range []string{locale, lang} {
+			if t, ok := cmd.texts[l]; ok {
+				if s := pick(t); s != "" {
+					return s
+				}
+			}
+		}
+	}
+
+	return cmd.app.tr(english)
+}
+
+func (cmd *Command) description() string {
+	return cmd.localized(cmd.Description, func(t CommandText) string { return t.Description })
+}
+
+func (cmd *Command) argDescription(a *Arg) string {
+	return cmd.localized(a.Description, func(t CommandText) string { return t.Args[a.Name] })
+}
+
+func (cmd *Command) flagUsage(f *flag.Flag) string {
+	return cmd.localized(f.Usage, func(t CommandText) string { return t.Flags[f.Name] })
+}
+
+func (cmd *Command) envArgDescription(name string) string {
+	desc, ok := cmd.EnvArgs[name]
+	if !ok {
+		desc = cmd.optionalEnv[name]
+	}
+
+	return cmd.localized(desc, func(t CommandText) string { return t.EnvArgs[name] })
+}
+
+var rtlLanguages = map[string]bool{"ar": true, "dv": true, "fa": true, "he": true, "ps": true, "ur": true, "yi": true}
+
+// ltr keeps s, a name or command line, left to right when the locale is
+// written right to left, by wrapping it in a Unicode directional isolate.
+func (app *App) ltr(s string) string {
+	if app == nil || s == "" {
+		return s
+	}
+
+	lang, _, _ := strings.Cut(app.locale(), "_")
+	if !rtlLanguages[lang] {
+		return s
+	}
+
+	return "\u2066" + s + "\u2069"
+}
+
+// displayWidth is the number of terminal columns s takes up: marks and
+// format characters such as directional isolates take none and wide East
+// Asian characters take two.
+func displayWidth(s string) int {
+	w := 0
+
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case isWide(r):
+			w += 2
+		default:
+			w++
+		}
+	}
+
+	return w
+}
+
+func isWide(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f ||
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f ||
+		r >= 0xac00 && r <= 0xd7a3 ||
+		r >= 0xf900 && r <= 0xfaff ||
+		r >= 0xfe30 && r <= 0xfe4f ||
+		r >= 0xff00 && r <= 0xff60 ||
+		r >= 0xffe0 && r <= 0xffe6 ||
+		r >= 0x1f300 && r <= 0x1f64f ||
+		r >= 0x20000 && r <= 0x3fffd)
+}
+
+// padRight pads s with spaces to width columns.
+func padRight(s string, width int) string {
+	if n := width - displayWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+
+	return s
+}
+
+// nameColumn is the width of a column listing names: 18, or the widest
+// name.
+func nameColumn(names []string) int {
+	w := 18
+	for _, n := range names {
+		if dw := displayWidth(n); dw > w {
+			w = dw
+		}
+	}
+
+	return w
+}
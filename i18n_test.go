@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppMessages(t *testing.T) {
+	app := NewApp()
+	app.Name = "app"
+	app.Getenv = func(name string) string {
+		if name == "LANG" {
+			return "de_AT.UTF-8"
+		}
+
+		return ""
+	}
+
+	app.AddMessages("de", Messages{
+		"usage:":                            "Aufruf:",
+		"Flags:":                            "Optionen:",
+		"[flags]":                           "[Optionen]",
+		"Command Arguments:":                "Argumente:",
+		"Wrong number of command arguments": "Falsche Anzahl von Argumenten",
+		"Deploy a service":                  "Einen Dienst ausrollen",
+		"The service":                       "Der Dienst",
+	})
+	app.AddMessages("de_AT", Messages{"Optionen:": "unused", "Flags:": "Schalter:"})
+
+	c := NewCommand("deploy", "test-group", "Deploy a service", func(c *Command) {
+		c.AppendArg("service", "The service")
+		c.Flags.Bool("force", false, "Skip checks")
+	}, func(c *Command) error { return nil })
+	app.AddCommand(c)
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	err := app.Run([]string{"app", "deploy"})
+	if err == nil || err.Error() != "Falsche Anzahl von Argumenten" {
+		t.Fatalf("Expected a translated error, got %v", err)
+	}
+
+	out.Reset()
+	c.Usage()
+
+	for _, expected := range []string{
+		"Aufruf: app deploy [Optionen] service",
+		"Einen Dienst ausrollen",
+		"Argumente:\n    service: Der Dienst",
+		"Schalter:\n    force: Skip checks",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("Expected %q in\n%s", expected, out.String())
+		}
+	}
+
+	app.Locale = "C"
+	if s := app.tr("Flags:"); s != "Flags:" {
+		t.Fatalf("Expected no translation for the C locale, got %q", s)
+	}
+}
+
+func TestCmdLocalize(t *testing.T) {
+	app := NewApp()
+	app.Name = "app"
+	app.Locale = "he_IL"
+	app.AddMessages("he", Messages{"Flags:": "אפשרויות:"})
+
+	c := NewCommand("deploy", "test-group", "Deploy a service", func(c *Command) {
+		c.AppendArg("service", "The service")
+		c.Flags.Bool("force", false, "Skip checks")
+	}, nil)
+	c.Localize("he", CommandText{
+		Description: "פריסת שירות",
+		Args:        map[string]string{"service": "השירות"},
+		Flags:       map[string]string{"force": "דלג על בדיקות"},
+	})
+	app.AddCommand(c)
+	app.AddCommand(NewCommand("a-command-with-a-long-name", "test-group", "Does things", func(c *Command) {}, nil))
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	c.Usage()
+
+	for _, expected := range []string{
+		"usage: \u2066app deploy [flags] service \u2069",
+		"פריסת שירות",
+		"\u2066service\u2069: השירות",
+		"אפשרויות:\n    \u2066force\u2069: דלג על בדיקות",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("Expected %q in\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	app.Usage()
+
+	for _, expected := range []string{
+		"    \u2066a-command-with-a-long-name\u2069 Does things\n",
+		"    \u2066deploy\u2069                     פריסת שירות\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("Expected %q in\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	for s, w := range map[string]int{"deploy": 6, "\u2066deploy\u2069": 6, "デプロイ": 8, "é": 1} {
+		if dw := displayWidth(s); dw != w {
+			t.Fatalf("Expected width %d for %q, got %d", w, s, dw)
+		}
+	}
+}
+
+func TestFrameworkMessages(t *testing.T) {
+	var out, errOut strings.Builder
+
+	app := NewApp()
+	app.Name = "app"
+	app.Locale = "de"
+	app.Stdout = &out
+	app.Stderr = &errOut
+	app.AddMessages("de", Messages{
+		"No commands match %q":            "Keine Befehle passen zu %q",
+		"%s: attempt %d/%d":               "%s: Versuch %d/%d",
+		"command %s timed out after %s":   "%s nach %s abgebrochen",
+		"type to filter, enter to choose": "tippen zum Filtern",
+	})
+
+	runs := 0
+
+	c := NewCommand("flaky", "test-group", "fails once", func(c *Command) {}, func(c *Command) error {
+		if runs++; runs == 1 {
+			return errors.New("flaky")
+		}
+
+		return nil
+	})
+	c.Retry = &RetryPolicy{Attempts: 2, Backoff: time.Millisecond}
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "--help", "zzz"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Run([]string{"app", "flaky"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "Keine Befehle passen zu \"zzz\"\n" || !strings.Contains(errOut.String(), "flaky: Versuch 2/2\n") {
+		t.Fatalf("Expected translated output, got %q and %q", out.String(), errOut.String())
+	}
+
+	if s := (&TimeoutErr{Command: "flaky", Timeout: time.Second, app: app}).Error(); s != "flaky nach 1s abgebrochen" {
+		t.Fatalf("Expected a translated timeout, got %q", s)
+	}
+
+	var picker strings.Builder
+
+	p := newPicker("Region", []string{"eu"}, false)
+	p.app = app
+	p.render(&picker)
+
+	if !strings.HasPrefix(picker.String(), "Region (tippen zum Filtern): ") {
+		t.Fatalf("Expected a translated key hint, got %q", picker.String())
+	}
+}
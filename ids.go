@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// UUID is a parsed UUID, see Value.UUID.
+type UUID [16]byte
+
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// UUID parses v as a UUID in the usual 8-4-4-4-12 hex form, in any case.
+func (v Value) UUID() (UUID, error) {
+	var u UUID
+
+	s := string(v)
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errors.New("must be a UUID like 123e4567-e89b-12d3-a456-426614174000")
+	}
+
+	if _, err := hex.Decode(u[:], []byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		return u, errors.New("must be a UUID like 123e4567-e89b-12d3-a456-426614174000")
+	}
+
+	return u, nil
+}
+
+// newULID returns a random ULID for the current time.
+func newULID() ULID {
+	var u ULID
+
+	ms := uint64(time.Now().UnixMilli())
+	for i := 0; i < 6; i++ {
+		u[i] = byte(ms >> (40 - 8*i))
+	}
+
+	rand.Read(u[6:])
+
+	return u
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID is a parsed ULID, see Value.ULID.
+type ULID [16]byte
+
+func (u ULID) String() string {
+	// The 26 characters hold 130 bits, the first two always zero
+	bit := func(i int) int {
+		if i -= 2; i < 0 {
+			return 0
+		}
+
+		return int(u[i/8]>>(7-i%8)) & 1
+	}
+
+	b := make([]byte, 26)
+	for i := range b {
+		c := 0
+		for j := 0; j < 5; j++ {
+			c = c<<1 | bit(i*5+j)
+		}
+
+		b[i] = crockford[c]
+	}
+
+	return string(b)
+}
+
+// Time returns the time the ULID was made, to the millisecond.
+func (u ULID) Time() time.Time {
+	ms := int64(0)
+	for _, b := range u[:6] {
+		ms = ms<<8 | int64(b)
+	}
+
+	return time.UnixMilli(ms)
+}
+
+// ULID parses v as a 26 character ULID, in any case.
+func (v Value) ULID() (ULID, error) {
+	var u ULID
+
+	err := errors.New("must be a ULID like 01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if len(v) != 26 {
+		return u, err
+	}
+
+	for _, r := range strings.ToUpper(string(v)) {
+		c := strings.IndexRune(crockford, r)
+		if c < 0 {
+			return u, err
+		}
+
+		carry := uint16(c)
+		for i := len(u) - 1; i >= 0; i-- {
+			x := uint16(u[i])<<5 | carry
+			u[i], carry = byte(x), x>>8
+		}
+
+		if carry != 0 {
+			return ULID{}, err
+		}
+	}
+
+	return u, nil
+}
+
+// IsUUID is a Validator for values accepted by Value.UUID.
+func IsUUID(v Value) error {
+	_, err := v.UUID()
+	return err
+}
+
+// IsULID is a Validator for values accepted by Value.ULID.
+func IsULID(v Value) error {
+	_, err := v.ULID()
+	return err
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValueIDs(t *testing.T) {
+	u, err := Value("123E4567-E89B-12D3-A456-426614174000").UUID()
+	if err != nil || u.String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Fatalf("Unexpected %v %v", u, err)
+	}
+
+	l, err := Value("01arz3ndektsv4rrffq69g5fav").ULID()
+	if err != nil || l.String() != "01ARZ3NDEKTSV4RRFFQ69G5FAV" {
+		t.Fatalf("Unexpected %v %v", l, err)
+	}
+
+	if ts := l.Time(); !ts.Equal(time.UnixMilli(1469922850259)) {
+		t.Fatalf("Unexpected time %v", ts)
+	}
+
+	testCases := []struct {
+		v   Validator
+		in  Value
+		err string
+	}{
+		{IsUUID, "00000000-0000-0000-0000-000000000000", ""},
+		{IsUUID, "123e4567e89b12d3a456426614174000", "must be a UUID like 123e4567-e89b-12d3-a456-426614174000"},
+		{IsUUID, "123e4567-e89b-12d3-a456-42661417400g", "must be a UUID like 123e4567-e89b-12d3-a456-426614174000"},
+		{IsULID, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", ""},
+		{IsULID, "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "must be a ULID like 01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		{IsULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU", "must be a ULID like 01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.v(tc.in)
+
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || err.Error() != tc.err) {
+			t.Fatalf("Expected %q for %q, got %v", tc.err, tc.in, err)
+		}
+	}
+}
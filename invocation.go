@@ -0,0 +1,82 @@
+package cmd
+
+import "flag"
+
+// Invocation is a parsed run of a command: its resolved name and the value
+// of every arg, flag and env arg, with where each came from. It marshals to
+// JSON for audit logs or for handing the run to another process.
+type Invocation struct {
+	Command string                     `json:"command"`
+	Args    map[string]InvocationValue `json:"args"`
+	VarArgs []string                   `json:"var_args"`
+	Flags   map[string]InvocationValue `json:"flags"`
+	Env     map[string]InvocationValue `json:"env"`
+}
+
+type InvocationValue struct {
+	Value      string     `json:"value"`
+	Provenance Provenance `json:"provenance"`
+	Secret     bool       `json:"secret,omitempty"`
+}
+
+// Invocation describes the current run of cmd. It is meant to be called from
+// the command's RunFunc, once args have been parsed.
+func (cmd *Command) Invocation() *Invocation {
+	inv := &Invocation{
+		Command: cmd.Name,
+		Args:    map[string]InvocationValue{},
+		VarArgs: []string{},
+		Flags:   map[string]InvocationValue{},
+		Env:     map[string]InvocationValue{},
+	}
+
+	value := func(name, v string) InvocationValue {
+		return InvocationValue{Value: v, Provenance: cmd.Provenance(name), Secret: cmd.isSecret(name)}
+	}
+
+	for i, a := range cmd.Args {
+		if a.Variable {
+			for _, v := range cmd.VarArgs() {
+				inv.VarArgs = append(inv.VarArgs, v.String())
+			}
+			break
+		}
+
+		inv.Args[a.Name] = value(a.Name, cmd.Flags.Arg(i))
+	}
+
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		inv.Flags[f.Name] = value(f.Name, f.Value.String())
+	})
+
+	for n := range cmd.EnvArgs {
+		inv.Env[n] = value(n, cmd.EnvArg(n).String())
+	}
+
+	return inv
+}
+
+// Redacted returns a copy of inv with secret values replaced by "***", fit
+// for logging.
+func (inv *Invocation) Redacted() *Invocation {
+	c := *inv
+	c.Args = redactValues(inv.Args)
+	c.Flags = redactValues(inv.Flags)
+	c.Env = redactValues(inv.Env)
+
+	return &c
+}
+
+func redactValues(values map[string]InvocationValue) map[string]InvocationValue {
+	ret := make(map[string]InvocationValue, len(values))
+
+	for n, v := range values {
+		if v.Secret {
+			v.Value = historyRedacted
+		}
+
+		ret[n] = v
+	}
+
+	return ret
+}
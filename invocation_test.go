@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInvocation(t *testing.T) {
+	var inv *Invocation
+
+	app := NewApp()
+	app.Getenv = func(name string) string { return "s3cret" }
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys things", func(c *Command) {
+		c.AppendArg("service", "the service")
+		c.AppendVarArg("hosts", "the hosts")
+		c.Flags.Bool("force", false, "force it")
+		c.Flags.String("region", "us", "the region")
+		c.AddSecretEnvArg("TOKEN", "a token")
+	}, func(c *Command) error {
+		inv = c.Invocation()
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "deploy", "--force", "web", "h1", "h2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if inv.Command != "deploy" || inv.Args["service"].Value != "web" || len(inv.VarArgs) != 2 {
+		t.Fatalf("Unexpected invocation: %+v", inv)
+	}
+
+	if f := inv.Flags["force"]; f.Value != "true" || f.Provenance.Source != SourceCLI {
+		t.Fatalf("Unexpected force flag: %+v", f)
+	}
+
+	if f := inv.Flags["region"]; f.Value != "us" || f.Provenance.Source != SourceDefault {
+		t.Fatalf("Unexpected region flag: %+v", f)
+	}
+
+	if e := inv.Env["TOKEN"]; e.Value != "s3cret" || !e.Secret {
+		t.Fatalf("Unexpected env arg: %+v", e)
+	}
+
+	b, err := json.Marshal(inv.Redacted())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Invocation
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Env["TOKEN"].Value != "***" || decoded.Args["service"].Value != "web" || inv.Env["TOKEN"].Value != "s3cret" {
+		t.Fatalf("Expected only the secret to be redacted, got %s", b)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyBackspace
+	keyTab
+	keyCancel
+	keyEOF
+	keyUnknown
+)
+
+type keypress struct {
+	kind keyKind
+	r    rune
+}
+
+func readByte(r io.Reader) (byte, error) {
+	buf := make([]byte, 1)
+
+	for {
+		n, err := r.Read(buf)
+		if n == 1 {
+			return buf[0], nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// readKey reads one keypress from a terminal in raw mode.
+func readKey(r io.Reader) (keypress, error) {
+	b, err := readByte(r)
+	if err != nil {
+		return keypress{}, err
+	}
+
+	switch b {
+	case 0x03:
+		return keypress{kind: keyCancel}, nil
+	case 0x04:
+		return keypress{kind: keyEOF}, nil
+	case '\r', '\n':
+		return keypress{kind: keyEnter}, nil
+	case 0x7f, 0x08:
+		return keypress{kind: keyBackspace}, nil
+	case '\t':
+		return keypress{kind: keyTab}, nil
+	case 0x10:
+		return keypress{kind: keyUp}, nil
+	case 0x0e:
+		return keypress{kind: keyDown}, nil
+	case 0x1b:
+		return readEscape(r)
+	}
+
+	if b < 0x20 {
+		return keypress{kind: keyUnknown}, nil
+	}
+
+	if b < utf8.RuneSelf {
+		return keypress{kind: keyRune, r: rune(b)}, nil
+	}
+
+	buf := []byte{b}
+	for !utf8.FullRune(buf) && len(buf) < utf8.UTFMax {
+		c, err := readByte(r)
+		if err != nil {
+			return keypress{}, err
+		}
+
+		buf = append(buf, c)
+	}
+
+	ru, _ := utf8.DecodeRune(buf)
+	return keypress{kind: keyRune, r: ru}, nil
+}
+
+func readEscape(r io.Reader) (keypress, error) {
+	b, err := readByte(r)
+	if err != nil {
+		return keypress{}, err
+	}
+
+	if b != '[' && b != 'O' {
+		return keypress{kind: keyUnknown}, nil
+	}
+
+	b, err = readByte(r)
+	if err != nil {
+		return keypress{}, err
+	}
+
+	switch b {
+	case 'A':
+		return keypress{kind: keyUp}, nil
+	case 'B':
+		return keypress{kind: keyDown}, nil
+	case 'C':
+		return keypress{kind: keyRight}, nil
+	case 'D':
+		return keypress{kind: keyLeft}, nil
+	}
+
+	// Skip the rest of longer sequences such as ESC [ 3 ~
+	for b >= '0' && b <= '9' || b == ';' {
+		if b, err = readByte(r); err != nil {
+			return keypress{}, err
+		}
+	}
+
+	return keypress{kind: keyUnknown}, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+)
+
+// KV is a key=value pair given to a KV arg.
+type KV struct {
+	Key   string
+	Value Value
+}
+
+// KVs are the pairs given to a KV arg, in the order of their first use.
+type KVs []KV
+
+// Get returns the value for key, or false if it wasn't given.
+func (kvs KVs) Get(key string) (Value, bool) {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// AppendKVVarArg adds a variable arg taking key=value pairs, like
+// "env=prod tier=web". Read them with KVArgs.
+func (cmd *Command) AppendKVVarArg(name, desc string) {
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc, Variable: true, KV: true})
+}
+
+// KVArgs returns the pairs given to the command's KV arg. A key given twice
+// keeps its first position and takes the last value.
+func (cmd *Command) KVArgs() KVs {
+	ret := KVs{}
+
+	i := len(cmd.Args) - 1
+	if i < 0 || !cmd.Args[i].KV {
+		return ret
+	}
+
+	index := map[string]int{}
+
+	for _, v := range cmd.VarArgs() {
+		k, val, _ := strings.Cut(string(v), "=")
+
+		if j, ok := index[k]; ok {
+			ret[j].Value = Value(val)
+			continue
+		}
+
+		index[k] = len(ret)
+		ret = append(ret, KV{k, Value(val)})
+	}
+
+	return ret
+}
+
+func isKV(v Value) error {
+	if k, _, ok := strings.Cut(string(v), "="); !ok || k == "" {
+		return errors.New("must be key=value")
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCmdKVArgs(t *testing.T) {
+	var got KVs
+
+	app := NewApp()
+	app.AddCommand(NewCommand("label", "test-group", "labels a node", func(c *Command) {
+		c.AppendArg("node", "the node")
+		c.AppendKVVarArg("labels", "the labels")
+	}, func(c *Command) error {
+		got = c.KVArgs()
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "label", "n1", "env=prod", "note=a=b", "tier=", "env=dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := KVs{{"env", "dev"}, {"note", "a=b"}, {"tier", ""}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+
+	if v, ok := got.Get("note"); !ok || v != "a=b" {
+		t.Fatalf("Unexpected note %q", v)
+	}
+
+	for _, arg := range []string{"env", "=prod"} {
+		err := app.Run([]string{"app", "label", "n1", arg})
+		if msg := `Invalid value "` + arg + `" for labels: must be key=value`; !errors.Is(err, ErrInvalidValue) || err.Error() != msg {
+			t.Fatalf("Expected %q, got %v", msg, err)
+		}
+	}
+}
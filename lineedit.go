@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// lineEditor reads lines from a terminal in raw mode with history and tab
+// completion.
+type lineEditor struct {
+	in       io.Reader
+	out      io.Writer
+	prompt   string
+	history  []string
+	complete func(line string) []string
+
+	buf []rune
+	pos int
+}
+
+func (le *lineEditor) redraw() {
+	fmt.Fprintf(le.out, "\r\x1b[K%s%s", le.prompt, string(le.buf))
+
+	if back := len(le.buf) - le.pos; back > 0 {
+		fmt.Fprintf(le.out, "\x1b[%dD", back)
+	}
+}
+
+func (le *lineEditor) set(line string) {
+	le.buf = []rune(line)
+	le.pos = len(le.buf)
+}
+
+// readLine returns the next line, "" when it was aborted with Ctrl-C, or
+// io.EOF on Ctrl-D at an empty line.
+func (le *lineEditor) readLine() (string, error) {
+	le.set("")
+	le.redraw()
+
+	hist := len(le.history)
+
+	for {
+		kp, err := readKey(le.in)
+		if err != nil {
+			return "", err
+		}
+
+		switch kp.kind {
+		case keyEnter:
+			fmt.Fprint(le.out, "\n")
+
+			line := string(le.buf)
+			if strings.TrimSpace(line) != "" && (len(le.history) == 0 || le.history[len(le.history)-1] != line) {
+				le.history = append(le.history, line)
+			}
+
+			return line, nil
+		case keyCancel:
+			fmt.Fprint(le.out, "^C\n")
+			return "", nil
+		case keyEOF:
+			if len(le.buf) == 0 {
+				fmt.Fprint(le.out, "\n")
+				return "", io.EOF
+			}
+		case keyBackspace:
+			if le.pos > 0 {
+				le.buf = append(le.buf[:le.pos-1], le.buf[le.pos:]...)
+				le.pos--
+			}
+		case keyLeft:
+			if le.pos > 0 {
+				le.pos--
+			}
+		case keyRight:
+			if le.pos < len(le.buf) {
+				le.pos++
+			}
+		case keyUp:
+			if hist > 0 {
+				hist--
+				le.set(le.history[hist])
+			}
+		case keyDown:
+			if hist < len(le.history)-1 {
+				hist++
+				le.set(le.history[hist])
+			} else {
+				hist = len(le.history)
+				le.set("")
+			}
+		case keyTab:
+			le.completeWord()
+		case keyRune:
+			le.buf = append(le.buf[:le.pos], append([]rune{kp.r}, le.buf[le.pos:]...)...)
+			le.pos++
+		}
+
+		le.redraw()
+	}
+}
+
+func (le *lineEditor) completeWord() {
+	if le.complete == nil {
+		return
+	}
+
+	head := string(le.buf[:le.pos])
+	start := strings.LastIndexAny(head, " \t") + 1
+	partial := head[start:]
+
+	candidates := le.complete(head)
+	if len(candidates) == 0 {
+		return
+	}
+
+	completion := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completion += " "
+	}
+
+	if len(completion) > len(partial) {
+		insert := []rune(completion[len(partial):])
+		le.buf = append(le.buf[:le.pos], append(insert, le.buf[le.pos:]...)...)
+		le.pos += len(insert)
+		return
+	}
+
+	if len(candidates) > 1 {
+		fmt.Fprintf(le.out, "\n%s\n", strings.Join(candidates, "  "))
+	}
+}
+
+func commonPrefix(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
+
+// completeLine returns completions for the last word of a partial command
+// line: command names for the first word, flag names after it, and paths
+// for file and directory args.
+func (app *App) completeLine(ctx context.Context, line string) []string {
+	words, err := splitArgs(line)
+	if err != nil {
+		return nil
+	}
+
+	partial := ""
+	if len(line) > 0 && !strings.ContainsAny(line[len(line)-1:], " \t") && len(words) > 0 {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	ret := []string{}
+
+	if len(words) == 0 {
+		for name, cmd := range app.Commands {
+			if app.listed(ctx, cmd) && strings.HasPrefix(name, partial) {
+				ret = append(ret, name)
+			}
+		}
+
+		// Offer the new name of a renamed command for its old name
+		offered := map[string]bool{}
+		for from := range app.redirects {
+			to, ok := app.redirect(from)
+			if !ok || offered[to] || strings.HasPrefix(to, partial) || !strings.HasPrefix(from, partial) {
+				continue
+			}
+
+			if cmd, ok := app.Commands[to]; ok && app.listed(ctx, cmd) {
+				ret = append(ret, to)
+				offered[to] = true
+			}
+		}
+	} else if cmd, ok := app.Commands[words[0]]; ok && cmd.mount != nil {
+		return completeMounted(ctx, cmd.mount, words[0], line)
+	} else if cmd, ok := app.resolve(words[0]); ok && strings.HasPrefix(partial, "-") {
+		_, release := hold(ctx, cmd)
+		defer release()
+
+		app.prepare(cmd)
+
+		for _, name := range cmd.flagNames() {
+			if f := "--" + name; strings.HasPrefix(f, partial) {
+				ret = append(ret, f)
+			}
+		}
+	} else if cmd, ok := app.resolve(words[0]); ok {
+		if a := cmd.argAt(words[1:]); a != nil && a.path != nil {
+			ret = a.path.complete(partial)
+		}
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
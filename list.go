@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"flag"
+	"sort"
+	"strings"
+)
+
+// ListOptions controls how a list flag collects its values.
+type ListOptions struct {
+	// Dedupe drops repeated values, keeping the first.
+	Dedupe bool
+
+	// Sort sorts the values. Otherwise they keep the order they were given.
+	Sort bool
+}
+
+// listValue is a flag.Value that collects every value it is set to, split
+// on commas and newlines. The first Set replaces the default.
+type listValue struct {
+	p    *[]string
+	def  []string
+	opts ListOptions
+	set  bool
+}
+
+func (lv *listValue) String() string {
+	if lv.p == nil {
+		return ""
+	}
+
+	return strings.Join(*lv.p, ",")
+}
+
+func (lv *listValue) Set(s string) error {
+	if !lv.set {
+		*lv.p = nil
+		lv.set = true
+	}
+
+	*lv.p = lv.opts.apply(append(*lv.p, splitList(s)...))
+	return nil
+}
+
+func (lv *listValue) Get() interface{} {
+	return *lv.p
+}
+
+func (lv *listValue) reset() {
+	*lv.p = append([]string(nil), lv.def...)
+	lv.set = false
+}
+
+func (lv *listValue) clone() flag.Value {
+	return &listValue{p: new([]string), def: lv.def, opts: lv.opts}
+}
+
+func (opts ListOptions) apply(values []string) []string {
+	if opts.Dedupe {
+		seen := map[string]bool{}
+		kept := values[:0]
+
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				kept = append(kept, v)
+			}
+		}
+
+		values = kept
+	}
+
+	if opts.Sort {
+		sort.Strings(values)
+	}
+
+	return values
+}
+
+func splitList(s string) []string {
+	ret := []string{}
+
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
+
+// AddFlagList defines a flag that can be repeated and takes comma or
+// newline separated values, so "--tag a,b --tag c" gives [a b c]. The
+// default is used only when the flag isn't given.
+func (cmd *Command) AddFlagList(name string, value []string, usage string, opts ListOptions) *[]string {
+	def := opts.apply(append([]string(nil), value...))
+
+	p := new([]string)
+	*p = append([]string(nil), def...)
+	cmd.Flags.Var(&listValue{p: p, def: def, opts: opts}, name, usage)
+	return p
+}
+
+// FlagSlice returns the values of a list flag. Any other flag's value is
+// split on commas and newlines.
+func (cmd *Command) FlagSlice(name string) []string {
+	f := cmd.Flags.Lookup(name)
+	if f == nil {
+		return nil
+	}
+
+	if lv, ok := f.Value.(*listValue); ok {
+		return append([]string{}, *lv.p...)
+	}
+
+	return splitList(f.Value.String())
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCmdFlagList(t *testing.T) {
+	var tags, zones []string
+
+	app := NewApp()
+	app.AddCommand(NewCommand("tag", "test-group", "tags things", func(c *Command) {
+		c.AddFlagList("tag", []string{"default"}, "tags to add", ListOptions{})
+		c.AddFlagList("zone", nil, "zones", ListOptions{Dedupe: true, Sort: true})
+	}, func(c *Command) error {
+		tags, zones = c.FlagSlice("tag"), c.FlagSlice("zone")
+		return nil
+	}))
+
+	testCases := []struct {
+		args  []string
+		tags  []string
+		zones []string
+	}{
+		{[]string{"--tag", "b,a", "--tag", "c\nb", "--zone", "z, y,z", "--zone=x"}, []string{"b", "a", "c", "b"}, []string{"x", "y", "z"}},
+		{[]string{}, []string{"default"}, []string{}},
+		{[]string{"--tag", "only"}, []string{"only"}, []string{}},
+	}
+
+	for _, tc := range testCases {
+		if err := app.Run(append([]string{"app", "tag"}, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(tags, tc.tags) || !reflect.DeepEqual(zones, tc.zones) {
+			t.Fatalf("Expected %q %q for %q, got %q %q", tc.tags, tc.zones, tc.args, tags, zones)
+		}
+	}
+
+	cmd, _ := app.command("tag")
+	res, err := cmd.ParseStrict([]string{"--tag", "x", "--tag", "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := res.Flags["tag"]; v != "x,y" {
+		t.Fatalf("Expected x,y, got %q", v)
+	}
+}
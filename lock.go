@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LockedErr is returned when an exclusive command is already running.
+type LockedErr struct {
+	Command string
+	Path    string
+	PID     int
+
+	app *App
+}
+
+func (le *LockedErr) Error() string {
+	return le.app.trf("%s is already running (pid %d); remove %s if this is wrong", le.Command, le.PID, le.Path)
+}
+
+type lockFile struct {
+	path string
+	next *lockFile
+}
+
+// acquireLock creates the lock file at path holding our pid. The file is
+// written in full to a temporary file and hard linked into place, so a
+// holder never sees a half-written lock, and a stale lock is only removed
+// if it is still the same file with the same dead pid.
+func acquireLock(path, name string) (*lockFile, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = fmt.Fprintf(tmp, "%d\n", os.Getpid())
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < 2; i++ {
+		err := os.Link(tmp.Name(), path)
+		if err == nil {
+			return &lockFile{path: path}, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		fi, pid, err := readLock(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if pid > 0 && processAlive(pid) {
+			return nil, &LockedErr{Command: name, Path: path, PID: pid}
+		}
+
+		// The previous holder died without cleaning up. Make sure no one
+		// else took the lock over in the meantime before removing it.
+		if again, againPID, err := readLock(path); err == nil && againPID == pid && os.SameFile(fi, again) {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("could not acquire lock %s", path)
+}
+
+// readLock returns the lock file at path and the pid it holds.
+func readLock(path string) (os.FileInfo, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	b, err := io.ReadAll(io.LimitReader(f, 64))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+	return fi, pid, nil
+}
+
+// release removes the lock files, as long as they are still ours.
+func (lf *lockFile) release() {
+	for ; lf != nil; lf = lf.next {
+		if _, pid, err := readLock(lf.path); err == nil && pid == os.Getpid() {
+			os.Remove(lf.path)
+		}
+	}
+}
+
+// lock takes the app-wide lock, then the command's, so that two processes
+// never wait on each other's locks in the opposite order. Command locks are
+// prefixed so that one can't be named like the app's.
+func (app *App) lock(cmd *Command) (_ *lockFile, err error) {
+	if !app.Exclusive && !cmd.Exclusive {
+		return nil, nil
+	}
+
+	defer func() {
+		if le, ok := err.(*LockedErr); ok {
+			le.app = app
+		}
+	}()
+
+	dir, err := app.runtimeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var held *lockFile
+
+	if app.Exclusive {
+		lf, err := acquireLock(filepath.Join(dir, app.name()+".lock"), app.name())
+		if err != nil {
+			return nil, err
+		}
+
+		held = lf
+	}
+
+	if cmd.Exclusive {
+		lf, err := acquireLock(filepath.Join(dir, "cmd-"+cmd.Name+".lock"), cmd.Name)
+		if err != nil {
+			held.release()
+			return nil, err
+		}
+
+		lf.next = held
+		held = lf
+	}
+
+	return held, nil
+}
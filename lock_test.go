@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCmdExclusive(t *testing.T) {
+	app := NewApp()
+	app.RuntimeDir = t.TempDir()
+
+	var nestedErr error
+
+	c := NewCommand("migrate", "test-group", "migrates things", func(c *Command) {}, func(c *Command) error {
+		_, nestedErr = app.lock(c)
+		return nil
+	})
+	c.Exclusive = true
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "migrate"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := nestedErr.(*LockedErr); !ok {
+		t.Fatalf("Expected a locked error while running, got %v", nestedErr)
+	}
+
+	// The lock is released after the run
+	if err := app.Run([]string{"app", "migrate"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.lock")
+
+	// Pids are never this large, so the holder can't be alive
+	if err := os.WriteFile(path, []byte(fmt.Sprintln(1<<30)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	lf, err := acquireLock(path, "stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lf.release()
+}
+
+func TestAppAndCmdExclusive(t *testing.T) {
+	app := NewApp()
+	app.RuntimeDir = t.TempDir()
+	app.Exclusive = true
+
+	var appErr error
+
+	c := NewCommand("migrate", "test-group", "migrates things", func(c *Command) {}, func(c *Command) error {
+		_, appErr = app.lock(app.Commands["other"])
+		return nil
+	})
+	c.Exclusive = true
+	app.AddCommand(c)
+	app.AddCommand(NewCommand("other", "test-group", "does other things", func(c *Command) {}, func(c *Command) error {
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "migrate"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := appErr.(*LockedErr); !ok {
+		t.Fatalf("Expected the app lock to be held by an exclusive command, got %v", appErr)
+	}
+
+	entries, err := os.ReadDir(app.RuntimeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("Expected both locks to be released, found %d files", len(entries))
+	}
+}
+
+func TestCmdNamedLikeApp(t *testing.T) {
+	app := NewApp()
+	app.Name = "tool"
+	app.RuntimeDir = t.TempDir()
+	app.Exclusive = true
+
+	c := NewCommand("tool", "test-group", "does tool things", func(c *Command) {}, func(c *Command) error {
+		return nil
+	})
+	c.Exclusive = true
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"tool", "tool"}); err != nil {
+		t.Fatalf("Expected the app and command locks to be distinct, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+
+	return slog.LevelInfo
+}
+
+// Logger writes leveled messages through a slog.Logger. Messages below the
+// logger's level are dropped.
+type Logger struct {
+	slog  *slog.Logger
+	level Level
+}
+
+func (l *Logger) Level() Level {
+	return l.level
+}
+
+// Slog returns the underlying structured logger.
+func (l *Logger) Slog() *slog.Logger {
+	return l.slog
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+
+	l.slog.Log(context.Background(), level.slogLevel(), fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, format, args...)
+}
+
+func (cmd *Command) logLevel() Level {
+	switch {
+	case cmd.verboseFlag != nil && *cmd.verboseFlag:
+		return LevelDebug
+	case cmd.quietFlag != nil && *cmd.quietFlag:
+		return LevelError
+	}
+
+	return LevelInfo
+}
+
+// Log returns the command's logger. Its level follows the --quiet and
+// --verbose flags when the App adds them. Messages go to the App's
+// LogHandler if set, as JSON in CI, and as plain text otherwise.
+func (cmd *Command) Log() *Logger {
+	level := cmd.logLevel()
+
+	var h slog.Handler
+
+	switch {
+	case cmd.app != nil && cmd.app.LogHandler != nil:
+		h = &levelHandler{Handler: cmd.app.LogHandler, level: level.slogLevel()}
+	case cmd.app.isCI():
+		h = slog.NewJSONHandler(cmd.stderr(), &slog.HandlerOptions{Level: level.slogLevel()})
+	default:
+		h = newPlainHandler(cmd.stderr(), level.slogLevel())
+	}
+
+	return &Logger{slog: slog.New(h).With("command", cmd.Name), level: level}
+}
+
+// levelHandler applies the command's level on top of an injected handler.
+type levelHandler struct {
+	slog.Handler
+	level slog.Level
+}
+
+func (lh *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= lh.level && lh.Handler.Enabled(ctx, level)
+}
+
+func (lh *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: lh.Handler.WithAttrs(attrs), level: lh.level}
+}
+
+func (lh *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: lh.Handler.WithGroup(name), level: lh.level}
+}
+
+// plainHandler writes human-oriented lines: the message prefixed by its
+// level, followed by any attributes other than the command name.
+type plainHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Level
+	attrs  []slog.Attr
+	prefix string
+}
+
+func newPlainHandler(w io.Writer, level slog.Level) *plainHandler {
+	return &plainHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (ph *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= ph.level
+}
+
+func (ph *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+
+	switch {
+	case r.Level >= slog.LevelError:
+		sb.WriteString("error: ")
+	case r.Level >= slog.LevelWarn:
+		sb.WriteString("warning: ")
+	case r.Level < slog.LevelInfo:
+		sb.WriteString("debug: ")
+	}
+
+	sb.WriteString(r.Message)
+
+	write := func(a slog.Attr) {
+		if a.Key == "command" && ph.prefix == "" {
+			return
+		}
+
+		fmt.Fprintf(&sb, " %s%s=%v", ph.prefix, a.Key, a.Value)
+	}
+
+	for _, a := range ph.attrs {
+		write(a)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		write(a)
+		return true
+	})
+
+	sb.WriteString("\n")
+
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	_, err := io.WriteString(ph.w, sb.String())
+	return err
+}
+
+func (ph *plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *ph
+	c.attrs = append(append([]slog.Attr{}, ph.attrs...), attrs...)
+	return &c
+}
+
+func (ph *plainHandler) WithGroup(name string) slog.Handler {
+	c := *ph
+	c.prefix = ph.prefix + name + "."
+	return &c
+}
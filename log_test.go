@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestCmdLog(t *testing.T) {
+	for _, n := range ciEnvVars {
+		t.Setenv(n, "")
+	}
+
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stderr = &out
+	app.LogFlags = true
+
+	app.AddCommand(NewCommand("sync", "test-group", "syncs", func(c *Command) {}, func(c *Command) error {
+		log := c.Log()
+		log.Debugf("debug %d", 1)
+		log.Infof("info %d", 2)
+		log.Warnf("warn %d", 3)
+		log.Errorf("error %d", 4)
+		return nil
+	}))
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"app", "sync"}, "info 2\nwarning: warn 3\nerror: error 4\n"},
+		{[]string{"app", "sync", "-v"}, "debug: debug 1\ninfo 2\nwarning: warn 3\nerror: error 4\n"},
+		{[]string{"app", "sync", "-v=false", "--quiet"}, "error: error 4\n"},
+	}
+
+	for i, tc := range testCases {
+		out.Reset()
+
+		if err := app.Run(tc.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if out.String() != tc.expected {
+			t.Fatalf("Unexpected output for test %d: %q", i, out.String())
+		}
+	}
+}
+
+func TestCmdLogHandler(t *testing.T) {
+	var out strings.Builder
+
+	app := NewApp()
+	app.LogFlags = true
+	app.LogHandler = slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	app.AddCommand(NewCommand("sync", "test-group", "syncs", func(c *Command) {}, func(c *Command) error {
+		c.Log().Debugf("hidden")
+		c.Log().Slog().Info("synced", "items", 3)
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "sync"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"msg":"synced","command":"sync","items":3`) {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
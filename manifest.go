@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Manifest describes commands that run external programs, so a thin
+// wrapper CLI can gain commands without being rebuilt.
+type Manifest struct {
+	Commands []ManifestCommand `json:"commands"`
+}
+
+// ManifestCommand runs Exec, split into words like a shell would, with the
+// command's positional args appended. Flag values are passed in the
+// environment as FLAG_<NAME>, e.g. --dry-run as FLAG_DRY_RUN.
+type ManifestCommand struct {
+	Name        string         `json:"name"`
+	Group       string         `json:"group"`
+	Description string         `json:"description"`
+	Exec        string         `json:"exec"`
+	Args        []ManifestArg  `json:"args"`
+	Flags       []ManifestFlag `json:"flags"`
+
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ManifestArg struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Variable    bool   `json:"variable"`
+}
+
+// ManifestFlag is a flag of Type string (the default), bool, int or
+// duration.
+type ManifestFlag struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+}
+
+// LoadManifest adds the commands described in the manifest at path. Files
+// ending in .json are JSON; anything else is read as YAML in the same
+// subset as the config file, with commands keyed by name:
+//
+//	commands:
+//	  deploy:
+//	    description: Deploy a service
+//	    exec: ./scripts/deploy.sh
+//	    args:
+//	      service: The service to deploy
+//	    flags:
+//	      force:
+//	        type: bool
+//	        description: Skip the health check
+//
+// A relative exec program starting with ./ or ../ is resolved against the
+// manifest's directory.
+func (app *App) LoadManifest(path string) error {
+	var m *Manifest
+	var err error
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		m, err = readJSONManifest(path)
+	} else {
+		m, err = readYAMLManifest(path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, mc := range m.Commands {
+		if err := mc.validate(); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		if _, ok := app.Commands[mc.Name]; ok {
+			return fmt.Errorf("%s: command %q already exists", path, mc.Name)
+		}
+	}
+
+	for _, mc := range m.Commands {
+		app.AddCommand(mc.command(filepath.Dir(path)))
+	}
+
+	return nil
+}
+
+func readJSONManifest(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return m, nil
+}
+
+func readYAMLManifest(path string) (*Manifest, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	cf, err := loadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+
+	for _, name := range cf.children("commands") {
+		key := "commands." + name
+		if _, ok := cf.sections[key]; !ok {
+			return nil, &ConfigErr{path, cf.values[key].line, fmt.Sprintf("command %s must be a section", name)}
+		}
+
+		mc := ManifestCommand{Name: name}
+		mc.Group, _ = cf.get(key + ".group")
+		mc.Description, _ = cf.get(key + ".description")
+		mc.Exec, _ = cf.get(key + ".exec")
+
+		for _, a := range cf.keys(key + ".annotations") {
+			if mc.Annotations == nil {
+				mc.Annotations = map[string]string{}
+			}
+
+			mc.Annotations[a], _ = cf.get(key + ".annotations." + a)
+		}
+
+		for _, a := range cf.children(key + ".args") {
+			ma := ManifestArg{Name: a}
+
+			if d, ok := cf.get(key + ".args." + a); ok {
+				ma.Description = d
+			} else {
+				ma.Description, _ = cf.get(key + ".args." + a + ".description")
+				v, _ := cf.get(key + ".args." + a + ".variable")
+				ma.Variable = v == "true"
+			}
+
+			mc.Args = append(mc.Args, ma)
+		}
+
+		for _, f := range cf.children(key + ".flags") {
+			mf := ManifestFlag{Name: f}
+
+			if d, ok := cf.get(key + ".flags." + f); ok {
+				mf.Description = d
+			} else {
+				fk := key + ".flags." + f
+				mf.Type, _ = cf.get(fk + ".type")
+				mf.Default, _ = cf.get(fk + ".default")
+				mf.Description, _ = cf.get(fk + ".description")
+			}
+
+			mc.Flags = append(mc.Flags, mf)
+		}
+
+		m.Commands = append(m.Commands, mc)
+	}
+
+	return m, nil
+}
+
+// children returns the names of the values and sections directly inside
+// section, in file order.
+func (cf *configFile) children(section string) []string {
+	prefix := section + "."
+	lines := map[string]int{}
+
+	for k, e := range cf.values {
+		if strings.HasPrefix(k, prefix) && !strings.Contains(k[len(prefix):], ".") {
+			lines[k[len(prefix):]] = e.line - 1
+		}
+	}
+
+	for k, i := range cf.sections {
+		if strings.HasPrefix(k, prefix) && !strings.Contains(k[len(prefix):], ".") {
+			lines[k[len(prefix):]] = i
+		}
+	}
+
+	names := make([]string, 0, len(lines))
+	for n := range lines {
+		names = append(names, n)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return lines[names[i]] < lines[names[j]]
+	})
+
+	return names
+}
+
+func (mc *ManifestCommand) validate() error {
+	if mc.Name == "" {
+		return fmt.Errorf("command without a name")
+	}
+
+	if strings.TrimSpace(mc.Exec) == "" {
+		return fmt.Errorf("command %s has no exec", mc.Name)
+	}
+
+	if _, err := splitArgs(mc.Exec); err != nil {
+		return fmt.Errorf("command %s: exec: %v", mc.Name, err)
+	}
+
+	for i, a := range mc.Args {
+		if a.Variable && i != len(mc.Args)-1 {
+			return fmt.Errorf("command %s: only the last arg can be variable", mc.Name)
+		}
+	}
+
+	for _, f := range mc.Flags {
+		if _, err := f.parse(f.Default); err != nil {
+			return fmt.Errorf("command %s: flag %s: %v", mc.Name, f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (mf *ManifestFlag) parse(v string) (interface{}, error) {
+	switch mf.Type {
+	case "", "string":
+		return v, nil
+	case "bool":
+		if v == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(v)
+	case "int":
+		if v == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(v)
+	case "duration":
+		if v == "" {
+			return time.Duration(0), nil
+		}
+		return time.ParseDuration(v)
+	}
+
+	return nil, fmt.Errorf("unknown type %q", mf.Type)
+}
+
+func (mc ManifestCommand) command(dir string) *Command {
+	setup := func(cmd *Command) {
+		for _, a := range mc.Args {
+			if a.Variable {
+				cmd.AppendVarArg(a.Name, a.Description)
+			} else {
+				cmd.AppendArg(a.Name, a.Description)
+			}
+		}
+
+		for _, f := range mc.Flags {
+			def, _ := f.parse(f.Default)
+
+			switch d := def.(type) {
+			case bool:
+				cmd.Flags.Bool(f.Name, d, f.Description)
+			case int:
+				cmd.Flags.Int(f.Name, d, f.Description)
+			case time.Duration:
+				cmd.Flags.Duration(f.Name, d, f.Description)
+			default:
+				cmd.Flags.String(f.Name, f.Default, f.Description)
+			}
+		}
+	}
+
+	run := func(cmd *Command) error {
+		argv, _ := splitArgs(mc.Exec)
+
+		if strings.HasPrefix(argv[0], "./") || strings.HasPrefix(argv[0], "../") {
+			argv[0] = filepath.Join(dir, argv[0])
+		}
+
+		argv = append(argv, cmd.Flags.Args()...)
+
+		c := exec.CommandContext(cmd.Context(), argv[0], argv[1:]...)
+		c.Stdin = cmd.stdin()
+		c.Stdout = cmd.stdout()
+		c.Stderr = cmd.stderr()
+		c.Env = cmd.Environ()
+
+		for _, f := range mc.Flags {
+			name := "FLAG_" + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+			c.Env = append(c.Env, name+"="+cmd.Flag(f.Name).String())
+		}
+
+		return c.Run()
+	}
+
+	cmd := NewCommand(mc.Name, mc.Group, mc.Description, setup, run)
+	cmd.Annotations = mc.Annotations
+
+	return cmd
+}
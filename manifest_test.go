@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+
+	dir := t.TempDir()
+
+	script := "#!/bin/sh\necho \"deploying $* to $FLAG_ENV force=$FLAG_FORCE\"\nexit $FLAG_CODE\n"
+	if err := os.WriteFile(filepath.Join(dir, "deploy.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := `commands:
+  deploy:
+    group: Ops
+    description: Deploy services
+    exec: ./deploy.sh --now
+    annotations:
+      requires-auth: "true"
+    args:
+      region: The region
+      services:
+        description: The services to deploy
+        variable: true
+    flags:
+      env:
+        default: staging
+        description: Target environment
+      force:
+        type: bool
+      code:
+        type: int
+`
+	path := filepath.Join(dir, "commands.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+
+	app := NewApp()
+	app.Stdout = &out
+
+	if err := app.LoadManifest(path); err != nil {
+		t.Fatal(err)
+	}
+
+	c := app.Commands["deploy"]
+	if c.Group != "Ops" || len(c.Args) != 2 || c.Args[0].Name != "region" || !c.Args[1].Variable || c.Annotations["requires-auth"] != "true" {
+		t.Fatalf("Unexpected command: %+v", c)
+	}
+
+	if err := app.Run([]string{"app", "deploy", "--force", "us", "web", "api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "deploying --now us web api to staging force=true\n"; out.String() != want {
+		t.Fatalf("Expected %q, got %q", want, out.String())
+	}
+
+	err := app.Run([]string{"app", "deploy", "--code=3", "us", "web"})
+	if ExitCode(err) != 3 {
+		t.Fatalf("Expected exit code 3, got %v", err)
+	}
+
+	if err := app.LoadManifest(path); err == nil {
+		t.Fatal("Expected an error redefining deploy")
+	}
+}
+
+func TestLoadManifestJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "commands.json")
+	manifest := `{"commands": [{"name": "hello", "exec": "echo hello", "flags": [{"name": "n", "type": "int", "default": "x"}]}]}`
+
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	if err := app.LoadManifest(path); err == nil || !strings.Contains(err.Error(), "flag n") {
+		t.Fatalf("Expected an invalid default error, got %v", err)
+	}
+
+	if _, ok := app.Commands["hello"]; ok {
+		t.Fatal("Expected no commands from an invalid manifest")
+	}
+}
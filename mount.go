@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+)
+
+// Mount exposes the commands of sub under prefix, so "app prefix cmd ..."
+// runs sub's cmd. Help and completion descend into sub, which shares the
+// App's streams, environment, config file and generated flags, and keeps
+// its state in subdirectories of the App's directories.
+func (app *App) Mount(prefix string, sub *App) {
+	app.checkFrozen("Mount")
+
+	cmd := NewCommand(prefix, "Commands", sub.Description, func(*Command) {}, nil)
+	cmd.mount = sub
+
+	sub.parent = app
+	sub.mountedAs = prefix
+
+	app.Commands[prefix] = cmd
+	cmd.app = app
+}
+
+// runMounted runs args, whose second element is the prefix of mounted, in
+// the mounted App.
+func (app *App) runMounted(ctx context.Context, mounted *Command, args []string) error {
+	sub := mounted.mount
+
+	mounted.once.Do(func() {
+		sub.LogFlags = sub.LogFlags || app.LogFlags
+		sub.OutputFlag = sub.OutputFlag || app.OutputFlag
+		sub.ColorFlag = sub.ColorFlag || app.ColorFlag
+		sub.TimeoutFlag = sub.TimeoutFlag || app.TimeoutFlag
+		sub.TimingsFlag = sub.TimingsFlag || app.TimingsFlag
+		sub.ProfileFlags = sub.ProfileFlags || app.ProfileFlags
+		sub.AssumeYes = sub.AssumeYes || app.AssumeYes
+		sub.HideAlpha = sub.HideAlpha || app.HideAlpha
+
+		if sub.Interactive == Auto {
+			sub.Interactive = app.Interactive
+		}
+
+		if sub.Color == Auto {
+			sub.Color = app.Color
+		}
+
+		if sub.DefaultOutput == "" {
+			sub.DefaultOutput = app.DefaultOutput
+		}
+
+		if sub.LogHandler == nil {
+			sub.LogHandler = app.LogHandler
+		}
+	})
+
+	return sub.RunContext(ctx, append([]string{sub.name()}, args[2:]...))
+}
+
+// completeMounted completes line, which starts with the prefix of a mounted
+// App, within that App.
+func completeMounted(ctx context.Context, sub *App, prefix, line string) []string {
+	rest := strings.TrimLeft(line, " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, prefix), " \t")
+
+	return sub.completeLine(ctx, rest)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	var out strings.Builder
+
+	db := NewApp()
+	db.Description = "Database tools"
+	db.AddCommand(NewCommand("migrate", "db", "Run migrations", func(c *Command) {
+		c.AppendArg("target", "version to migrate to")
+	}, func(c *Command) error {
+		out.WriteString("migrating to " + c.Arg("target").String())
+		return nil
+	}))
+
+	app := NewApp()
+	app.Name = "platform"
+	app.Stdout = &out
+	app.TimingsFlag = true
+	app.DataDir = t.TempDir()
+	app.Mount("db", db)
+
+	if err := app.Run([]string{"platform", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "Database tools") {
+		t.Fatalf("Expected the mount in the listing:\n%s", out.String())
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"platform", "db", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "usage: platform db cmd") || !strings.Contains(out.String(), "Run migrations") {
+		t.Fatalf("Unexpected mounted help:\n%s", out.String())
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"platform", "db", "migrate", "--timings=false", "v2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "migrating to v2" {
+		t.Fatalf("Unexpected output: %q", out.String())
+	}
+
+	if got := app.completeLine(context.Background(), "db mi"); !reflect.DeepEqual(got, []string{"migrate"}) {
+		t.Fatalf("Unexpected completions: %v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// negatable returns the bool flag that --no-<name> turns off, if name is
+// of that form and no flag is called name itself.
+func (cmd *Command) negatable(name string) *flag.Flag {
+	if !strings.HasPrefix(name, "no-") || cmd.Flags.Lookup(name) != nil {
+		return nil
+	}
+
+	f := cmd.Flags.Lookup(name[3:])
+	if f == nil || !isBoolFlag(f) || f.DefValue != "true" {
+		return nil
+	}
+
+	return f
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// negateFlags rewrites --no-<name> in args to --<name>=false for bool flags
+// defaulting to true.
+func (cmd *Command) negateFlags(args []string) []string {
+	ret := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" || len(a) < 2 || a[0] != '-' {
+			return append(ret, args[i:]...)
+		}
+
+		name := strings.TrimLeft(a, "-")
+		name, v, hasValue := strings.Cut(name, "=")
+
+		if f := cmd.negatable(name); f != nil {
+			b, err := strconv.ParseBool(v)
+			if !hasValue || err == nil {
+				a = "--" + f.Name + "=" + strconv.FormatBool(hasValue && !b)
+			}
+		} else if f := cmd.Flags.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) && i+1 < len(args) {
+			ret = append(ret, a)
+			i++
+			a = args[i]
+		}
+
+		ret = append(ret, a)
+	}
+
+	return ret
+}
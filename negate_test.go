@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCmdNegatableFlags(t *testing.T) {
+	var cache bool
+	var name string
+
+	app := NewApp()
+	app.AddCommand(NewCommand("build", "test-group", "builds", func(c *Command) {
+		c.Flags.Bool("cache", true, "Use the build cache")
+		c.Flags.Bool("force", false, "Rebuild everything")
+		c.Flags.String("name", "", "The image name")
+	}, func(c *Command) error {
+		cache, _ = c.Flag("cache").Bool()
+		name = c.Flag("name").String()
+		return nil
+	}))
+
+	testCases := []struct {
+		args  []string
+		cache bool
+		name  string
+	}{
+		{[]string{}, true, ""},
+		{[]string{"--no-cache"}, false, ""},
+		{[]string{"-no-cache=false"}, true, ""},
+		{[]string{"--name", "--no-cache", "--cache"}, true, "--no-cache"},
+		{[]string{"--name=x", "--no-cache"}, false, "x"},
+	}
+
+	for _, tc := range testCases {
+		if err := app.Run(append([]string{"app", "build"}, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+
+		if cache != tc.cache || name != tc.name {
+			t.Fatalf("Expected cache=%v name=%q for %q, got %v %q", tc.cache, tc.name, tc.args, cache, name)
+		}
+	}
+
+	if c := app.completeLine(context.Background(), "build --no"); !reflect.DeepEqual(c, []string{"--no-cache"}) {
+		t.Fatalf("Unexpected completions %v", c)
+	}
+
+	cmd, _ := app.command("build")
+	if _, err := cmd.ParseStrict([]string{"--no-force"}); err == nil {
+		t.Fatal("Expected --no-force to be unknown")
+	}
+
+	var out strings.Builder
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	if !strings.Contains(out.String(), "[no-]cache: Use the build cache") || strings.Contains(out.String(), "[no-]force") {
+		t.Fatalf("Unexpected usage:\n%s", out.String())
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// HostPort splits v, like "db.internal:5432" or "[::1]:80", into its host
+// and port. The host may be empty, as in ":8080".
+func (v Value) HostPort() (string, int, error) {
+	host, p, err := net.SplitHostPort(string(v))
+	if err != nil {
+		return "", 0, errors.New("must be host:port")
+	}
+
+	port, err := strconv.Atoi(p)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("port %s must be a number from 1 to 65535", p)
+	}
+
+	return host, port, nil
+}
+
+// CIDR parses v as a network like "10.0.0.0/8" or "fd00::/8".
+func (v Value) CIDR() (*net.IPNet, error) {
+	_, n, err := net.ParseCIDR(string(v))
+	if err != nil {
+		return nil, errors.New("must be a CIDR block like 10.0.0.0/8")
+	}
+
+	return n, nil
+}
+
+// MAC parses v as a hardware address like "00:1a:2b:3c:4d:5e".
+func (v Value) MAC() (net.HardwareAddr, error) {
+	mac, err := net.ParseMAC(string(v))
+	if err != nil {
+		return nil, errors.New("must be a MAC address like 00:1a:2b:3c:4d:5e")
+	}
+
+	return mac, nil
+}
+
+// IsHostPort is a Validator for values accepted by Value.HostPort.
+func IsHostPort(v Value) error {
+	_, _, err := v.HostPort()
+	return err
+}
+
+// IsCIDR is a Validator for values accepted by Value.CIDR.
+func IsCIDR(v Value) error {
+	_, err := v.CIDR()
+	return err
+}
+
+// IsMAC is a Validator for values accepted by Value.MAC.
+func IsMAC(v Value) error {
+	_, err := v.MAC()
+	return err
+}
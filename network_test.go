@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValueNetwork(t *testing.T) {
+	host, port, err := Value("[::1]:8080").HostPort()
+	if err != nil || host != "::1" || port != 8080 {
+		t.Fatalf("Unexpected %q %d %v", host, port, err)
+	}
+
+	n, err := Value("10.1.2.3/8").CIDR()
+	if err != nil || n.String() != "10.0.0.0/8" {
+		t.Fatalf("Unexpected %v %v", n, err)
+	}
+
+	mac, err := Value("00-1A-2B-3C-4D-5E").MAC()
+	if err != nil || mac.String() != "00:1a:2b:3c:4d:5e" {
+		t.Fatalf("Unexpected %v %v", mac, err)
+	}
+
+	testCases := []struct {
+		v   Validator
+		in  Value
+		err string
+	}{
+		{IsHostPort, ":443", ""},
+		{IsHostPort, "db.internal", "must be host:port"},
+		{IsHostPort, "db.internal:http", "port http must be a number from 1 to 65535"},
+		{IsHostPort, "db.internal:70000", "port 70000 must be a number from 1 to 65535"},
+		{IsCIDR, "fd00::/8", ""},
+		{IsCIDR, "10.0.0.0", "must be a CIDR block like 10.0.0.0/8"},
+		{IsMAC, "00:1a:2b", "must be a MAC address like 00:1a:2b:3c:4d:5e"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.v(tc.in)
+
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || err.Error() != tc.err) {
+			t.Fatalf("Expected %q for %q, got %v", tc.err, tc.in, err)
+		}
+	}
+}
+
+func TestCmdValidateNetwork(t *testing.T) {
+	app := NewApp()
+	app.AddCommand(NewCommand("connect", "test-group", "connects", func(c *Command) {
+		c.AppendArg("addr", "the server")
+		c.ValidateArg("addr", IsHostPort)
+	}, func(c *Command) error { return nil }))
+
+	err := app.Run([]string{"app", "connect", "db.internal"})
+	if msg := `Invalid value "db.internal" for addr: must be host:port`; !errors.Is(err, ErrInvalidValue) || err.Error() != msg {
+		t.Fatalf("Expected %q, got %v", msg, err)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type nodeKind int
+
+const (
+	nodeScalar nodeKind = iota
+	nodeObject
+	nodeArray
+)
+
+// node is a JSON value that keeps object keys in their encoded order, so
+// struct fields render in declaration order in every output format.
+type node struct {
+	kind   nodeKind
+	keys   []string
+	values []*node
+	scalar interface{}
+}
+
+func toNode(v interface{}) (*node, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	return decodeNode(dec)
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		n := &node{kind: nodeObject}
+
+		for dec.More() {
+			kt, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			n.keys = append(n.keys, kt.(string))
+			n.values = append(n.values, v)
+		}
+
+		_, err := dec.Token()
+		return n, err
+	case json.Delim('['):
+		n := &node{kind: nodeArray}
+
+		for dec.More() {
+			v, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			n.values = append(n.values, v)
+		}
+
+		_, err := dec.Token()
+		return n, err
+	}
+
+	return &node{kind: nodeScalar, scalar: tok}, nil
+}
+
+func (n *node) get(key string) *node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.values[i]
+		}
+	}
+
+	return nil
+}
+
+// text renders n on a single line: scalars as plain text and collections as
+// compact JSON.
+func (n *node) text() string {
+	if n == nil {
+		return ""
+	}
+
+	switch n.kind {
+	case nodeScalar:
+		if n.scalar == nil {
+			return ""
+		}
+
+		return fmt.Sprint(n.scalar)
+	}
+
+	var buf bytes.Buffer
+	n.writeJSON(&buf)
+
+	return buf.String()
+}
+
+func (n *node) writeJSON(w io.Writer) {
+	switch n.kind {
+	case nodeObject:
+		io.WriteString(w, "{")
+
+		for i, k := range n.keys {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+
+			kb, _ := json.Marshal(k)
+			w.Write(kb)
+			io.WriteString(w, ":")
+			n.values[i].writeJSON(w)
+		}
+
+		io.WriteString(w, "}")
+	case nodeArray:
+		io.WriteString(w, "[")
+
+		for i, v := range n.values {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+
+			v.writeJSON(w)
+		}
+
+		io.WriteString(w, "]")
+	default:
+		b, _ := json.Marshal(n.scalar)
+		w.Write(b)
+	}
+}
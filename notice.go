@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	defaultNoticeInterval = 24 * time.Hour
+	noticeTimeout         = 5 * time.Second
+)
+
+type versionCheck struct {
+	Checked time.Time `json:"checked"`
+	Latest  string    `json:"latest"`
+}
+
+// EnableUpdateNotice checks source for a newer release at most once a day,
+// or App.UpdateNoticeInterval, in the background while commands run, and
+// prints a notice to stderr after a command finishes when one is available.
+// Results are cached in the app's cache dir, so a check that does not finish
+// in time is used by a later run.
+func (app *App) EnableUpdateNotice(source ReleaseSource) {
+	app.checkFrozen("EnableUpdateNotice")
+
+	app.notice = &updateNotice{source: source}
+}
+
+type updateNotice struct {
+	source ReleaseSource
+	done   chan versionCheck
+}
+
+func (app *App) versionCheckPath() string {
+	return filepath.Join(app.cacheDir(), "version-check.json")
+}
+
+func (app *App) loadVersionCheck() versionCheck {
+	var vc versionCheck
+
+	if b, err := os.ReadFile(app.versionCheckPath()); err == nil {
+		json.Unmarshal(b, &vc)
+	}
+
+	return vc
+}
+
+// startVersionCheck starts a background check if the cached one is stale.
+func (app *App) startVersionCheck() {
+	n := app.notice
+	if n == nil {
+		return
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	interval := app.UpdateNoticeInterval
+	if interval <= 0 {
+		interval = defaultNoticeInterval
+	}
+
+	if n.done != nil || time.Since(app.loadVersionCheck().Checked) < interval {
+		return
+	}
+
+	done := make(chan versionCheck, 1)
+	n.done = done
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), noticeTimeout)
+		defer cancel()
+
+		vc := versionCheck{Checked: time.Now().UTC()}
+		if r, err := n.source.Latest(ctx, http.DefaultClient); err == nil {
+			vc.Latest = r.Version
+		} else {
+			vc.Latest = app.loadVersionCheck().Latest
+		}
+
+		if b, err := json.Marshal(vc); err == nil && os.MkdirAll(app.cacheDir(), 0700) == nil {
+			os.WriteFile(app.versionCheckPath(), b, 0600)
+		}
+
+		done <- vc
+	}()
+}
+
+// printUpdateNotice prints a notice after cmd if a newer version is known,
+// without waiting for a check still in progress.
+func (app *App) printUpdateNotice(cmd *Command) {
+	n := app.notice
+	if n == nil || cmd.Name == "update" || app.isCI() {
+		return
+	}
+
+	var vc versionCheck
+
+	app.mu.Lock()
+	select {
+	case vc = <-n.done:
+		n.done = nil
+	default:
+		vc = app.loadVersionCheck()
+	}
+	app.mu.Unlock()
+
+	if vc.Latest == "" {
+		return
+	}
+
+	if newer, err := compareVersions(vc.Latest, app.Version); err == nil && newer > 0 {
+		fmt.Fprintf(cmd.stderr(), "\n%s\n", app.trf("A new version of %s is available: %s (current %s)", app.name(), vc.Latest, app.Version))
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testReleaseSource struct {
+	version string
+	calls   int
+	release chan struct{}
+}
+
+func (rs *testReleaseSource) Latest(ctx context.Context, client *http.Client) (*Release, error) {
+	rs.calls++
+	<-rs.release
+
+	if rs.version == "" {
+		return nil, errors.New("offline")
+	}
+
+	return &Release{Version: rs.version}, nil
+}
+
+func TestUpdateNotice(t *testing.T) {
+	for _, n := range ciEnvVars {
+		t.Setenv(n, "")
+	}
+
+	var errOut strings.Builder
+	source := &testReleaseSource{version: "2.0.0", release: make(chan struct{})}
+
+	app := NewApp()
+	app.Name = "tool"
+	app.Version = "1.0.0"
+	app.CacheDir = t.TempDir()
+	app.Stderr = &errOut
+	app.EnableUpdateNotice(source)
+
+	app.AddCommand(NewCommand("work", "test-group", "works", func(c *Command) {}, func(c *Command) error {
+		return nil
+	}))
+
+	// The check is still running when the command finishes
+	if err := app.Run([]string{"tool", "work"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if errOut.Len() != 0 {
+		t.Fatalf("Expected no notice before the check is done, got %q", errOut.String())
+	}
+
+	close(source.release)
+	<-app.notice.done
+	app.notice.done = nil
+
+	for i := 0; i < 2; i++ {
+		if err := app.Run([]string{"tool", "work"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if source.calls != 1 {
+		t.Fatalf("Expected one check a day, got %d", source.calls)
+	}
+
+	if strings.Count(errOut.String(), "A new version of tool is available: 2.0.0 (current 1.0.0)\n") != 2 {
+		t.Fatalf("Unexpected notice %q", errOut.String())
+	}
+}
+
+func TestUpdateNoticeInterval(t *testing.T) {
+	source := &testReleaseSource{version: "2.0.0", release: make(chan struct{})}
+	close(source.release)
+
+	app := NewApp()
+	app.Version = "1.0.0"
+	app.CacheDir = t.TempDir()
+	app.Stderr = io.Discard
+	app.UpdateNoticeInterval = time.Nanosecond
+	app.EnableUpdateNotice(source)
+
+	app.AddCommand(NewCommand("work", "test-group", "works", func(c *Command) {}, func(c *Command) error {
+		return nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		if err := app.Run([]string{"app", "work"}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The notice takes the result if the check is already done
+		app.mu.Lock()
+		done := app.notice.done
+		app.notice.done = nil
+		app.mu.Unlock()
+
+		if done != nil {
+			<-done
+		}
+	}
+
+	if source.calls != 2 {
+		t.Fatalf("Expected a check on every run, got %d", source.calls)
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// RunEnv gives a run its own streams, environment and working directory in
+// place of the App's, so that concurrent runs of one App can each have their
+// own. Unset fields fall back to the App's. Settings the App reads for
+// itself, such as its locale, color and data directories, still come from
+// App.Getenv.
+type RunEnv struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Getenv func(string) string
+
+	// Dir is the directory relative paths given to the run are resolved
+	// against, in place of the process's working directory.
+	Dir string
+}
+
+// runEnvKey holds the RunEnv of a run.
+type runEnvKey struct{}
+
+// WithRunEnv returns a copy of ctx for running commands with env, e.g.
+// app.RunContext(cmd.WithRunEnv(ctx, env), args).
+func WithRunEnv(ctx context.Context, env *RunEnv) context.Context {
+	return context.WithValue(ctx, runEnvKey{}, env)
+}
+
+func runEnvOf(ctx context.Context) *RunEnv {
+	if env, ok := ctx.Value(runEnvKey{}).(*RunEnv); ok && env != nil {
+		return env
+	}
+
+	return &RunEnv{}
+}
+
+func (app *App) stdin() io.Reader {
+	if app != nil && app.Stdin != nil {
+		return app.Stdin
+	}
+
+	if app != nil && app.parent != nil {
+		return app.parent.stdin()
+	}
+
+	return os.Stdin
+}
+
+func (app *App) stdout() io.Writer {
+	if app != nil && app.Stdout != nil {
+		return app.Stdout
+	}
+
+	if app != nil && app.parent != nil {
+		return app.parent.stdout()
+	}
+
+	return os.Stdout
+}
+
+func (app *App) stderr() io.Writer {
+	if app != nil && app.Stderr != nil {
+		return app.Stderr
+	}
+
+	if app != nil && app.parent != nil {
+		return app.parent.stderr()
+	}
+
+	return os.Stderr
+}
+
+// stdinIn, stdoutIn, stderrIn and getenvIn are the App's, unless the run in
+// ctx has its own.
+func (app *App) stdinIn(ctx context.Context) io.Reader {
+	if env := runEnvOf(ctx); env.Stdin != nil {
+		return env.Stdin
+	}
+
+	return app.stdin()
+}
+
+func (app *App) stdoutIn(ctx context.Context) io.Writer {
+	if env := runEnvOf(ctx); env.Stdout != nil {
+		return env.Stdout
+	}
+
+	return app.stdout()
+}
+
+func (app *App) stderrIn(ctx context.Context) io.Writer {
+	if env := runEnvOf(ctx); env.Stderr != nil {
+		return env.Stderr
+	}
+
+	return app.stderr()
+}
+
+func (app *App) getenvIn(ctx context.Context, name string) string {
+	if env := runEnvOf(ctx); env.Getenv != nil {
+		return env.Getenv(name)
+	}
+
+	return app.getenv(name)
+}
+
+func (cmd *Command) stdin() io.Reader {
+	if cmd.Stdin != nil {
+		return cmd.Stdin
+	}
+
+	return cmd.app.stdinIn(cmd.Context())
+}
+
+func (cmd *Command) stdout() io.Writer {
+	if cmd.Stdout != nil {
+		return cmd.Stdout
+	}
+
+	return cmd.app.stdoutIn(cmd.Context())
+}
+
+func (cmd *Command) stderr() io.Writer {
+	if cmd.Stderr != nil {
+		return cmd.Stderr
+	}
+
+	return cmd.app.stderrIn(cmd.Context())
+}
+
+// bindOutput fills in the command's unset streams from the App for the
+// duration of a run, returning a func that undoes it.
+func (cmd *Command) bindOutput() func() {
+	stdin, stdout, stderr := cmd.Stdin, cmd.Stdout, cmd.Stderr
+
+	cmd.Stdin = cmd.stdin()
+	cmd.Stdout = cmd.stdout()
+	cmd.Stderr = cmd.stderr()
+
+	return func() {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	}
+}
+
+// rawTerminal puts r into raw mode when it is a terminal file.
+func rawTerminal(r io.Reader) (func(), error) {
+	f, ok := r.(*os.File)
+	if !ok {
+		return nil, ErrNotInteractive
+	}
+
+	return makeRaw(f)
+}
+
+func (app *App) getenv(name string) string {
+	if app != nil && app.Getenv != nil {
+		return app.Getenv(name)
+	}
+
+	if app != nil && app.parent != nil {
+		return app.parent.getenv(name)
+	}
+
+	return os.Getenv(name)
+}
+
+// setenv sets name for the rest of the run, without touching the process
+// environment.
+func (cmd *Command) setenv(name, v string) {
+	if cmd.envOverlay == nil {
+		cmd.envOverlay = map[string]string{}
+	}
+
+	cmd.envOverlay[name] = v
+}
+
+func (cmd *Command) getenv(name string) string {
+	if v, ok := cmd.envOverlay[name]; ok {
+		return v
+	}
+
+	if cmd.ExecEnv != nil {
+		if v, ok := cmd.ExecEnv.getenv(name, cmd); ok {
+			return v
+		}
+	}
+
+	if cmd.Getenv != nil {
+		return cmd.Getenv(name)
+	}
+
+	return cmd.app.getenvIn(cmd.Context(), name)
+}
+
+// Dir returns the working directory of the run, which relative paths given
+// to the command are resolved against: the RunEnv's Dir, or "." for the
+// process's working directory.
+func (cmd *Command) Dir() string {
+	return dirIn(cmd.Context())
+}
+
+func dirIn(ctx context.Context) string {
+	if env := runEnvOf(ctx); env.Dir != "" {
+		return env.Dir
+	}
+
+	return "."
+}
+
+// inDir resolves path against dir, leaving absolute paths as they are.
+func inDir(dir, path string) string {
+	if dir == "." || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
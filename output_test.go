@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, f := range []string{"a.log", "b.log", "input.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var appOut bytes.Buffer
+	var files []string
+
+	app := NewApp()
+	app.Stdout = &appOut
+	app.Stderr = &appOut
+	app.Getenv = func(string) string { return "" }
+
+	app.AddCommand(NewCommand("read", "test-group", "reads things", func(c *Command) {
+		c.AppendFileArg("input", "the input", PathOptions{Readable: true})
+		c.AppendGlobVarArg("files", "the files")
+		c.AddEnvArg("TOKEN", "a token")
+	}, func(c *Command) error {
+		r, err := c.ArgReader("input")
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		in, err := io.ReadAll(c.Stdin)
+		if err != nil {
+			return err
+		}
+
+		files = c.Flags.Args()[1:]
+		_, err = c.Stdout.Write(append(append(b, ' '), in...))
+		c.Stderr.Write([]byte(c.EnvArg("TOKEN")))
+
+		return err
+	}))
+
+	var stdout, stderr bytes.Buffer
+	env := &RunEnv{
+		Stdin:  strings.NewReader("piped"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+		Getenv: func(name string) string { return map[string]string{"TOKEN": "abc"}[name] },
+		Dir:    dir,
+	}
+	ctx := WithRunEnv(context.Background(), env)
+
+	if err := app.RunContext(ctx, []string{"app", "read", "input.txt", "*.log"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout.String() != "input.txt piped" || stderr.String() != "abc" {
+		t.Fatalf("Unexpected output %q and %q", stdout.String(), stderr.String())
+	}
+
+	if expected := []string{"a.log", "b.log"}; !reflect.DeepEqual(files, expected) {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+
+	stdout.Reset()
+	if err := app.RunContext(ctx, []string{"app", "--help"}); err != nil || !strings.Contains(stdout.String(), "read") {
+		t.Fatalf("Expected usage in the run's stdout, got %v and %q", err, stdout.String())
+	}
+
+	if err := app.RunContext(ctx, []string{"app", "read", "missing.txt", "*.log"}); !errors.Is(err, ErrInvalidValue) || err.Error() != "missing.txt: no such file" {
+		t.Fatalf("Expected a missing file error, got %v", err)
+	}
+
+	if appOut.Len() != 0 {
+		t.Fatalf("Expected nothing in the App's streams, got %q", appOut.String())
+	}
+
+	if err := app.Run([]string{"app", "read", "input.txt", "*.log"}); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("Expected the App's own runs to use the working directory, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+)
+
+// EnablePalette registers a find command that lets the user search for a
+// command interactively, fill in its arguments and run it. The palette also
+// opens when the app is started without arguments on a terminal.
+func (app *App) EnablePalette() {
+	app.palette = true
+
+	app.AddCommand(NewCommand("find", "Shell", "Search for a command and run it",
+		func(cmd *Command) {},
+		func(cmd *Command) error {
+			return app.runPalette(cmd.Context())
+		}))
+}
+
+func (app *App) paletteCommands(ctx context.Context) []*Command {
+	cmds := []*Command{}
+
+	for _, cmd := range app.Commands {
+		if app.listed(ctx, cmd) && cmd.Name != "find" {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Name < cmds[j].Name
+	})
+
+	return cmds
+}
+
+func (app *App) runPalette(ctx context.Context) error {
+	if !app.interactive(ctx) {
+		return ErrNotInteractive
+	}
+
+	cmds := app.paletteCommands(ctx)
+
+	names := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		names[i] = cmd.Name
+	}
+
+	width := nameColumn(names)
+
+	options := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		options[i] = padRight(cmd.Name, width) + " " + cmd.description()
+	}
+
+	p := newPicker(app.tr("Command"), options, false)
+	p.app = app
+	p.plain = app.plainIn(ctx)
+	p.score = func(i int, filter string) (int, bool) {
+		return cmds[i].matchScore(filter)
+	}
+
+	idx, err := p.run(app.stdinIn(ctx), app.stderrIn(ctx))
+	if err != nil {
+		return err
+	}
+
+	cmd, _ := app.command(cmds[idx[0]].Name)
+
+	ctx, release := hold(ctx, cmd)
+	defer release()
+
+	app.prepare(cmd)
+
+	args, err := cmd.promptMissing([]string{})
+	if err != nil {
+		return err
+	}
+
+	return app.RunContext(ctx, append([]string{app.name(), cmd.Name}, args...))
+}
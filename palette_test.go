@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPaletteMatching(t *testing.T) {
+	app := NewApp()
+	app.EnablePalette()
+
+	rollout := NewCommand("rollout", "test-group", "Gradually ship traffic", func(c *Command) {}, nil)
+	rollout.Keywords = []string{"canary"}
+
+	app.AddCommand(NewCommand("deploy", "test-group", "Ship a release", func(c *Command) {}, nil))
+	app.AddCommand(rollout)
+
+	cmds := app.paletteCommands(context.Background())
+	if len(cmds) != 2 || cmds[0].Name != "deploy" || cmds[1].Name != "rollout" {
+		t.Fatalf("Unexpected palette commands %v", cmds)
+	}
+
+	p := newPicker("Command", []string{"deploy", "rollout"}, false)
+	p.score = func(i int, filter string) (int, bool) {
+		return cmds[i].matchScore(filter)
+	}
+
+	for _, r := range "canary" {
+		p.handle(keypress{kind: keyRune, r: r})
+	}
+
+	if vis := p.visible(); len(vis) != 1 || vis[0] != 1 {
+		t.Fatalf("Expected the keyword to match rollout, got %v", vis)
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"time"
+)
+
+// ParseResult is the outcome of ParseStrict.
+type ParseResult struct {
+	// Flags holds the value of every flag after parsing, including those
+	// left at their defaults.
+	Flags map[string]string
+
+	// Set lists the flags given on the command line, in order.
+	Set []string
+
+	// Args maps positional argument names to their values. VarArgs holds
+	// the values of a trailing variable argument.
+	Args    map[string]string
+	VarArgs []string
+}
+
+// ParseStrict parses args like Parse but without changing the command: its
+// flags are left untouched, nothing is printed and bad flags are returned as
+// a *UsageErr. Environment variables are not checked. Like Parse, it reads
+// the filesystem to expand glob args and check path args.
+func (cmd *Command) ParseStrict(args []string) (*ParseResult, error) {
+	fs := cloneFlags(cmd.Flags)
+
+	if err := fs.Parse(cmd.negateFlags(args)); err != nil {
+		return nil, cmd.usageErr(err.Error(), err)
+	}
+
+	if err := cmd.expandGlobs(fs); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.checkArgCount(fs.NArg()); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.checkValues(fs); err != nil {
+		return nil, err
+	}
+
+	res := &ParseResult{
+		Flags:   map[string]string{},
+		Args:    map[string]string{},
+		VarArgs: []string{},
+	}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		res.Flags[f.Name] = f.Value.String()
+	})
+
+	fs.Visit(func(f *flag.Flag) {
+		res.Set = append(res.Set, f.Name)
+	})
+
+	for i, a := range cmd.Args {
+		if a.Variable {
+			res.VarArgs = append(res.VarArgs, fs.Args()[i:]...)
+			break
+		}
+
+		res.Args[a.Name] = fs.Arg(i)
+	}
+
+	return res, nil
+}
+
+func (cmd *Command) checkArgCount(n int) error {
+	varArgs := false
+	for _, arg := range cmd.Args {
+		if arg.Variable {
+			varArgs = true
+			break
+		}
+	}
+
+	if !varArgs && n != len(cmd.Args) {
+		return cmd.usageErr("Wrong number of command arguments", ErrArgCount)
+	} else if varArgs && n < len(cmd.Args) {
+		return cmd.usageErr("Wrong number of command arguments", ErrArgCount)
+	}
+
+	return nil
+}
+
+// cloneFlags returns a silent, non-exiting copy of fs whose flags have their
+// own values, reset to their defaults.
+func cloneFlags(fs *flag.FlagSet) *flag.FlagSet {
+	clone := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	clone.SetOutput(io.Discard)
+	clone.Usage = func() {}
+
+	fs.VisitAll(func(f *flag.Flag) {
+		clone.Var(cloneValue(f), f.Name, f.Usage)
+		clone.Lookup(f.Name).DefValue = f.DefValue
+	})
+
+	return clone
+}
+
+// resetValue returns v to its default def. Values that add to themselves
+// when set, like list flags, reset through their own hook.
+func resetValue(v flag.Value, def string) {
+	if r, ok := v.(interface{ reset() }); ok {
+		r.reset()
+		return
+	}
+
+	v.Set(def)
+}
+
+func cloneValue(f *flag.Flag) flag.Value {
+	if c, ok := f.Value.(interface{ clone() flag.Value }); ok {
+		v := c.clone()
+		resetValue(v, f.DefValue)
+		return v
+	}
+
+	t := reflect.TypeOf(f.Value)
+	isPtr := t.Kind() == reflect.Ptr
+
+	// Value types outside the flag package that are a named bool, number or
+	// string, like those usually given to flag.Var, get a fresh value of
+	// their own type so their Set still validates
+	if isPtr && t.Elem().PkgPath() != "flag" && isBasicKind(t.Elem().Kind()) {
+		v := reflect.New(t.Elem()).Interface().(flag.Value)
+		v.Set(f.DefValue)
+		return v
+	}
+
+	scratch := flag.NewFlagSet("", flag.ContinueOnError)
+
+	if g, ok := f.Value.(flag.Getter); ok && isPtr && t.Elem().PkgPath() == "flag" {
+		switch g.Get().(type) {
+		case bool:
+			scratch.Bool(f.Name, false, "")
+		case int:
+			scratch.Int(f.Name, 0, "")
+		case int64:
+			scratch.Int64(f.Name, 0, "")
+		case uint:
+			scratch.Uint(f.Name, 0, "")
+		case uint64:
+			scratch.Uint64(f.Name, 0, "")
+		case float64:
+			scratch.Float64(f.Name, 0, "")
+		case string:
+			scratch.String(f.Name, "", "")
+		case time.Duration:
+			scratch.Duration(f.Name, 0, "")
+		}
+	}
+
+	v := flag.Value(&rawValue{})
+	if sf := scratch.Lookup(f.Name); sf != nil {
+		v = sf.Value
+	} else if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+		v = &rawValue{isBool: true}
+	}
+
+	v.Set(f.DefValue)
+
+	return v
+}
+
+func isBasicKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// rawValue stands in for flag values of unknown types, keeping the string it
+// was set to.
+type rawValue struct {
+	s      string
+	isBool bool
+}
+
+func (rv *rawValue) String() string {
+	return rv.s
+}
+
+func (rv *rawValue) Set(s string) error {
+	rv.s = s
+	return nil
+}
+
+func (rv *rawValue) IsBoolFlag() bool {
+	return rv.isBool
+}
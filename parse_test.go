@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newStrictCommand() *Command {
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {}, nil)
+	c.Flags.Bool("force", false, "force it")
+	c.Flags.Int("count", 1, "how many")
+	c.Flags.Var(new(toggleValue), "color", "color output")
+	c.AppendArg("target", "the target")
+	c.AppendVarArg("files", "the files")
+
+	return c
+}
+
+func TestParseStrict(t *testing.T) {
+	c := newStrictCommand()
+
+	res, err := c.ParseStrict([]string{"--force", "--count", "3", "--color=never", "web", "a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &ParseResult{
+		Flags:   map[string]string{"force": "true", "count": "3", "color": "never"},
+		Set:     []string{"color", "count", "force"},
+		Args:    map[string]string{"target": "web"},
+		VarArgs: []string{"a", "b"},
+	}
+
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, res)
+	}
+
+	if c.Flag("force") != "false" || c.Flag("count") != "1" {
+		t.Fatal("ParseStrict changed the command's flags")
+	}
+
+	var ue *UsageErr
+
+	if _, err := c.ParseStrict([]string{"--count", "many", "web", "a"}); !errors.As(err, &ue) {
+		t.Fatalf("Expected a usage error for a bad flag, got %v", err)
+	}
+
+	if _, err := c.ParseStrict([]string{"--color=bogus", "web", "a"}); !errors.As(err, &ue) {
+		t.Fatalf("Expected a usage error for a bad color, got %v", err)
+	}
+
+	if _, err := c.ParseStrict([]string{"--nope", "web", "a"}); !errors.As(err, &ue) {
+		t.Fatalf("Expected a usage error for an unknown flag, got %v", err)
+	}
+
+	if _, err := c.ParseStrict([]string{"web"}); !errors.Is(err, ErrArgCount) {
+		t.Fatalf("Expected ErrArgCount, got %v", err)
+	}
+}
+
+func TestVarArgsWithoutArgs(t *testing.T) {
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {}, nil)
+
+	if len(c.VarArgs()) != 0 {
+		t.Fatal("Expected no var args")
+	}
+
+	c.AppendArg("a", "an arg")
+	c.AppendVarArg("b", "more args")
+
+	if len(c.VarArgs()) != 0 {
+		t.Fatal("Expected no var args before parsing")
+	}
+}
+
+func FuzzParseStrict(f *testing.F) {
+	f.Add("--force\x00web\x00a")
+	f.Add("--count=2\x00--\x00-x")
+	f.Add("-color\x00always\x00web")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, joined string) {
+		args := strings.Split(joined, "\x00")
+
+		res, err := newStrictCommand().ParseStrict(args)
+		if err != nil {
+			return
+		}
+
+		if _, ok := res.Args["target"]; !ok {
+			t.Fatalf("Missing target for %q", args)
+		}
+	})
+}
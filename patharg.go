@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathOptions controls how AppendFileArg and AppendDirArg check their path
+// when the command is parsed.
+type PathOptions struct {
+	// AllowMissing accepts paths that do not exist yet, such as outputs.
+	AllowMissing bool
+
+	// Readable requires that the path can be opened.
+	Readable bool
+
+	// Extensions, like ".png", limit the files accepted. They are matched
+	// case-insensitively.
+	Extensions []string
+
+	// Glob is a pattern the path's base name must match, e.g. "*.tar.gz".
+	Glob string
+}
+
+type pathArg struct {
+	dir  bool
+	opts PathOptions
+}
+
+// AppendFileArg adds an argument naming a file, which is checked according
+// to opts at parse time and completed from the filesystem in the shell.
+func (cmd *Command) AppendFileArg(name, desc string, opts PathOptions) {
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc, path: &pathArg{opts: opts}})
+}
+
+// AppendDirArg adds an argument naming a directory, like AppendFileArg.
+func (cmd *Command) AppendDirArg(name, desc string, opts PathOptions) {
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc, path: &pathArg{dir: true, opts: opts}})
+}
+
+func (pa *pathArg) kind() string {
+	if pa.dir {
+		return "dir"
+	}
+
+	return "file"
+}
+
+// check returns a message like "input.png: no such file" if path, resolved
+// against dir, is not acceptable.
+func (pa *pathArg) check(dir, path string) string {
+	fi, err := os.Stat(inDir(dir, path))
+
+	switch {
+	case os.IsNotExist(err) && pa.opts.AllowMissing:
+	case os.IsNotExist(err):
+		return fmt.Sprintf("%s: no such %s", path, pa.kind())
+	case os.IsPermission(err):
+		return fmt.Sprintf("%s: permission denied", path)
+	case err != nil:
+		return fmt.Sprintf("%s: %v", path, err)
+	case pa.dir && !fi.IsDir():
+		return fmt.Sprintf("%s: not a directory", path)
+	case !pa.dir && fi.IsDir():
+		return fmt.Sprintf("%s: is a directory", path)
+	case pa.opts.Readable:
+		f, err := os.Open(inDir(dir, path))
+		if err != nil {
+			return fmt.Sprintf("%s: not readable", path)
+		}
+		f.Close()
+	}
+
+	if !pa.matches(filepath.Base(path)) {
+		if pa.opts.Glob != "" {
+			return fmt.Sprintf("%s: must match %s", path, pa.opts.Glob)
+		}
+
+		return fmt.Sprintf("%s: must have extension %s", path, strings.Join(pa.opts.Extensions, ", "))
+	}
+
+	return ""
+}
+
+// matches reports whether name has an accepted extension and matches the
+// glob.
+func (pa *pathArg) matches(name string) bool {
+	if pa.opts.Glob != "" {
+		if ok, _ := filepath.Match(pa.opts.Glob, name); !ok {
+			return false
+		}
+	}
+
+	if len(pa.opts.Extensions) == 0 {
+		return true
+	}
+
+	for _, ext := range pa.opts.Extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+
+		if strings.HasSuffix(strings.ToLower(name), strings.ToLower(ext)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// complete returns the paths starting with partial that the argument could
+// take, with directories ending in a separator so completion can continue
+// into them.
+func (pa *pathArg) complete(partial string) []string {
+	dir, base := filepath.Split(partial)
+
+	entries, err := os.ReadDir(filepath.Clean(dir + "."))
+	if err != nil {
+		return nil
+	}
+
+	ret := []string{}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if fi, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = fi.IsDir()
+			}
+		}
+
+		switch {
+		case isDir:
+			ret = append(ret, dir+name+string(filepath.Separator))
+		case !pa.dir && pa.matches(name):
+			ret = append(ret, dir+name)
+		}
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+// argAt returns the arg the next word after words would fill, skipping
+// flags, or nil.
+func (cmd *Command) argAt(words []string) *Arg {
+	n := 0
+	for _, w := range words {
+		if !strings.HasPrefix(w, "-") {
+			n++
+		}
+	}
+
+	for i, a := range cmd.Args {
+		if i == n || a.Variable && i <= n {
+			return a
+		}
+	}
+
+	return nil
+}
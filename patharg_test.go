@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCmdFileArg(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b.PNG", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp()
+	app.AddCommand(NewCommand("convert", "test-group", "converts", func(c *Command) {
+		c.AppendFileArg("input", "the image", PathOptions{Extensions: []string{".png"}})
+		c.AppendDirArg("output", "where to write", PathOptions{AllowMissing: true})
+	}, func(c *Command) error { return nil }))
+
+	p := func(name string) string { return filepath.Join(dir, name) }
+
+	if err := app.Run([]string{"app", "convert", p("a.png"), p("new")}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		args []string
+		msg  string
+	}{
+		{[]string{p("input.png"), dir}, p("input.png") + ": no such file"},
+		{[]string{p("c.txt"), dir}, p("c.txt") + ": must have extension .png"},
+		{[]string{p("sub"), dir}, p("sub") + ": is a directory"},
+		{[]string{p("a.png"), p("c.txt")}, p("c.txt") + ": not a directory"},
+	}
+
+	for _, tc := range testCases {
+		err := app.Run(append([]string{"app", "convert"}, tc.args...))
+		if !errors.Is(err, ErrInvalidValue) || err.Error() != tc.msg {
+			t.Fatalf("Expected %q, got %v", tc.msg, err)
+		}
+	}
+
+	expected := []string{p("a.png"), p("b.PNG"), p("sub") + string(filepath.Separator)}
+	if c := app.completeLine(context.Background(), "convert "+dir+string(filepath.Separator)); !reflect.DeepEqual(c, expected) {
+		t.Fatalf("Expected %v, got %v", expected, c)
+	}
+
+	expected = []string{p("sub") + string(filepath.Separator)}
+	if c := app.completeLine(context.Background(), "convert x.png "+p("s")); !reflect.DeepEqual(c, expected) {
+		t.Fatalf("Expected %v, got %v", expected, c)
+	}
+
+	c, _ := app.command("convert")
+	if info := c.Describe(); info.Args[0].Complete != "file" || info.Args[1].Complete != "dir" {
+		t.Fatalf("Unexpected completion hints %+v", info.Args)
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import "context"
+
+const (
+	plainEnv  = "CMD_PLAIN"
+	plainFlag = "--plain"
+)
+
+// plainKey marks the context of a run given --plain.
+type plainKey struct{}
+
+// plainIn reports whether output in ctx must be plain: App.Plain is set, the
+// run was given --plain, CMD_PLAIN is set or the terminal is dumb.
+func (app *App) plainIn(ctx context.Context) bool {
+	if app != nil && app.Plain || ctx.Value(plainKey{}) != nil {
+		return true
+	}
+
+	if v := app.getenvIn(ctx, plainEnv); v != "" && v != "0" && v != "false" {
+		return true
+	}
+
+	return app.getenvIn(ctx, "TERM") == "dumb"
+}
+
+// Plain reports whether the command should write linear text, without
+// color, animation or cursor movement, for screen readers and logs.
+func (cmd *Command) Plain() bool {
+	return cmd.app.plainIn(cmd.Context())
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppPlain(t *testing.T) {
+	var plain []bool
+	var rest []string
+
+	app := NewApp()
+	app.Getenv = func(string) string { return "" }
+	app.AddCommand(NewCommand("sync", "test-group", "syncs", func(c *Command) {
+		c.AppendVarArg("args", "passed on")
+	}, func(c *Command) error {
+		plain, rest = append(plain, c.Plain()), nil
+		for _, v := range c.VarArgs() {
+			rest = append(rest, v.String())
+		}
+
+		return nil
+	}))
+
+	for _, args := range [][]string{{"sync", "a"}, {"--plain", "sync", "a"}, {"sync", "ssh", "--plain", "host"}} {
+		if err := app.Run(append([]string{"app"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if expected := []string{"ssh", "--plain", "host"}; !reflect.DeepEqual(rest, expected) {
+		t.Fatalf("Expected --plain after the command to be passed on, got %q", rest)
+	}
+
+	app.Getenv = func(name string) string {
+		if name == "TERM" {
+			return "dumb"
+		}
+
+		return ""
+	}
+
+	if err := app.Run([]string{"app", "sync", "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []bool{false, true, false, true}; !reflect.DeepEqual(plain, expected) {
+		t.Fatalf("Expected %v, got %v", expected, plain)
+	}
+}
+
+func TestPlainSpinnerAndPicker(t *testing.T) {
+	app := NewApp()
+	app.Plain = true
+
+	var out strings.Builder
+
+	c := NewCommand("sync", "test-group", "syncs", func(c *Command) {}, nil)
+	c.Stderr = &out
+	app.AddCommand(c)
+
+	c.Spinner("Syncing").Stop("Synced")
+
+	if expected := "Syncing...\nSynced\n"; out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+
+	p := newPicker("Pick", []string{"a", "b"}, false)
+	p.plain = true
+
+	idx, err := p.run(strings.NewReader("2\n"), &out)
+	if err != nil || len(idx) != 1 || idx[0] != 1 {
+		t.Fatalf("Expected the second option, got %v, %v", idx, err)
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Requirement is a host feature a command needs, such as a kernel module or
+// a device file. Check returns a non-nil error when it is missing.
+type Requirement struct {
+	Name  string
+	Check func() error
+}
+
+// PathRequirement is satisfied when path exists on the host.
+func PathRequirement(name, path string) Requirement {
+	return Requirement{
+		Name: name,
+		Check: func() error {
+			_, err := os.Stat(path)
+			return err
+		},
+	}
+}
+
+type PlatformErr struct {
+	Command     string
+	Platform    string
+	Supported   []string
+	Requirement string
+	Err         error
+	Image       string
+
+	app *App
+}
+
+func (pe *PlatformErr) Error() string {
+	var msg string
+
+	if pe.Requirement != "" {
+		msg = pe.app.trf("%s requires %s, which is unavailable on this host: %v", pe.Command, pe.Requirement, pe.Err)
+	} else {
+		msg = pe.app.trf("%s is not supported on %s (supported: %s)", pe.Command, pe.Platform, strings.Join(pe.Supported, ", "))
+	}
+
+	if pe.Image != "" {
+		msg += "\n" + pe.app.trf("Try running it from a container instead: docker run --rm -it %s %s", pe.Image, pe.Command)
+	}
+
+	return msg
+}
+
+func (pe *PlatformErr) Unwrap() error {
+	return pe.Err
+}
+
+func platformMatches(pattern, goos, goarch string) bool {
+	parts := strings.SplitN(pattern, "/", 2)
+
+	if parts[0] != "*" && parts[0] != goos {
+		return false
+	}
+
+	return len(parts) == 1 || parts[1] == "*" || parts[1] == goarch
+}
+
+func (app *App) checkPlatform(cmd *Command) error {
+	if len(cmd.Platforms) > 0 {
+		supported := false
+
+		for _, p := range cmd.Platforms {
+			if platformMatches(p, runtime.GOOS, runtime.GOARCH) {
+				supported = true
+				break
+			}
+		}
+
+		if !supported {
+			return &PlatformErr{
+				Command:   cmd.Name,
+				Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+				Supported: cmd.Platforms,
+				Image:     app.ContainerImage,
+				app:       app,
+			}
+		}
+	}
+
+	for _, r := range cmd.Requirements {
+		if err := r.Check(); err != nil {
+			return &PlatformErr{
+				Command:     cmd.Name,
+				Platform:    runtime.GOOS + "/" + runtime.GOARCH,
+				Requirement: r.Name,
+				Err:         err,
+				Image:       app.ContainerImage,
+				app:         app,
+			}
+		}
+	}
+
+	return nil
+}
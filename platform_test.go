@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPlatformMatches(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		match   bool
+	}{
+		{"linux", true},
+		{"linux/amd64", true},
+		{"linux/*", true},
+		{"*/amd64", true},
+		{"linux/arm64", false},
+		{"darwin", false},
+	}
+
+	for _, tc := range testCases {
+		if m := platformMatches(tc.pattern, "linux", "amd64"); m != tc.match {
+			t.Fatalf("Expected %t for %s", tc.match, tc.pattern)
+		}
+	}
+}
+
+func TestAppCheckPlatform(t *testing.T) {
+	ran := false
+
+	app := NewApp()
+	app.ContainerImage = "example/tool"
+
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {}, func(c *Command) error {
+		ran = true
+		return nil
+	})
+	c.Platforms = []string{"plan9/mips"}
+	app.AddCommand(c)
+
+	err := app.Run([]string{"app", "test"})
+	if _, ok := err.(*PlatformErr); !ok || ran {
+		t.Fatalf("Expected a platform error, got %v", err)
+	}
+
+	c.Platforms = nil
+	c.Requirements = []Requirement{PathRequirement("a missing file", "/does/not/exist")}
+
+	err = app.Run([]string{"app", "test"})
+	pe, ok := err.(*PlatformErr)
+	if !ok || ran {
+		t.Fatalf("Expected a platform error, got %v", err)
+	}
+
+	if !os.IsNotExist(pe.Unwrap()) {
+		t.Fatalf("Expected a not exist error, got %v", pe.Unwrap())
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+type pprofFlags struct {
+	cpu   string
+	mem   string
+	trace string
+}
+
+func (cmd *Command) addPprofFlags() {
+	cmd.pprof = &pprofFlags{}
+	cmd.Flags.StringVar(&cmd.pprof.cpu, "cpuprofile", "", "Write a CPU profile to this file")
+	cmd.Flags.StringVar(&cmd.pprof.mem, "memprofile", "", "Write a heap profile to this file")
+	cmd.Flags.StringVar(&cmd.pprof.trace, "trace", "", "Write an execution trace to this file")
+
+	for _, n := range []string{"cpuprofile", "memprofile", "trace"} {
+		cmd.HideFlag(n)
+	}
+}
+
+func (pf *pprofFlags) enabled() bool {
+	return pf != nil && (pf.cpu != "" || pf.mem != "" || pf.trace != "")
+}
+
+// withProfiling runs run with the CPU profile and execution trace requested
+// by pf recording, and writes the heap profile once it returns.
+func withProfiling(run RunFunc, pf *pprofFlags) RunFunc {
+	return func(cmd *Command) error {
+		if pf.cpu != "" {
+			f, err := os.Create(pf.cpu)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := pprof.StartCPUProfile(f); err != nil {
+				return err
+			}
+			defer pprof.StopCPUProfile()
+		}
+
+		if pf.trace != "" {
+			f, err := os.Create(pf.trace)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := trace.Start(f); err != nil {
+				return err
+			}
+			defer trace.Stop()
+		}
+
+		runErr := run(cmd)
+
+		if pf.mem != "" {
+			f, err := os.Create(pf.mem)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				return err
+			}
+		}
+
+		return runErr
+	}
+}
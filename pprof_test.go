@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileFlags(t *testing.T) {
+	var out strings.Builder
+	dir := t.TempDir()
+
+	app := NewApp()
+	app.Stdout = &out
+	app.ProfileFlags = true
+	app.AddCommand(NewCommand("work", "test-group", "does work", func(c *Command) {}, func(c *Command) error {
+		return nil
+	}))
+
+	cpu, mem, trace := filepath.Join(dir, "cpu.prof"), filepath.Join(dir, "mem.prof"), filepath.Join(dir, "trace.out")
+
+	if err := app.Run([]string{"app", "work", "--cpuprofile", cpu, "--memprofile", mem, "--trace", trace}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{cpu, mem, trace} {
+		if fi, err := os.Stat(f); err != nil || fi.Size() == 0 {
+			t.Fatalf("Expected %s to be written, got %v", f, err)
+		}
+	}
+
+	if err := app.Run([]string{"app", "work", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "profile") {
+		t.Fatalf("Expected profiling flags to be hidden, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+const (
+	profileEnv     = "CMD_PROFILE"
+	profileOption  = "--profile"
+	profileSection = "profiles"
+)
+
+// profileKey holds the profile a run was given with --profile.
+type profileKey struct{}
+
+// EnableProfiles lets the config files hold named profiles that switch a
+// set of flag values and environment variables at once:
+//
+//	profile: staging
+//	profiles:
+//	  staging:
+//	    flags:
+//	      endpoint: https://staging.example.com
+//	      region: us-east-1
+//	    env:
+//	      API_TOKEN: staging-token
+//
+// A run uses the profile given with --profile, then the one in CMD_PROFILE,
+// then the config's profile key. Profile flags apply to every command with
+// a flag of that name, and are overridden by the command line. Profile env
+// values override the process environment for the run.
+//
+// It also adds a profile command to list the profiles and select the one
+// used by default.
+func (app *App) EnableProfiles() {
+	app.profiles = true
+
+	c := NewCommand("profile", "Shell", "Manage config profiles: list, use <name>",
+		func(cmd *Command) {
+			cmd.AppendVarArg("action", "list or use, followed by its arguments")
+		},
+		func(cmd *Command) error {
+			args := cmd.VarArgs()
+
+			rc, err := app.resolveConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case args[0] == "list" && len(args) == 1:
+				active := app.profileName(cmd.Context(), rc)
+
+				for _, n := range rc.children(profileSection) {
+					mark := " "
+					if n == active {
+						mark = "*"
+					}
+
+					fmt.Fprintf(cmd.stdout(), "%s %s\n", mark, n)
+				}
+
+				return nil
+			case args[0] == "use" && len(args) == 2:
+				name := args[1].String()
+				if _, ok := rc.sections[profileSection+"."+name]; !ok {
+					return fmt.Errorf("no profile named %s", name)
+				}
+
+				cf, err := app.loadConfig()
+				if err != nil {
+					return err
+				}
+
+				if err := cf.set("profile", name); err != nil {
+					return err
+				}
+
+				return cf.save()
+			}
+
+			return cmd.usageErr("Invalid profile action", ErrArgCount)
+		})
+	c.noProfile = true
+
+	app.AddCommand(c)
+}
+
+// profileName returns the profile for the run in ctx, or "" for none.
+func (app *App) profileName(ctx context.Context, cf *configFile) string {
+	if p, ok := ctx.Value(profileKey{}).(string); ok {
+		return p
+	}
+
+	if p := app.getenvIn(ctx, profileEnv); p != "" {
+		return p
+	}
+
+	p, _ := cf.get("profile")
+	return p
+}
+
+// applyProfile sets cmd's flags and environment from the run's profile.
+func (app *App) applyProfile(ctx context.Context, cmd *Command) error {
+	cf, err := app.resolveConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	name := app.profileName(ctx, cf)
+	if name == "" {
+		return nil
+	}
+
+	section := profileSection + "." + name
+	if _, ok := cf.sections[section]; !ok {
+		return fmt.Errorf("no profile named %s", name)
+	}
+
+	cmd.profile = name
+	from := Provenance{Source: SourceProfile, Detail: name}
+
+	for _, k := range cf.keys(section + ".flags") {
+		f := cmd.Flags.Lookup(k)
+		if f == nil {
+			continue
+		}
+
+		key := section + ".flags." + k
+		e := cf.values[key]
+		if err := presetValue(f.Value, e.value); err != nil {
+			return &ConfigErr{cf.fileOf(key), e.line, fmt.Sprintf("invalid value %q for --%s: %v", e.value, k, err)}
+		}
+
+		cmd.SetProvenance(k, from)
+	}
+
+	for _, k := range cf.keys(section + ".env") {
+		v, _ := cf.get(section + ".env." + k)
+		cmd.setenv(k, v)
+
+		if _, ok := cmd.EnvArgs[k]; ok {
+			cmd.SetProvenance(k, from)
+		}
+	}
+
+	return nil
+}
+
+// presetValue sets v to s on the user's behalf, before the command line is
+// parsed. A list flag given on the command line then replaces s rather than
+// adding to it.
+func presetValue(v flag.Value, s string) error {
+	if err := v.Set(s); err != nil {
+		return err
+	}
+
+	if lv, ok := v.(*listValue); ok {
+		lv.set = false
+	}
+
+	return nil
+}
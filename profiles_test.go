@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAppProfiles(t *testing.T) {
+	config := `profile: staging
+profiles:
+  staging:
+    flags:
+      region: us-east-1
+      zone: a,b
+    env:
+      API_TOKEN: staging-token
+  prod:
+    flags:
+      region: eu-west-1
+`
+
+	app := NewApp()
+	app.Getenv = func(name string) string { return map[string]string{"API_TOKEN": "from-env"}[name] }
+	app.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(app.ConfigFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app.EnableProfiles()
+
+	var region, token string
+	var zones []string
+	var from Provenance
+
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.Flags.String("region", "us-west-2", "the region")
+		c.AddFlagList("zone", nil, "zones", ListOptions{})
+		c.AddEnvArg("API_TOKEN", "the token")
+	}, func(c *Command) error {
+		region, token, zones = c.Flag("region").String(), c.EnvArg("API_TOKEN").String(), c.FlagSlice("zone")
+		from = c.Provenance("region")
+		return nil
+	}))
+
+	testCases := []struct {
+		global []string
+		args   []string
+		region string
+		token  string
+		zones  []string
+		from   Provenance
+	}{
+		{nil, []string{}, "us-east-1", "staging-token", []string{"a", "b"}, Provenance{Source: SourceProfile, Detail: "staging"}},
+		{nil, []string{"--zone", "c", "--region", "ap-south-1"}, "ap-south-1", "staging-token", []string{"c"}, Provenance{Source: SourceCLI}},
+		{[]string{"--profile", "prod"}, nil, "eu-west-1", "from-env", []string{}, Provenance{Source: SourceProfile, Detail: "prod"}},
+		{[]string{"--profile=prod"}, nil, "eu-west-1", "from-env", []string{}, Provenance{Source: SourceProfile, Detail: "prod"}},
+	}
+
+	for _, tc := range testCases {
+		args := append(append([]string{"app"}, tc.global...), "deploy")
+		if err := app.Run(append(args, tc.args...)); err != nil {
+			t.Fatal(err)
+		}
+
+		if region != tc.region || token != tc.token || !reflect.DeepEqual(zones, tc.zones) || from != tc.from {
+			t.Fatalf("Unexpected %q %q %q %v for %q", region, token, zones, from, tc.args)
+		}
+	}
+
+	if err := app.Run([]string{"app", "--profile", "qa", "deploy"}); err == nil || !strings.Contains(err.Error(), "no profile named qa") {
+		t.Fatalf("Expected an unknown profile error, got %v", err)
+	}
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	if err := app.Run([]string{"app", "profile", "use", "prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Run([]string{"app", "profile", "list"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "  staging\n* prod\n" {
+		t.Fatalf("Unexpected profile list %q", out.String())
+	}
+
+	if err := app.Run([]string{"app", "profile", "use", "qa"}); err == nil {
+		t.Fatal("Expected an unknown profile to be refused")
+	}
+}
+
+func TestAppProfileOnlyBeforeCommand(t *testing.T) {
+	app := NewApp()
+	app.Getenv = func(string) string { return "" }
+	app.ConfigFile = filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(app.ConfigFile, []byte("profiles:\n  prod:\n    env:\n      REGION: eu\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app.EnableProfiles()
+
+	var args []string
+	var region string
+
+	app.AddCommand(NewCommand("add", "test-group", "adds", func(c *Command) {
+		c.AppendVarArg("args", "the command to add")
+	}, func(c *Command) error {
+		args, region = nil, c.getenv("REGION")
+		for _, v := range c.VarArgs() {
+			args = append(args, v.String())
+		}
+
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "--profile", "prod", "add", "backup", "--profile", "staging"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"backup", "--profile", "staging"}; !reflect.DeepEqual(args, expected) || region != "eu" {
+		t.Fatalf("Expected %q in profile prod, got %q with REGION=%q", expected, args, region)
+	}
+}
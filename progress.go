@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressWidth    = 30
+	progressInterval = 100 * time.Millisecond
+)
+
+// Progress renders a progress bar on the command's stderr. When stderr is
+// not a terminal, or in CI, it prints a plain line every 10% instead. It is
+// safe for concurrent use.
+type Progress struct {
+	mu       sync.Mutex
+	w        io.Writer
+	label    string
+	total    int
+	current  int
+	tty      bool
+	lastDraw time.Time
+	lastStep int
+	done     bool
+}
+
+func (cmd *Command) Progress(total int) *Progress {
+	w := cmd.stderr()
+
+	return &Progress{
+		w:        w,
+		label:    cmd.Name,
+		total:    total,
+		tty:      cmd.interactiveWriter(w),
+		lastStep: -1,
+	}
+}
+
+func (p *Progress) Add(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current += n
+	p.render(false)
+}
+
+func (p *Progress) Set(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current = n
+	p.render(false)
+}
+
+// Done draws the final state. Further updates are ignored.
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return
+	}
+
+	p.render(true)
+	p.done = true
+
+	if p.tty {
+		fmt.Fprintln(p.w)
+	}
+}
+
+func (p *Progress) percent() int {
+	if p.total <= 0 {
+		return 100
+	}
+
+	pct := p.current * 100 / p.total
+	if pct > 100 {
+		pct = 100
+	}
+
+	return pct
+}
+
+func (p *Progress) render(final bool) {
+	if p.done {
+		return
+	}
+
+	pct := p.percent()
+
+	if !p.tty {
+		if step := pct / 10; step != p.lastStep || final {
+			if step == p.lastStep && final {
+				return
+			}
+
+			p.lastStep = step
+			fmt.Fprintf(p.w, "%s: %d/%d (%d%%)\n", p.label, p.current, p.total, pct)
+		}
+
+		return
+	}
+
+	if !final && time.Since(p.lastDraw) < progressInterval && p.current < p.total {
+		return
+	}
+
+	p.lastDraw = time.Now()
+
+	filled := pct * progressWidth / 100
+	bar := strings.Repeat("=", filled)
+	if filled < progressWidth {
+		bar += ">" + strings.Repeat(" ", progressWidth-filled-1)
+	}
+
+	fmt.Fprintf(p.w, "\r\x1b[K%s [%s] %d/%d %3d%%", p.label, bar, p.current, p.total, pct)
+}
+
+// Spinner shows that work is in progress on the command's stderr. When
+// stderr is not a terminal, or in CI, it prints its label once instead of
+// animating. It is safe for concurrent use.
+type Spinner struct {
+	mu     sync.Mutex
+	w      io.Writer
+	label  string
+	tty    bool
+	frames []string
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (cmd *Command) Spinner(label string) *Spinner {
+	w := cmd.stderr()
+
+	s := &Spinner{
+		w:      w,
+		label:  label,
+		tty:    cmd.interactiveWriter(w),
+		frames: cmd.symbolsFor(w).Spinner,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if !s.tty {
+		fmt.Fprintf(w, "%s...\n", label)
+		close(s.done)
+		return s
+	}
+
+	go s.spin()
+
+	return s
+}
+
+func (s *Spinner) spin() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		fmt.Fprintf(s.w, "\r\x1b[K%s %s", s.frames[i%len(s.frames)], s.label)
+		s.mu.Unlock()
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update changes the label shown next to the spinner.
+func (s *Spinner) Update(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.label = label
+
+	if !s.tty {
+		fmt.Fprintf(s.w, "%s...\n", label)
+	}
+}
+
+// Stop ends the animation and prints msg, if any, in its place.
+func (s *Spinner) Stop(msg string) {
+	s.mu.Lock()
+	select {
+	case <-s.stop:
+		s.mu.Unlock()
+		return
+	default:
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tty {
+		fmt.Fprint(s.w, "\r\x1b[K")
+	}
+
+	if msg != "" {
+		fmt.Fprintln(s.w, msg)
+	}
+}
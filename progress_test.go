@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProgressPlain(t *testing.T) {
+	var out strings.Builder
+
+	c := NewCommand("copy", "test-group", "copies things", nil, nil)
+	c.Stderr = &out
+
+	p := c.Progress(20)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Add(1)
+		}()
+	}
+
+	wg.Wait()
+	p.Done()
+	p.Done()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("Expected a line every 10%%, got %q", out.String())
+	}
+
+	if lines[10] != "copy: 20/20 (100%)" {
+		t.Fatalf("Unexpected final line %q", lines[10])
+	}
+}
+
+func TestSpinnerPlain(t *testing.T) {
+	var out strings.Builder
+
+	c := NewCommand("wait", "test-group", "waits", nil, nil)
+	c.Stderr = &out
+
+	s := c.Spinner("Waiting")
+	s.Update("Still waiting")
+	s.Stop("Done")
+	s.Stop("Done again")
+
+	if out.String() != "Waiting...\nStill waiting...\nDone\n" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
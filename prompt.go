@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var ErrNotInteractive = errors.New("input is not a terminal")
+
+// AddSecretEnvArg adds a required environment variable whose value is
+// sensitive. It is read with hidden input when prompted for.
+func (cmd *Command) AddSecretEnvArg(name, desc string) {
+	cmd.AddEnvArg(name, desc)
+	cmd.MarkSecret(name)
+}
+
+// MarkSecret marks the flag, arg or env arg called name as sensitive, so its
+// value is hidden when prompted for and redacted from traces and history.
+func (cmd *Command) MarkSecret(name string) {
+	if cmd.secrets == nil {
+		cmd.secrets = map[string]bool{}
+	}
+
+	cmd.secrets[name] = true
+}
+
+func (cmd *Command) isSecret(name string) bool {
+	return cmd.secrets[name]
+}
+
+// Prompt asks the user for a line of input on the terminal.
+func (cmd *Command) Prompt(label string) (string, error) {
+	if !cmd.Interactive() {
+		return "", ErrNotInteractive
+	}
+
+	fmt.Fprintf(cmd.stderr(), "%s: ", label)
+	return readLine(cmd.stdin())
+}
+
+// PromptSecret is like Prompt but does not echo the input.
+func (cmd *Command) PromptSecret(label string) (string, error) {
+	if !cmd.Interactive() {
+		return "", ErrNotInteractive
+	}
+
+	in := cmd.stdin()
+
+	if f, ok := in.(*os.File); ok {
+		if err := setEcho(f, false); err != nil {
+			return "", err
+		}
+		defer setEcho(f, true)
+	}
+
+	fmt.Fprintf(cmd.stderr(), "%s: ", label)
+	line, err := readLine(in)
+	fmt.Fprintln(cmd.stderr())
+
+	return line, err
+}
+
+// readLine reads up to a newline one byte at a time so nothing past the line
+// is consumed from r.
+func readLine(r io.Reader) (string, error) {
+	var sb strings.Builder
+	buf := make([]byte, 1)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+
+			sb.WriteByte(buf[0])
+		}
+
+		if err == io.EOF {
+			if sb.Len() == 0 {
+				return "", io.ErrUnexpectedEOF
+			}
+			break
+		} else if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\r"), nil
+}
+
+// promptMissing asks for any missing positional args and unset env args and
+// returns args with the answers appended.
+func (cmd *Command) promptMissing(args []string) ([]string, error) {
+	// Parsed into a copy so flags that add to themselves, like lists, aren't
+	// set twice; bad flags are left for cmd.Parse to report
+	fs := cloneFlags(cmd.Flags)
+	if err := fs.Parse(cmd.negateFlags(args)); err != nil {
+		return args, nil
+	}
+
+	given := fs.NArg()
+	prompted := []string{}
+
+	for i, a := range cmd.Args {
+		if i < given {
+			continue
+		}
+
+		prompt := cmd.Prompt
+		if cmd.isSecret(a.Name) {
+			prompt = cmd.PromptSecret
+		}
+
+		v, err := prompt(fmt.Sprintf("%s (%s)", a.Name, a.Description))
+		if err != nil {
+			return nil, err
+		}
+
+		prompted = append(prompted, v)
+		cmd.SetProvenance(a.Name, Provenance{Source: SourcePrompt})
+	}
+
+	if len(prompted) > 0 {
+		if given == 0 {
+			args = append(args, "--")
+		}
+
+		args = append(args, prompted...)
+	}
+
+	names := make([]string, 0, len(cmd.EnvArgs))
+	for n := range cmd.EnvArgs {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		if cmd.EnvArg(n) != "" {
+			continue
+		}
+
+		label := fmt.Sprintf("%s (%s)", n, cmd.EnvArgs[n])
+
+		var v string
+		var err error
+
+		if cmd.isSecret(n) {
+			v, err = cmd.PromptSecret(label)
+		} else {
+			v, err = cmd.Prompt(label)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.setenv(n, v)
+		cmd.SetProvenance(n, Provenance{Source: SourcePrompt})
+	}
+
+	return args, nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadLine(t *testing.T) {
+	r := strings.NewReader("first\r\nsecond\nthird")
+
+	for _, expected := range []string{"first", "second", "third"} {
+		line, err := readLine(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line != expected {
+			t.Fatalf("Expected %q, got %q", expected, line)
+		}
+	}
+
+	if _, err := readLine(r); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected unexpected EOF, got %v", err)
+	}
+}
+
+func TestPromptNotInteractive(t *testing.T) {
+	if isTerminal(os.Stdin) {
+		t.Skip("stdin is a terminal")
+	}
+
+	c := NewCommand("test", "test-group", "does test stuff", nil, nil)
+	c.AppendArg("a", "an arg")
+
+	if _, err := c.promptMissing([]string{}); err != ErrNotInteractive {
+		t.Fatalf("Expected ErrNotInteractive, got %v", err)
+	}
+
+	args, err := c.promptMissing([]string{"given"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(args) != 1 {
+		t.Fatalf("Expected nothing to be prompted, got %v", args)
+	}
+}
+
+func TestPromptMissing(t *testing.T) {
+	var got []string
+
+	app := NewApp()
+	app.PromptMissing = true
+	app.Interactive = Always
+	app.Stdin = strings.NewReader("x\ny\nz\n")
+	app.Stderr = io.Discard
+	app.Getenv = func(string) string { return "" }
+
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.AppendArg("a", "an arg")
+		c.AppendVarArg("rest", "more args")
+		c.AddEnvArg("CMD_TEST_PROMPTED", "a var")
+	}, func(c *Command) error {
+		got = []string{c.Arg("a").String(), c.VarArgs()[0].String(), c.EnvArg("CMD_TEST_PROMPTED").String()}
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(got, " ") != "x y z" {
+		t.Fatalf("Expected the args, var arg and env arg to be prompted, got %q", got)
+	}
+
+	if v := os.Getenv("CMD_TEST_PROMPTED"); v != "" {
+		t.Fatalf("Expected the process env to be left alone, got %q", v)
+	}
+}
+
+func TestPromptMissingListFlag(t *testing.T) {
+	var tags []string
+
+	app := NewApp()
+	app.PromptMissing = true
+	app.Interactive = Always
+	app.Stdin = strings.NewReader("x\n")
+	app.Stderr = io.Discard
+	app.Getenv = func(string) string { return "" }
+
+	app.AddCommand(NewCommand("test", "test-group", "does test stuff", func(c *Command) {
+		c.AddFlagList("tag", nil, "tags", ListOptions{})
+		c.AppendArg("a", "an arg")
+	}, func(c *Command) error {
+		tags = c.FlagSlice("tag")
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "test", "--tag", "a", "--tag", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(tags, " ") != "a b" {
+		t.Fatalf("Expected each tag once, got %q", tags)
+	}
+}
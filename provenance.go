@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Source identifies where a value came from.
+type Source string
+
+const (
+	SourceCLI     Source = "cli"
+	SourceEnv     Source = "env"
+	SourceConfig  Source = "config"
+	SourceDefault Source = "default"
+	SourceProfile Source = "profile"
+	SourceSticky  Source = "sticky"
+	SourcePrompt  Source = "prompt"
+)
+
+// Provenance describes where an arg, flag or env arg value came from. File
+// and Line are set for values read from config files; Detail names the
+// profile for profile values.
+type Provenance struct {
+	Source Source `json:"source"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (p Provenance) String() string {
+	switch {
+	case p.File != "" && p.Line > 0:
+		return fmt.Sprintf("%s (%s:%d)", p.Source, p.File, p.Line)
+	case p.File != "":
+		return fmt.Sprintf("%s (%s)", p.Source, p.File)
+	case p.Detail != "":
+		return fmt.Sprintf("%s (%s)", p.Source, p.Detail)
+	}
+
+	return string(p.Source)
+}
+
+// SetProvenance records where the value of name came from. It is meant for
+// layers that supply values on the user's behalf, such as config files.
+func (cmd *Command) SetProvenance(name string, p Provenance) {
+	if cmd.provenance == nil {
+		cmd.provenance = map[string]Provenance{}
+	}
+
+	cmd.provenance[name] = p
+}
+
+// Provenance returns where the value of the flag, arg or env arg called name
+// came from. The zero Provenance is returned for unknown names. A flag
+// given on the command line is always from the CLI, whatever was recorded
+// with SetProvenance.
+func (cmd *Command) Provenance(name string) Provenance {
+	if cmd.Flags.Lookup(name) != nil {
+		set := false
+		cmd.Flags.Visit(func(f *flag.Flag) {
+			if f.Name == name {
+				set = true
+			}
+		})
+
+		if set {
+			return Provenance{Source: SourceCLI}
+		}
+	}
+
+	if p, ok := cmd.provenance[name]; ok {
+		return p
+	}
+
+	if cmd.Flags.Lookup(name) != nil {
+		return Provenance{Source: SourceDefault}
+	}
+
+	for _, a := range cmd.Args {
+		if a.Name == name {
+			return Provenance{Source: SourceCLI}
+		}
+	}
+
+	if _, ok := cmd.EnvArgs[name]; ok {
+		return Provenance{Source: SourceEnv}
+	}
+
+	return Provenance{}
+}
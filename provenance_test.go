@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCmdProvenance(t *testing.T) {
+	os.Setenv("CMD_TEST_PROVENANCE", "set")
+	defer os.Unsetenv("CMD_TEST_PROVENANCE")
+
+	seen := map[string]Provenance{}
+
+	app := NewApp()
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.AppendArg("service", "the service")
+		c.Flags.String("env", "staging", "the env")
+		c.Flags.String("region", "us-east-1", "the region")
+		c.AddEnvArg("CMD_TEST_PROVENANCE", "a var")
+	}, func(c *Command) error {
+		c.SetProvenance("region", Provenance{Source: SourceConfig, File: "/etc/app/config.yaml", Line: 4})
+
+		for _, n := range []string{"service", "env", "region", "CMD_TEST_PROVENANCE", "nope"} {
+			seen[n] = c.Provenance(n)
+		}
+
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "deploy", "--env", "prod", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"service":             "cli",
+		"env":                 "cli",
+		"region":              "config (/etc/app/config.yaml:4)",
+		"CMD_TEST_PROVENANCE": "env",
+		"nope":                "",
+	}
+
+	for n, e := range expected {
+		if s := seen[n].String(); s != e {
+			t.Fatalf("Expected %q for %s, got %q", e, n, s)
+		}
+	}
+
+	// Explicit provenance does not leak into the next run
+	app.Commands["deploy"].Run = func(c *Command) error {
+		seen["region"] = c.Provenance("region")
+		return nil
+	}
+
+	if err := app.Run([]string{"app", "deploy", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if seen["region"].Source != SourceDefault {
+		t.Fatalf("Expected the default source, got %s", seen["region"])
+	}
+}
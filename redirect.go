@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// AddRedirect keeps the old name of a renamed command working. Running it
+// prints a deprecation notice and runs the command called to, and help
+// search and completion offer the new name for the old one.
+func (app *App) AddRedirect(from, to string) {
+	app.checkFrozen("AddRedirect")
+
+	if app.redirects == nil {
+		app.redirects = map[string]string{}
+	}
+
+	app.redirects[from] = to
+}
+
+// redirect returns the name name has been renamed to, if it is a redirect
+// and not also the name of a command.
+func (app *App) redirect(name string) (string, bool) {
+	if _, ok := app.Commands[name]; ok {
+		return "", false
+	}
+
+	to, ok := app.redirects[name]
+	return to, ok
+}
+
+// resolve returns the command called name or redirected from it.
+func (app *App) resolve(name string) (*Command, bool) {
+	if to, ok := app.redirect(name); ok {
+		name = to
+	}
+
+	return app.command(name)
+}
+
+func (app *App) warnRedirect(ctx context.Context, from, to string) {
+	fmt.Fprintln(app.stderrIn(ctx), app.trf("warning: %q is deprecated and will be removed; use %q instead", from, to))
+}
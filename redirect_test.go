@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddRedirect(t *testing.T) {
+	var out, errOut strings.Builder
+	var got string
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Stderr = &errOut
+	app.AddCommand(NewCommand("deploy", "test-group", "ship a service", func(c *Command) {
+		c.AppendArg("service", "the service")
+		c.Flags.Bool("force", false, "force it")
+	}, func(c *Command) error {
+		got = c.Arg("service").String()
+		return nil
+	}))
+	app.AddRedirect("push", "deploy")
+
+	if err := app.Run([]string{"app", "push", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "web" || !strings.Contains(errOut.String(), `"push" is deprecated`) || !strings.Contains(errOut.String(), `use "deploy"`) {
+		t.Fatalf("Expected deploy to run with a notice, got %q and %q", got, errOut.String())
+	}
+
+	if cmds := app.Search("push"); len(cmds) != 1 || cmds[0].Name != "deploy" {
+		t.Fatalf("Expected search for the old name to find deploy, got %v", cmds)
+	}
+
+	if c := app.completeLine(context.Background(), "pu"); !reflect.DeepEqual(c, []string{"deploy"}) {
+		t.Fatalf("Unexpected command completions: %v", c)
+	}
+
+	if c := app.completeLine(context.Background(), "push --fo"); !reflect.DeepEqual(c, []string{"--force"}) {
+		t.Fatalf("Unexpected flag completions: %v", c)
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+type repeatFlags struct {
+	every        time.Duration
+	untilSuccess bool
+	maxRuns      int
+}
+
+func (cmd *Command) addRepeatFlags() {
+	cmd.repeat = &repeatFlags{}
+	cmd.Flags.DurationVar(&cmd.repeat.every, "every", 0, "Run the command repeatedly at this interval")
+	cmd.Flags.BoolVar(&cmd.repeat.untilSuccess, "until-success", false, "With --every, stop after the first successful run")
+	cmd.Flags.IntVar(&cmd.repeat.maxRuns, "max-runs", 0, "With --every, stop after this many runs")
+}
+
+func (cmd *Command) repeating() bool {
+	return cmd.repeat != nil && cmd.repeat.every > 0
+}
+
+// withRepeat runs run every rf.every until the context is canceled, the run
+// limit is reached or, with untilSuccess, a run succeeds. Failed runs are
+// printed and the last error is returned.
+func withRepeat(run RunFunc, rf *repeatFlags) RunFunc {
+	return func(cmd *Command) error {
+		ctx := cmd.Context()
+
+		for runs := 1; ; runs++ {
+			err := run(cmd)
+
+			if err == nil && rf.untilSuccess {
+				return nil
+			}
+
+			if rf.maxRuns > 0 && runs >= rf.maxRuns {
+				return err
+			}
+
+			if err != nil {
+				fmt.Fprintln(cmd.stderr(), cmd.app.trf("%s: run %d failed: %v", cmd.Name, runs, err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(rf.every):
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRepeat(t *testing.T) {
+	var errOut strings.Builder
+	runs := 0
+
+	app := NewApp()
+	app.Stderr = &errOut
+
+	c := NewCommand("poll", "test-group", "polls", func(c *Command) {}, func(c *Command) error {
+		runs++
+		if runs < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+	c.Repeatable = true
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "poll", "--every", "1ms", "--until-success"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs != 3 {
+		t.Fatalf("Expected 3 runs, got %d", runs)
+	}
+
+	if strings.Count(errOut.String(), "not ready") != 2 {
+		t.Fatalf("Expected failed runs to be printed, got %q", errOut.String())
+	}
+
+	runs = 0
+
+	if err := app.Run([]string{"app", "poll", "--every", "1ms", "--until-success=false", "--max-runs", "2"}); err == nil || runs != 2 {
+		t.Fatalf("Expected 2 runs ending in an error, got %d and %v", runs, err)
+	}
+}
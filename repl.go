@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const shellHistoryMax = 1000
+
+// EnableShell registers a shell command which starts RunShell.
+func (app *App) EnableShell() {
+	app.AddCommand(NewCommand("shell", "Shell", "Start an interactive session",
+		func(cmd *Command) {},
+		func(cmd *Command) error {
+			return app.runShell(cmd.Context())
+		}))
+}
+
+// RunShell runs commands read one line at a time from stdin until EOF or
+// "exit". On a terminal lines can be edited, recalled from history and
+// completed with tab.
+func (app *App) RunShell() error {
+	return app.runShell(context.Background())
+}
+
+func (app *App) runShell(ctx context.Context) error {
+	interactive := app.interactive(ctx)
+
+	le := &lineEditor{
+		in:      app.stdinIn(ctx),
+		out:     app.stderrIn(ctx),
+		prompt:  app.name() + "> ",
+		history: app.loadShellHistory(),
+		complete: func(line string) []string {
+			return app.completeLine(ctx, line)
+		},
+	}
+
+	var lines *bufio.Reader
+	if !interactive {
+		lines = bufio.NewReader(app.stdinIn(ctx))
+	}
+
+	for {
+		var line string
+		var err error
+
+		if interactive {
+			line, err = app.readShellLine(le, app.plainIn(ctx))
+		} else {
+			line, err = lines.ReadString('\n')
+			if err == io.EOF && line != "" {
+				err = nil
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if app.dispatchShellLine(ctx, line) {
+			break
+		}
+	}
+
+	if interactive {
+		app.saveShellHistory(le.history)
+	}
+
+	return nil
+}
+
+func (app *App) readShellLine(le *lineEditor, plain bool) (string, error) {
+	if !plain {
+		if restore, err := rawTerminal(le.in); err == nil {
+			defer restore()
+			return le.readLine()
+		}
+	}
+
+	fmt.Fprint(le.out, le.prompt)
+	return readLine(le.in)
+}
+
+// dispatchShellLine runs one line of input and reports whether the shell
+// should exit.
+func (app *App) dispatchShellLine(ctx context.Context, line string) bool {
+	words, err := splitArgs(line)
+	if err != nil {
+		fmt.Fprintln(app.stderrIn(ctx), err)
+		return false
+	}
+
+	if len(words) == 0 || strings.HasPrefix(words[0], "#") {
+		return false
+	}
+
+	switch words[0] {
+	case "exit", "quit":
+		return true
+	case "help":
+		words[0] = "--help"
+	case "shell":
+		fmt.Fprintln(app.stderrIn(ctx), app.tr("Already in a shell"))
+		return false
+	}
+
+	if err := app.RunContext(ctx, append([]string{app.name()}, words...)); err != nil {
+		var ue *UsageErr
+		if errors.As(err, &ue) {
+			ue.ShowUsage()
+		} else {
+			fmt.Fprintln(app.stderrIn(ctx), app.trf("error: %v", err))
+		}
+	}
+
+	return false
+}
+
+func (app *App) shellHistoryPath() string {
+	return filepath.Join(app.dataDir(), "shell_history")
+}
+
+func (app *App) loadShellHistory() []string {
+	b, err := os.ReadFile(app.shellHistoryPath())
+	if err != nil {
+		return nil
+	}
+
+	history := []string{}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) != "" {
+			history = append(history, line)
+		}
+	}
+
+	return history
+}
+
+// saveShellHistory writes history with the values of secret flags and args
+// redacted.
+func (app *App) saveShellHistory(history []string) {
+	if len(history) > shellHistoryMax {
+		history = history[len(history)-shellHistoryMax:]
+	}
+
+	history = append([]string{}, history...)
+
+	for i, line := range history {
+		words, err := splitArgs(line)
+		if err != nil {
+			continue
+		}
+
+		if words, ok := app.redactShellWords(words); ok {
+			for j, w := range words {
+				words[j] = displayQuote(w)
+			}
+
+			history[i] = strings.Join(words, " ")
+		}
+	}
+
+	path := app.shellHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+}
+
+// redactShellWords resolves words, a shell line without the program name, to
+// a command and replaces the values of its secret flags and args, as the
+// history does. It reports whether anything was redacted.
+func (app *App) redactShellWords(words []string) ([]string, bool) {
+	args, _ := app.globalArgs(append([]string{app.name()}, words...))
+	global := words[:len(words)-len(args)+1]
+
+	if app.aliases {
+		if expanded, err := app.expandAliases(context.Background(), args); err == nil {
+			args = expanded
+		}
+	}
+
+	if len(args) < 2 {
+		return words, false
+	}
+
+	cmd, ok := app.command(args[1])
+	if !ok {
+		return words, false
+	}
+
+	var rest []string
+	var redacted bool
+
+	if cmd.mount != nil {
+		rest, redacted = cmd.mount.redactShellWords(args[2:])
+	} else {
+		_, release := hold(context.Background(), cmd)
+		rest, redacted = cmd.redactArgs(args[2:])
+		release()
+	}
+
+	if !redacted {
+		return words, false
+	}
+
+	out := append(append([]string{}, global...), args[1])
+	return append(out, rest...), true
+}
+
+func (cmd *Command) flagNames() []string {
+	names := []string{}
+
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if cmd.hiddenFlags[f.Name] {
+			return
+		}
+
+		names = append(names, f.Name)
+		if cmd.negatable("no-"+f.Name) != nil {
+			names = append(names, "no-"+f.Name)
+		}
+	})
+
+	return names
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		line  string
+		words []string
+	}{
+		{"deploy web", []string{"deploy", "web"}},
+		{"  deploy   web  ", []string{"deploy", "web"}},
+		{`say "hello world"`, []string{"say", "hello world"}},
+		{`say 'it''s'`, []string{"say", "its"}},
+		{`say "a \"b\""`, []string{"say", `a "b"`}},
+		{`say a\ b`, []string{"say", "a b"}},
+		{`say ""`, []string{"say", ""}},
+	}
+
+	for _, tc := range testCases {
+		words, err := splitArgs(tc.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(words, tc.words) {
+			t.Fatalf("Expected %q for %q, got %q", tc.words, tc.line, words)
+		}
+	}
+
+	if _, err := splitArgs(`say "oops`); err != errUnterminatedQuote {
+		t.Fatalf("Expected an unterminated quote error, got %v", err)
+	}
+}
+
+func TestAppCompleteLine(t *testing.T) {
+	app := NewApp()
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.Flags.Bool("force", false, "force it")
+		c.Flags.Bool("fast", false, "go fast")
+	}, nil))
+	app.AddCommand(NewCommand("delete", "test-group", "deletes", func(c *Command) {}, nil))
+
+	testCases := []struct {
+		line        string
+		completions []string
+	}{
+		{"de", []string{"delete", "deploy"}},
+		{"dep", []string{"deploy"}},
+		{"deploy --f", []string{"--fast", "--force"}},
+		{"deploy we", []string{}},
+		{"__desc", []string{}},
+	}
+
+	for _, tc := range testCases {
+		if c := app.completeLine(context.Background(), tc.line); !reflect.DeepEqual(c, tc.completions) {
+			t.Fatalf("Expected %v for %q, got %v", tc.completions, tc.line, c)
+		}
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	app := NewApp()
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {}, nil))
+
+	var out strings.Builder
+
+	le := &lineEditor{
+		in:  strings.NewReader("dep\tweb\r\x1b[A\x7f\x7fx\r\x04"),
+		out: &out,
+		complete: func(line string) []string {
+			return app.completeLine(context.Background(), line)
+		},
+	}
+
+	for _, expected := range []string{"deploy web", "deploy wx"} {
+		line, err := le.readLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line != expected {
+			t.Fatalf("Expected %q, got %q", expected, line)
+		}
+	}
+
+	if _, err := le.readLine(); err != io.EOF {
+		t.Fatalf("Expected EOF, got %v", err)
+	}
+
+	if !reflect.DeepEqual(le.history, []string{"deploy web", "deploy wx"}) {
+		t.Fatalf("Unexpected history %v", le.history)
+	}
+}
+
+func TestAppDispatchShellLine(t *testing.T) {
+	var got []string
+
+	app := NewApp()
+	app.AddCommand(NewCommand("say", "test-group", "says things", func(c *Command) {
+		c.AppendVarArg("words", "the words")
+	}, func(c *Command) error {
+		for _, v := range c.VarArgs() {
+			got = append(got, v.String())
+		}
+
+		return nil
+	}))
+
+	if app.dispatchShellLine(context.Background(), `say "hello world" again`) {
+		t.Fatal("Expected the shell to continue")
+	}
+
+	if !reflect.DeepEqual(got, []string{"hello world", "again"}) {
+		t.Fatalf("Unexpected args %v", got)
+	}
+
+	if !app.dispatchShellLine(context.Background(), "exit") {
+		t.Fatal("Expected exit to end the shell")
+	}
+}
+
+func TestShellHistory(t *testing.T) {
+	app := NewApp()
+	app.DataDir = t.TempDir()
+
+	if err := os.WriteFile(app.shellHistoryPath(), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if h := app.loadShellHistory(); len(h) != 0 {
+		t.Fatalf("Expected no entries from an empty file, got %q", h)
+	}
+
+	if err := os.WriteFile(app.shellHistoryPath(), []byte("status\n\n  \ndeploy web\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if h := app.loadShellHistory(); !reflect.DeepEqual(h, []string{"status", "deploy web"}) {
+		t.Fatalf("Expected blank lines to be dropped, got %q", h)
+	}
+}
+
+func TestShellHistoryRedacted(t *testing.T) {
+	app := NewApp()
+	app.DataDir = t.TempDir()
+
+	app.AddCommand(NewCommand("login", "test-group", "logs in", func(c *Command) {
+		c.Flags.String("token", "", "the token")
+		c.MarkSecret("token")
+		c.AppendArg("user", "the user")
+	}, func(c *Command) error {
+		return nil
+	}))
+
+	app.saveShellHistory([]string{"login --token hunter2 alice", "--plain login --token=hunter2 alice", "status"})
+
+	expected := []string{"login --token '***' alice", "--plain login '--token=***' alice", "status"}
+	if h := app.loadShellHistory(); !reflect.DeepEqual(h, expected) {
+		t.Fatalf("Expected %q, got %q", expected, h)
+	}
+}
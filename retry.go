@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of a command's Run.
+type RetryPolicy struct {
+	// Attempts is the total number of tries, including the first.
+	Attempts int
+
+	// Backoff is the base delay before the second attempt. It doubles on
+	// every further attempt, up to MaxBackoff, and is jittered.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable reports whether err is worth retrying. By default every
+	// error except a canceled context is retried.
+	Retryable func(err error) bool
+}
+
+func (rp *RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if rp.Retryable != nil {
+		return rp.Retryable(err)
+	}
+
+	return true
+}
+
+func (rp *RetryPolicy) delay(attempt int) time.Duration {
+	if rp.Backoff <= 0 {
+		return 0
+	}
+
+	limit := time.Duration(math.MaxInt64)
+	if rp.MaxBackoff > 0 {
+		limit = rp.MaxBackoff
+	}
+
+	// Double up to the limit, saturating rather than overflowing
+	d := rp.Backoff
+	for i := 1; i < attempt && d < limit; i++ {
+		if d > limit/2 {
+			d = limit
+		} else {
+			d *= 2
+		}
+	}
+
+	if d > limit {
+		d = limit
+	}
+
+	// Equal jitter: keep half the delay and randomize the other half
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+
+	return time.Duration(half + rand.Int63n(half))
+}
+
+func withRetry(run RunFunc, rp *RetryPolicy) RunFunc {
+	return func(cmd *Command) error {
+		var err error
+
+		for attempt := 1; ; attempt++ {
+			if attempt > 1 {
+				fmt.Fprintln(cmd.Stderr, cmd.app.trf("%s: attempt %d/%d", cmd.Name, attempt, rp.Attempts))
+			}
+
+			err = run(cmd)
+			if err == nil || attempt >= rp.Attempts || !rp.retryable(err) {
+				return err
+			}
+
+			fmt.Fprintln(cmd.Stderr, cmd.app.trf("%s: attempt %d/%d failed: %v", cmd.Name, attempt, rp.Attempts, err))
+
+			timer := time.NewTimer(rp.delay(attempt))
+
+			select {
+			case <-timer.C:
+			case <-cmd.Context().Done():
+				timer.Stop()
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+func TestCmdRetry(t *testing.T) {
+	attempts := 0
+
+	app := NewApp()
+
+	c := NewCommand("flaky", "test-group", "fails twice", func(c *Command) {}, func(c *Command) error {
+		attempts++
+		if attempts < 3 {
+			return errFlaky
+		}
+
+		return nil
+	})
+	c.Retry = &RetryPolicy{Attempts: 5, Backoff: time.Millisecond}
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "flaky"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", attempts)
+	}
+
+	attempts = 0
+	c.Retry.Retryable = func(err error) bool {
+		return err != errFlaky
+	}
+
+	if err := app.Run([]string{"app", "flaky"}); err != errFlaky {
+		t.Fatalf("Expected errFlaky, got %v", err)
+	}
+
+	if attempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	rp := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for attempt := 1; attempt < 10; attempt++ {
+		d := rp.delay(attempt)
+		if d > rp.MaxBackoff || d < rp.Backoff/2 {
+			t.Fatalf("Delay %s out of range for attempt %d", d, attempt)
+		}
+	}
+}
+
+func TestRetryPolicyDelaySaturates(t *testing.T) {
+	rp := &RetryPolicy{Backoff: time.Second}
+
+	for _, attempt := range []int{40, 64, 65, 1000, 1 << 30} {
+		if d := rp.delay(attempt); d < time.Duration(math.MaxInt64/2) {
+			t.Fatalf("Expected attempt %d to saturate, got %s", attempt, d)
+		}
+	}
+}
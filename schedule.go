@@ -0,0 +1,551 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ScheduleEntry is a command line of the app run by the system scheduler.
+// Args start with the command name.
+type ScheduleEntry struct {
+	Spec string   `json:"spec"`
+	Args []string `json:"args"`
+
+	// Name identifies a Windows scheduled task.
+	Name string `json:"name,omitempty"`
+
+	// line is the crontab line of the entry.
+	line string
+}
+
+// scheduler manages the app's entries in the system scheduler.
+type scheduler interface {
+	list() ([]ScheduleEntry, error)
+	add(e ScheduleEntry) error
+	remove(e ScheduleEntry) error
+}
+
+// systemScheduler returns app.scheduler if set, and otherwise the platform's
+// scheduler.
+func (app *App) systemScheduler() (scheduler, error) {
+	if app.scheduler != nil {
+		return app.scheduler, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	if runtime.GOOS == "windows" {
+		return &schtasks{app: app, exe: exe}, nil
+	}
+
+	return &crontab{tag: "# " + app.serviceName() + " schedule", exe: exe, read: readCrontab, write: writeCrontab}, nil
+}
+
+// EnableSchedule registers a schedule command which runs the app's own
+// commands periodically using crontab, or the Task Scheduler on Windows:
+//
+//	app schedule add "0 3 * * *" backup --full
+//
+// The command line is checked like it would be when run before the
+// schedule is installed.
+func (app *App) EnableSchedule() {
+	app.AddCommand(NewCommand("schedule", "Shell", "Manage scheduled commands: list, add <cron spec> <command> [args...], remove <n>",
+		func(cmd *Command) {
+			cmd.AppendVarArg("action", "list, add or remove, followed by its arguments")
+		},
+		func(cmd *Command) error {
+			var args []string
+			for _, a := range cmd.VarArgs() {
+				args = append(args, a.String())
+			}
+
+			s, err := app.systemScheduler()
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case args[0] == "list" && len(args) == 1:
+				entries, err := s.list()
+				if err != nil {
+					return err
+				}
+
+				for i, e := range entries {
+					fmt.Fprintf(cmd.Stdout, "%-3d %-15s %s\n", i+1, e.Spec, shellJoin(e.Args))
+				}
+
+				return nil
+			case args[0] == "add" && len(args) >= 3:
+				e := ScheduleEntry{Spec: strings.Join(strings.Fields(args[1]), " "), Args: args[2:]}
+
+				if err := validateCron(e.Spec); err != nil {
+					return err
+				}
+
+				if to, ok := app.redirect(e.Args[0]); ok {
+					e.Args[0] = to
+				}
+
+				if err := app.checkCommandLine(cmd, e.Args); err != nil {
+					return err
+				}
+
+				e.Args = append(app.mountPath(), e.Args...)
+
+				if err := s.add(e); err != nil {
+					return err
+				}
+
+				fmt.Fprintln(cmd.Stdout, app.trf("Scheduled %s at %q", shellJoin(e.Args), e.Spec))
+				return nil
+			case args[0] == "remove" && len(args) == 2:
+				entries, err := s.list()
+				if err != nil {
+					return err
+				}
+
+				n, err := strconv.Atoi(args[1])
+				if err != nil || n < 1 || n > len(entries) {
+					return errors.New(app.trf("no scheduled command %s; see schedule list", args[1]))
+				}
+
+				return s.remove(entries[n-1])
+			}
+
+			return cmd.usageErr("Invalid schedule action", ErrArgCount)
+		}))
+}
+
+// checkCommandLine returns an error if args, starting with a command name,
+// would not parse.
+func (app *App) checkCommandLine(from *Command, args []string) error {
+	cmd, ok := app.command(args[0])
+	if !ok {
+		return fmt.Errorf("no command named %q", args[0])
+	}
+
+	if cmd.mount != nil {
+		if len(args) < 2 {
+			return fmt.Errorf("%s needs a command", args[0])
+		}
+
+		return cmd.mount.checkCommandLine(from, args[1:])
+	}
+
+	_, release := hold(from.Context(), cmd)
+	defer release()
+
+	app.prepare(cmd)
+
+	_, err := cmd.ParseStrict(args[1:])
+	return err
+}
+
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'\\$`;&|<>()*?#~%") {
+			a = shellQuote("sh", a)
+		}
+
+		quoted[i] = a
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+	names    []string
+}{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{"day of week", 0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// validateCron returns an error unless spec is a five field cron
+// expression or one of the @ macros.
+func validateCron(spec string) error {
+	if _, ok := cronMacros[spec]; ok || spec == "@reboot" {
+		return nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("invalid cron spec %q: expected 5 fields", spec)
+	}
+
+	for i, f := range fields {
+		for _, part := range strings.Split(f, ",") {
+			if err := checkCronPart(i, part); err != nil {
+				return fmt.Errorf("invalid cron spec %q: %s: %v", spec, cronFields[i].name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkCronPart(field int, part string) error {
+	rng, step, stepped := strings.Cut(part, "/")
+	if stepped {
+		if n, err := strconv.Atoi(step); err != nil || n < 1 {
+			return fmt.Errorf("bad step %q", step)
+		}
+	}
+
+	if rng == "*" {
+		return nil
+	}
+
+	lo, hi, isRange := strings.Cut(rng, "-")
+
+	a, err := cronValue(field, lo)
+	if err != nil {
+		return err
+	}
+
+	if isRange {
+		b, err := cronValue(field, hi)
+		if err != nil {
+			return err
+		}
+
+		if b < a {
+			return fmt.Errorf("bad range %q", rng)
+		}
+	}
+
+	return nil
+}
+
+func cronValue(field int, s string) (int, error) {
+	f := cronFields[field]
+
+	for i, n := range f.names {
+		if strings.EqualFold(s, n) {
+			return i + f.min, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+
+	return v, nil
+}
+
+// crontab keeps entries in the user's crontab, marked with tag so other
+// lines are left alone.
+type crontab struct {
+	tag   string
+	exe   string
+	read  func() (string, error)
+	write func(string) error
+}
+
+func readCrontab() (string, error) {
+	out, err := exec.Command("crontab", "-l").Output()
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && strings.Contains(string(ee.Stderr), "no crontab") {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("crontab -l: %v", err)
+	}
+
+	return string(out), nil
+}
+
+func writeCrontab(s string) error {
+	c := exec.Command("crontab", "-")
+	c.Stdin = strings.NewReader(s)
+
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("crontab: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func (ct *crontab) lines() ([]string, error) {
+	s, err := ct.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), nil
+}
+
+func (ct *crontab) save(lines []string) error {
+	s := strings.Join(lines, "\n")
+	if s != "" {
+		s += "\n"
+	}
+
+	return ct.write(s)
+}
+
+func (ct *crontab) list() ([]ScheduleEntry, error) {
+	lines, err := ct.lines()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ScheduleEntry
+
+	for _, l := range lines {
+		if e, ok := ct.parse(l); ok {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+func (ct *crontab) parse(line string) (ScheduleEntry, bool) {
+	entry, ok := strings.CutSuffix(line, " "+ct.tag)
+	if !ok {
+		return ScheduleEntry{}, false
+	}
+
+	n := len(cronFields)
+	if strings.HasPrefix(entry, "@") {
+		n = 1
+	}
+
+	var spec []string
+
+	rest := entry
+	for i := 0; i < n; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			return ScheduleEntry{}, false
+		}
+
+		spec, rest = append(spec, rest[:end]), rest[end:]
+	}
+
+	words, err := splitArgs(strings.ReplaceAll(rest, `\%`, "%"))
+	if err != nil || len(words) < 2 {
+		return ScheduleEntry{}, false
+	}
+
+	return ScheduleEntry{Spec: strings.Join(spec, " "), Args: words[1:], line: line}, true
+}
+
+func (ct *crontab) line(e ScheduleEntry) string {
+	// cron turns an unescaped % into a newline
+	command := strings.ReplaceAll(shellJoin(append([]string{ct.exe}, e.Args...)), "%", `\%`)
+
+	return fmt.Sprintf("%s %s %s", e.Spec, command, ct.tag)
+}
+
+func (ct *crontab) add(e ScheduleEntry) error {
+	lines, err := ct.lines()
+	if err != nil {
+		return err
+	}
+
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+
+	return ct.save(append(lines, ct.line(e)))
+}
+
+func (ct *crontab) remove(e ScheduleEntry) error {
+	lines, err := ct.lines()
+	if err != nil {
+		return err
+	}
+
+	for i, l := range lines {
+		if l == e.line {
+			return ct.save(append(lines[:i], lines[i+1:]...))
+		}
+	}
+
+	return fmt.Errorf("%s is not scheduled", shellJoin(e.Args))
+}
+
+const scheduleStateKey = "schedule/tasks"
+
+// schtasks keeps entries as tasks in the Windows Task Scheduler, recording
+// them in the app's state since tasks cannot be read back portably.
+type schtasks struct {
+	app *App
+	exe string
+}
+
+func (st *schtasks) list() ([]ScheduleEntry, error) {
+	var entries []ScheduleEntry
+	_, err := stateStoreFor(st.app.dataDir()).get(scheduleStateKey, &entries)
+	return entries, err
+}
+
+func (st *schtasks) add(e ScheduleEntry) error {
+	sched, err := schtasksSchedule(e.Spec)
+	if err != nil {
+		return err
+	}
+
+	entries, err := st.list()
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, o := range entries {
+		taken[o.Name] = true
+	}
+
+	for i := 1; e.Name == "" || taken[e.Name]; i++ {
+		e.Name = fmt.Sprintf(`\%s\%d`, st.app.serviceName(), i)
+	}
+
+	tr := []string{windowsQuote(st.exe)}
+	for _, a := range e.Args {
+		tr = append(tr, windowsQuote(a))
+	}
+
+	args := append([]string{"/Create", "/F", "/TN", e.Name, "/TR", strings.Join(tr, " ")}, sched...)
+	if err := runSchtasks(args...); err != nil {
+		return err
+	}
+
+	return stateStoreFor(st.app.dataDir()).set(scheduleStateKey, append(entries, e))
+}
+
+func (st *schtasks) remove(e ScheduleEntry) error {
+	entries, err := st.list()
+	if err != nil {
+		return err
+	}
+
+	if err := runSchtasks("/Delete", "/F", "/TN", e.Name); err != nil {
+		return err
+	}
+
+	for i, o := range entries {
+		if o.Name == e.Name {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+
+	return stateStoreFor(st.app.dataDir()).set(scheduleStateKey, entries)
+}
+
+func runSchtasks(args ...string) error {
+	if out, err := exec.Command("schtasks", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("schtasks: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// schtasksSchedule turns spec into schtasks /Create schedule options. Only
+// cron expressions the Task Scheduler has an equivalent for are supported:
+// every N minutes, hourly, daily, weekly on one day and monthly on one day.
+func schtasksSchedule(spec string) ([]string, error) {
+	if spec == "@reboot" {
+		return []string{"/SC", "ONSTART"}, nil
+	}
+
+	if m, ok := cronMacros[spec]; ok {
+		spec = m
+	}
+
+	if err := validateCron(spec); err != nil {
+		return nil, err
+	}
+
+	f := strings.Fields(spec)
+	unsupported := fmt.Errorf("cron spec %q has no Task Scheduler equivalent", spec)
+
+	num := func(i int) (int, bool) {
+		v, err := cronValue(i, f[i])
+		return v, err == nil
+	}
+
+	if f[1] == "*" && f[2] == "*" && f[3] == "*" && f[4] == "*" {
+		if f[0] == "*" {
+			return []string{"/SC", "MINUTE", "/MO", "1"}, nil
+		}
+
+		if step, ok := strings.CutPrefix(f[0], "*/"); ok {
+			return []string{"/SC", "MINUTE", "/MO", step}, nil
+		}
+
+		if m, ok := num(0); ok {
+			return []string{"/SC", "HOURLY", "/ST", fmt.Sprintf("00:%02d", m)}, nil
+		}
+
+		return nil, unsupported
+	}
+
+	m, mok := num(0)
+	h, hok := num(1)
+	if !mok || !hok || f[3] != "*" {
+		return nil, unsupported
+	}
+
+	st := fmt.Sprintf("%02d:%02d", h, m)
+
+	switch {
+	case f[2] == "*" && f[4] == "*":
+		return []string{"/SC", "DAILY", "/ST", st}, nil
+	case f[2] == "*":
+		d, ok := num(4)
+		if !ok {
+			return nil, unsupported
+		}
+
+		days := []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT", "SUN"}
+		return []string{"/SC", "WEEKLY", "/D", days[d], "/ST", st}, nil
+	case f[4] == "*":
+		d, ok := num(2)
+		if !ok {
+			return nil, unsupported
+		}
+
+		return []string{"/SC", "MONTHLY", "/D", strconv.Itoa(d), "/ST", st}, nil
+	}
+
+	return nil, unsupported
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateCron(t *testing.T) {
+	for _, spec := range []string{"0 3 * * *", "*/15 * * * *", "0 9-17 * * mon-fri", "30 2 1,15 jan *", "@daily"} {
+		if err := validateCron(spec); err != nil {
+			t.Fatalf("Expected %q to be valid, got %v", spec, err)
+		}
+	}
+
+	for _, spec := range []string{"0 3 * *", "60 * * * *", "0 5-3 * * *", "*/0 * * * *", "@sometimes"} {
+		if err := validateCron(spec); err == nil {
+			t.Fatalf("Expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestSchtasksSchedule(t *testing.T) {
+	testCases := []struct {
+		spec string
+		args []string
+	}{
+		{"*/10 * * * *", []string{"/SC", "MINUTE", "/MO", "10"}},
+		{"5 * * * *", []string{"/SC", "HOURLY", "/ST", "00:05"}},
+		{"0 3 * * *", []string{"/SC", "DAILY", "/ST", "03:00"}},
+		{"30 4 * * fri", []string{"/SC", "WEEKLY", "/D", "FRI", "/ST", "04:30"}},
+		{"@monthly", []string{"/SC", "MONTHLY", "/D", "1", "/ST", "00:00"}},
+	}
+
+	for _, tc := range testCases {
+		args, err := schtasksSchedule(tc.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(args, tc.args) {
+			t.Fatalf("Expected %v for %q, got %v", tc.args, tc.spec, args)
+		}
+	}
+
+	if _, err := schtasksSchedule("0 9-17 * * *"); err == nil {
+		t.Fatal("Expected an error for a range of hours")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	tab := "MAILTO=ops\n0 1 * * * /usr/bin/other\n"
+
+	ct := &crontab{
+		tag:   "# app schedule",
+		exe:   "/usr/bin/app",
+		read:  func() (string, error) { return tab, nil },
+		write: func(s string) error { tab = s; return nil },
+	}
+
+	app := NewApp()
+	app.scheduler = ct
+	app.EnableSchedule()
+	app.AddCommand(NewCommand("backup", "test-group", "backs up", func(c *Command) {
+		c.Flags.Bool("full", false, "back up everything")
+		c.AppendArg("label", "the backup's label")
+	}, nil))
+
+	var out strings.Builder
+	app.Stdout = &out
+
+	if err := app.Run([]string{"app", "schedule", "add", "0  3 * * *", "backup", "--full", "100% done"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "MAILTO=ops\n0 1 * * * /usr/bin/other\n0 3 * * * /usr/bin/app backup --full '100\\% done' # app schedule\n"
+	if tab != expected {
+		t.Fatalf("Expected crontab %q, got %q", expected, tab)
+	}
+
+	for _, args := range [][]string{
+		{"schedule", "add", "0 3 * * *", "nope"},
+		{"schedule", "add", "0 3 * * *", "backup", "--fast", "x"},
+		{"schedule", "add", "0 3 * * *", "backup"},
+		{"schedule", "add", "0 25 * * *", "backup", "x"},
+	} {
+		if err := app.Run(append([]string{"app"}, args...)); err == nil {
+			t.Fatalf("Expected %q to fail", args)
+		}
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"app", "schedule", "list"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "1   0 3 * * *       backup --full '100% done'\n"; out.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, out.String())
+	}
+
+	if err := app.Run([]string{"app", "schedule", "remove", "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if tab != "MAILTO=ops\n0 1 * * * /usr/bin/other\n" {
+		t.Fatalf("Unexpected crontab %q", tab)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// RunScript runs the invocations in r, one per line, stopping at the first
+// failure. Lines are split like a shell would, without the program name;
+// blank lines and lines starting with # are skipped.
+func (app *App) RunScript(r io.Reader) error {
+	return app.runScript(context.Background(), r, false)
+}
+
+func (app *App) runScript(ctx context.Context, r io.Reader, keepGoing bool) error {
+	invocations := [][]string{}
+	sc := bufio.NewScanner(r)
+
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		words, err := splitArgs(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+
+		invocations = append(invocations, words)
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	return app.runSteps(ctx, invocations, keepGoing)
+}
+
+// EnableRunScript registers a run-script command which runs a file of
+// invocations with RunScript, or stdin when the file is "-".
+func (app *App) EnableRunScript() {
+	cmd := NewCommand("run-script", "Shell", "Run commands from a file, one per line",
+		func(cmd *Command) {
+			cmd.AppendArg("file", "The script to run, or - for stdin")
+		},
+		func(cmd *Command) error {
+			r := cmd.stdin()
+
+			if name := cmd.Arg("file").String(); name != "-" {
+				f, err := os.Open(name)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				r = f
+			}
+
+			return app.runScript(cmd.Context(), r, cmd.keepGoingFlag != nil && *cmd.keepGoingFlag)
+		})
+	cmd.SoftFail = true
+
+	app.AddCommand(cmd)
+}
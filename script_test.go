@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunScript(t *testing.T) {
+	var out strings.Builder
+	ran := []string{}
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Stderr = &out
+	app.EnableRunScript()
+
+	app.AddCommand(NewCommand("echo", "test-group", "echoes", func(c *Command) {
+		c.AppendVarArg("words", "the words")
+	}, func(c *Command) error {
+		ran = append(ran, c.Arg("words").String())
+		if c.Arg("words") == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	}))
+
+	script := `# a runbook
+echo 'first step'
+
+echo fail
+echo last
+`
+
+	var be *BatchErr
+
+	if err := app.RunScript(strings.NewReader(script)); !errors.As(err, &be) {
+		t.Fatalf("Expected a BatchErr, got %v", err)
+	}
+
+	if !reflect.DeepEqual(ran, []string{"first step", "fail"}) {
+		t.Fatalf("Unexpected runs: %q", ran)
+	}
+
+	ran = ran[:0]
+	app.Stdin = strings.NewReader(script)
+
+	if err := app.Run([]string{"app", "run-script", "--keep-going", "-"}); !errors.As(err, &be) {
+		t.Fatalf("Expected a BatchErr, got %v", err)
+	}
+
+	if !reflect.DeepEqual(ran, []string{"first step", "fail", "last"}) {
+		t.Fatalf("Unexpected runs: %q", ran)
+	}
+
+	if err := app.RunScript(strings.NewReader("echo 'open\n")); err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+		t.Fatalf("Expected a line error, got %v", err)
+	}
+}
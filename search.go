@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// fuzzyScore reports whether every rune of query appears in target in order,
+// ignoring case, and scores the match. Contiguous runs and matches at the
+// start of target or of a word score higher.
+func fuzzyScore(query, target string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(target))
+
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	score := 0
+	qi := 0
+	prev := -2
+
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+
+		score++
+
+		if ti == prev+1 {
+			score += 2
+		}
+
+		if ti == 0 || strings.ContainsRune(" -_./:", t[ti-1]) {
+			score += 3
+		}
+
+		prev = ti
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+
+	if strings.Contains(string(t), string(q)) {
+		score += 2 * len(q)
+	}
+
+	return score, true
+}
+
+// matchScore scores cmd against query using its name, keywords and
+// description, in decreasing order of weight.
+func (cmd *Command) matchScore(query string) (int, bool) {
+	best, found := 0, false
+
+	consider := func(target string, weight int) {
+		if s, ok := fuzzyScore(query, target); ok && (!found || s*weight > best) {
+			best, found = s*weight, true
+		}
+	}
+
+	consider(cmd.Name, 3)
+
+	for _, k := range cmd.Keywords {
+		consider(k, 2)
+	}
+
+	consider(cmd.Description, 1)
+
+	return best, found
+}
+
+// Search returns the visible commands matching query, best match first.
+func (app *App) Search(query string) []*Command {
+	return app.search(context.Background(), query)
+}
+
+func (app *App) search(ctx context.Context, query string) []*Command {
+	type scored struct {
+		cmd   *Command
+		score int
+	}
+
+	matches := []scored{}
+
+	for _, cmd := range app.Commands {
+		if !app.listed(ctx, cmd) {
+			continue
+		}
+
+		s, ok := cmd.matchScore(query)
+
+		// Old names of renamed commands lead to the new command
+		for from, to := range app.redirects {
+			if to != cmd.Name {
+				continue
+			}
+
+			if rs, rok := fuzzyScore(query, from); rok && (!ok || rs*3 > s) {
+				s, ok = rs*3, true
+			}
+		}
+
+		if ok {
+			matches = append(matches, scored{cmd, s})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+
+		return matches[i].cmd.Name < matches[j].cmd.Name
+	})
+
+	ret := make([]*Command, len(matches))
+	for i, m := range matches {
+		ret[i] = m.cmd
+	}
+
+	return ret
+}
+
+func (app *App) printSearch(ctx context.Context, query string) {
+	w := app.stdoutIn(ctx)
+	matches := app.search(ctx, query)
+
+	if len(matches) == 0 {
+		fmt.Fprintln(w, app.trf("No commands match %q", query))
+		return
+	}
+
+	fmt.Fprintln(w, app.trf("Commands matching %q:", query))
+
+	var names []string
+	for _, cmd := range matches {
+		names = append(names, cmd.Name)
+	}
+
+	width := nameColumn(names)
+
+	for _, cmd := range matches {
+		fmt.Fprintf(w, "    %s %s\n", padRight(app.ltr(cmd.Name), width), cmd.description())
+	}
+}
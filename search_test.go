@@ -0,0 +1,58 @@
+package cmd
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	testCases := []struct {
+		query  string
+		target string
+		match  bool
+	}{
+		{"dep", "deploy", true},
+		{"dpl", "deploy", true},
+		{"DEP", "deploy", true},
+		{"ldp", "deploy", false},
+		{"", "deploy", true},
+	}
+
+	for _, tc := range testCases {
+		if _, ok := fuzzyScore(tc.query, tc.target); ok != tc.match {
+			t.Fatalf("Expected match: %t for %q in %q", tc.match, tc.query, tc.target)
+		}
+	}
+
+	prefix, _ := fuzzyScore("dep", "deploy")
+	scattered, _ := fuzzyScore("dep", "delete-replica")
+	if prefix <= scattered {
+		t.Fatalf("Expected a prefix match to score higher: %d <= %d", prefix, scattered)
+	}
+}
+
+func TestAppSearch(t *testing.T) {
+	app := NewApp()
+
+	deploy := NewCommand("deploy", "test-group", "Ship a release", func(c *Command) {}, nil)
+	rollout := NewCommand("rollout", "test-group", "Gradually ship traffic", func(c *Command) {}, nil)
+	rollout.Keywords = []string{"canary", "release"}
+	status := NewCommand("status", "test-group", "Show the status", func(c *Command) {}, nil)
+
+	app.AddCommand(deploy)
+	app.AddCommand(rollout)
+	app.AddCommand(status)
+
+	matches := app.Search("canary")
+	if len(matches) != 1 || matches[0] != rollout {
+		t.Fatalf("Expected the keyword to match rollout, got %v", matches)
+	}
+
+	matches = app.Search("release")
+	if len(matches) != 2 || matches[0] != rollout {
+		t.Fatalf("Expected the keyword match to rank first, got %v", matches)
+	}
+
+	for _, m := range app.Search("describe") {
+		if m.Hidden {
+			t.Fatal("Hidden commands should not be returned")
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrCanceled = errors.New("canceled")
+
+const pickerHeight = 10
+
+type picker struct {
+	label   string
+	options []string
+	multi   bool
+	plain   bool
+	pointer string
+	app     *App
+	filter  []rune
+	cursor  int
+	chosen  map[int]bool
+
+	// score overrides how options are matched against the filter.
+	score func(i int, filter string) (int, bool)
+}
+
+func newPicker(label string, options []string, multi bool) *picker {
+	return &picker{label: label, options: options, multi: multi, pointer: ">", chosen: map[int]bool{}}
+}
+
+// visible returns the indexes of the options matching the filter, best match
+// first.
+func (p *picker) visible() []int {
+	if len(p.filter) == 0 {
+		ret := make([]int, len(p.options))
+		for i := range ret {
+			ret[i] = i
+		}
+
+		return ret
+	}
+
+	type scored struct {
+		i, score int
+	}
+
+	matches := []scored{}
+	score := p.score
+	if score == nil {
+		score = func(i int, filter string) (int, bool) {
+			return fuzzyScore(filter, p.options[i])
+		}
+	}
+
+	for i := range p.options {
+		if s, ok := score(i, string(p.filter)); ok {
+			matches = append(matches, scored{i, s})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	ret := make([]int, len(matches))
+	for i, m := range matches {
+		ret[i] = m.i
+	}
+
+	return ret
+}
+
+// handle applies a keypress and reports whether the selection is complete.
+func (p *picker) handle(kp keypress) (bool, error) {
+	vis := p.visible()
+
+	switch kp.kind {
+	case keyCancel, keyEOF:
+		return false, ErrCanceled
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.cursor < len(vis)-1 {
+			p.cursor++
+		}
+	case keyBackspace:
+		if len(p.filter) > 0 {
+			p.filter = p.filter[:len(p.filter)-1]
+			p.cursor = 0
+		}
+	case keyTab:
+		if p.multi && p.cursor < len(vis) {
+			p.chosen[vis[p.cursor]] = !p.chosen[vis[p.cursor]]
+		}
+	case keyRune:
+		if kp.r == ' ' && p.multi {
+			if p.cursor < len(vis) {
+				p.chosen[vis[p.cursor]] = !p.chosen[vis[p.cursor]]
+			}
+		} else {
+			p.filter = append(p.filter, kp.r)
+			p.cursor = 0
+		}
+	case keyEnter:
+		if p.multi {
+			if len(p.result()) == 0 && p.cursor < len(vis) {
+				p.chosen[vis[p.cursor]] = true
+			}
+
+			return true, nil
+		}
+
+		return p.cursor < len(vis), nil
+	}
+
+	return false, nil
+}
+
+// result returns the chosen option indexes in their original order.
+func (p *picker) result() []int {
+	if !p.multi {
+		vis := p.visible()
+		if p.cursor < len(vis) {
+			return []int{vis[p.cursor]}
+		}
+
+		return nil
+	}
+
+	ret := []int{}
+	for i := range p.options {
+		if p.chosen[i] {
+			ret = append(ret, i)
+		}
+	}
+
+	return ret
+}
+
+// render draws the picker and returns the number of lines written.
+func (p *picker) render(w io.Writer) int {
+	vis := p.visible()
+
+	hint := p.app.tr("type to filter, enter to choose")
+	if p.multi {
+		hint = p.app.tr("type to filter, space to toggle, enter to confirm")
+	}
+
+	fmt.Fprintf(w, "%s (%s): %s\n", p.label, hint, string(p.filter))
+	lines := 1
+
+	start := 0
+	if p.cursor >= pickerHeight {
+		start = p.cursor - pickerHeight + 1
+	}
+
+	for i := start; i < len(vis) && i < start+pickerHeight; i++ {
+		pointer := strings.Repeat(" ", displayWidth(p.pointer)+1)
+		if i == p.cursor {
+			pointer = p.pointer + " "
+		}
+
+		box := ""
+		if p.multi {
+			box = "[ ] "
+			if p.chosen[vis[i]] {
+				box = "[x] "
+			}
+		}
+
+		fmt.Fprintf(w, "%s%s%s\n", pointer, box, p.options[vis[i]])
+		lines++
+	}
+
+	return lines
+}
+
+func (p *picker) run(in io.Reader, out io.Writer) ([]int, error) {
+	if p.plain {
+		return p.runNumbered(in, out)
+	}
+
+	restore, err := rawTerminal(in)
+	if err != nil {
+		return p.runNumbered(in, out)
+	}
+	defer restore()
+
+	lines := p.render(out)
+
+	for {
+		kp, err := readKey(in)
+		if err != nil {
+			return nil, err
+		}
+
+		done, err := p.handle(kp)
+
+		// Move back up and clear what was drawn last time
+		fmt.Fprintf(out, "\x1b[%dA\x1b[J", lines)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if done {
+			names := []string{}
+			for _, i := range p.result() {
+				names = append(names, p.options[i])
+			}
+
+			fmt.Fprintf(out, "%s: %s\n", p.label, strings.Join(names, ", "))
+			return p.result(), nil
+		}
+
+		lines = p.render(out)
+	}
+}
+
+// runNumbered is the fallback for terminals that can't be put in raw mode,
+// and is used in plain mode.
+func (p *picker) runNumbered(in io.Reader, out io.Writer) ([]int, error) {
+	for i, o := range p.options {
+		fmt.Fprintf(out, "  %d) %s\n", i+1, o)
+	}
+
+	for {
+		if p.multi {
+			fmt.Fprint(out, p.app.trf("%s (numbers separated by commas): ", p.label))
+		} else {
+			fmt.Fprint(out, p.app.trf("%s (number): ", p.label))
+		}
+
+		line, err := readLine(in)
+		if err != nil {
+			return nil, err
+		}
+
+		if ret, ok := p.parseNumbers(line); ok {
+			return ret, nil
+		}
+
+		fmt.Fprintln(out, p.app.tr("Invalid choice"))
+	}
+}
+
+func (p *picker) parseNumbers(line string) ([]int, bool) {
+	ret := []int{}
+
+	for _, f := range strings.Split(line, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 1 || n > len(p.options) {
+			return nil, false
+		}
+
+		ret = append(ret, n-1)
+	}
+
+	if len(ret) == 0 || (!p.multi && len(ret) != 1) {
+		return nil, false
+	}
+
+	return ret, true
+}
+
+// Select lets the user pick one of options on the terminal, with arrow keys
+// and type-to-filter. It returns ErrNotInteractive when stdin is not a
+// terminal.
+func (cmd *Command) Select(label string, options []string) (string, error) {
+	if !cmd.Interactive() {
+		return "", ErrNotInteractive
+	}
+
+	if len(options) == 0 {
+		return "", errors.New(cmd.app.tr("no options to select from"))
+	}
+
+	p := newPicker(label, options, false)
+	p.app = cmd.app
+	p.plain = cmd.Plain()
+	p.pointer = cmd.symbolsFor(cmd.stderr()).Pointer
+
+	idx, err := p.run(cmd.stdin(), cmd.stderr())
+	if err != nil {
+		return "", err
+	}
+
+	return options[idx[0]], nil
+}
+
+// MultiSelect is like Select but lets the user pick any number of options.
+func (cmd *Command) MultiSelect(label string, options []string) ([]string, error) {
+	if !cmd.Interactive() {
+		return nil, ErrNotInteractive
+	}
+
+	if len(options) == 0 {
+		return nil, errors.New(cmd.app.tr("no options to select from"))
+	}
+
+	p := newPicker(label, options, true)
+	p.app = cmd.app
+	p.plain = cmd.Plain()
+	p.pointer = cmd.symbolsFor(cmd.stderr()).Pointer
+
+	idx, err := p.run(cmd.stdin(), cmd.stderr())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]string, len(idx))
+	for i, j := range idx {
+		ret[i] = options[j]
+	}
+
+	return ret, nil
+}
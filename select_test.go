@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadKey(t *testing.T) {
+	r := strings.NewReader("a\x1b[A\x1b[B\r\x7f\x03é\x1b[3~")
+
+	expected := []keypress{
+		{kind: keyRune, r: 'a'},
+		{kind: keyUp},
+		{kind: keyDown},
+		{kind: keyEnter},
+		{kind: keyBackspace},
+		{kind: keyCancel},
+		{kind: keyRune, r: 'é'},
+		{kind: keyUnknown},
+	}
+
+	for i, e := range expected {
+		kp, err := readKey(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if kp != e {
+			t.Fatalf("Expected %+v at %d, got %+v", e, i, kp)
+		}
+	}
+}
+
+func TestPicker(t *testing.T) {
+	options := []string{"us-east-1", "us-west-2", "eu-west-1"}
+
+	p := newPicker("region", options, false)
+
+	for _, kp := range []keypress{{kind: keyRune, r: 'e'}, {kind: keyRune, r: 'u'}} {
+		if done, err := p.handle(kp); done || err != nil {
+			t.Fatalf("Unexpected done: %t, err: %v", done, err)
+		}
+	}
+
+	if vis := p.visible(); len(vis) != 1 || vis[0] != 2 {
+		t.Fatalf("Expected the filter to leave eu-west-1, got %v", vis)
+	}
+
+	if done, _ := p.handle(keypress{kind: keyEnter}); !done {
+		t.Fatal("Expected enter to complete the selection")
+	}
+
+	if r := p.result(); !reflect.DeepEqual(r, []int{2}) {
+		t.Fatalf("Unexpected result %v", r)
+	}
+
+	p = newPicker("regions", options, true)
+
+	keys := []keypress{
+		{kind: keyDown},
+		{kind: keyRune, r: ' '},
+		{kind: keyDown},
+		{kind: keyRune, r: ' '},
+		{kind: keyEnter},
+	}
+
+	for _, kp := range keys {
+		p.handle(kp)
+	}
+
+	if r := p.result(); !reflect.DeepEqual(r, []int{1, 2}) {
+		t.Fatalf("Unexpected result %v", r)
+	}
+
+	if _, err := p.handle(keypress{kind: keyCancel}); err != ErrCanceled {
+		t.Fatalf("Expected ErrCanceled, got %v", err)
+	}
+}
+
+func TestPickerNumbered(t *testing.T) {
+	p := newPicker("region", []string{"a", "b", "c"}, true)
+
+	var out strings.Builder
+
+	r, err := p.runNumbered(strings.NewReader("4\n1, 3\n"), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(r, []int{0, 2}) {
+		t.Fatalf("Unexpected result %v", r)
+	}
+
+	if !strings.Contains(out.String(), "Invalid choice") {
+		t.Fatal("Expected the out of range choice to be rejected")
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type semver struct {
+	major, minor, patch int
+	pre                 []string
+}
+
+func parseSemver(s string) (semver, error) {
+	var v semver
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || parts[0] == "" {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+
+	nums := []*int{&v.major, &v.minor, &v.patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+func (v semver) compare(o semver) int {
+	for _, d := range []int{v.major - o.major, v.minor - o.minor, v.patch - o.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	// A pre-release sorts before the release itself
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		a, b := v.pre[i], o.pre[i]
+		an, aerr := strconv.Atoi(a)
+		bn, berr := strconv.Atoi(b)
+
+		switch {
+		case aerr == nil && berr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aerr == nil:
+			return -1
+		case berr == nil:
+			return 1
+		case a != b:
+			return sign(strings.Compare(a, b))
+		}
+	}
+
+	return sign(len(v.pre) - len(o.pre))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+
+	return 0
+}
+
+// compareVersions returns -1, 0 or 1 as semantic version a is older than,
+// equal to or newer than b.
+func compareVersions(a, b string) (int, error) {
+	va, err := parseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+
+	vb, err := parseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return va.compare(vb), nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		c    int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.3", "2.0.0", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+
+	for _, tc := range testCases {
+		c, err := compareVersions(tc.a, tc.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c != tc.c {
+			t.Fatalf("Expected %d comparing %s to %s, got %d", tc.c, tc.a, tc.b, c)
+		}
+	}
+
+	if _, err := compareVersions("1.x", "1.0"); err == nil {
+		t.Fatal("Expected an error for an invalid version")
+	}
+}
+
+func TestAppCompat(t *testing.T) {
+	app := NewApp()
+	app.Version = "1.4.2"
+
+	testCases := []struct {
+		min  string
+		code int
+	}{
+		{"1.4.0", 0},
+		{"1.4.2", 0},
+		{"1.5.0", 1},
+		{"banana", 2},
+	}
+
+	for _, tc := range testCases {
+		if code := ExitCode(app.Run([]string{"app", "__compat", tc.min})); code != tc.code {
+			t.Fatalf("Expected exit code %d for %s, got %d", tc.code, tc.min, code)
+		}
+	}
+}
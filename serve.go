@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// ServeRequest asks a serve socket to run a command. Args start with the
+// command name; Stdin is the command's whole input.
+type ServeRequest struct {
+	ID    int      `json:"id"`
+	Args  []string `json:"args"`
+	Stdin string   `json:"stdin,omitempty"`
+}
+
+// ServeMessage is one line sent back for a request: output as it is
+// written, then a final message with Done set.
+type ServeMessage struct {
+	ID       int    `json:"id"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	Done     bool   `json:"done,omitempty"`
+	ExitCode int    `json:"exit_code"`
+	Err      string `json:"error,omitempty"`
+}
+
+// EnableServe registers a serve command which listens on a Unix socket for
+// newline-delimited JSON ServeRequests and runs them like command lines,
+// streaming ServeMessages back. Requests on one connection run in order;
+// connections are served concurrently. Editor plugins and daemons can use it
+// to run the app's commands without starting a process each time.
+func (app *App) EnableServe() {
+	app.AddCommand(NewCommand("serve", "Shell", "Run commands sent over a local socket",
+		func(cmd *Command) {
+			cmd.Flags.String("socket", "", "Path of the socket to listen on (default in the runtime dir)")
+		},
+		func(cmd *Command) error {
+			path := cmd.Flag("socket").String()
+			if path == "" {
+				dir, err := app.runtimeDir()
+				if err != nil {
+					return err
+				}
+
+				path = filepath.Join(dir, "serve.sock")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return app.serve(ctx, path, cmd)
+		}))
+}
+
+func (app *App) serve(ctx context.Context, path string, serveCmd *Command) error {
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return fmt.Errorf("%s is already being served", path)
+	}
+
+	os.Remove(path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	fmt.Fprintln(serveCmd.Stderr, app.trf("Serving on %s", path))
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			app.serveConn(ctx, conn, serveCmd)
+		}()
+	}
+}
+
+func (app *App) serveConn(ctx context.Context, conn net.Conn, serveCmd *Command) {
+	defer conn.Close()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(conn)
+	send := func(m ServeMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(m)
+	}
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for sc.Scan() {
+		var req ServeRequest
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			send(ServeMessage{Done: true, ExitCode: 2, Err: "invalid request: " + err.Error()})
+			continue
+		}
+
+		err := app.serveRequest(ctx, req, serveCmd, send)
+
+		m := ServeMessage{ID: req.ID, Done: true, ExitCode: ExitCode(err)}
+		if err != nil {
+			m.Err = err.Error()
+		}
+
+		send(m)
+	}
+}
+
+func (app *App) serveRequest(ctx context.Context, req ServeRequest, serveCmd *Command, send func(ServeMessage)) error {
+	if len(req.Args) == 0 {
+		return errors.New("no command given")
+	}
+
+	c, ok := app.command(req.Args[0])
+	if !ok {
+		return ErrInvalidCommand
+	} else if c == serveCmd {
+		return errors.New("serve cannot be run over its own socket")
+	}
+
+	stdout := serveWriter(func(p string) { send(ServeMessage{ID: req.ID, Stdout: p}) })
+	stderr := serveWriter(func(p string) { send(ServeMessage{ID: req.ID, Stderr: p}) })
+
+	return app.runWithStreams(ctx, c, req.Args, strings.NewReader(req.Stdin), stdout, stderr)
+}
+
+// serveWriter sends each write as a message.
+type serveWriter func(p string)
+
+func (sw serveWriter) Write(p []byte) (int, error) {
+	sw(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	dir, err := os.MkdirTemp("", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.sock")
+
+	app := NewApp()
+	app.Stderr = io.Discard
+	app.EnableServe()
+	app.AddCommand(NewCommand("upper", "test-group", "uppercases stdin", func(c *Command) {
+		c.AppendArg("prefix", "a prefix")
+	}, func(c *Command) error {
+		b, err := io.ReadAll(c.Stdin)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.Stdout, "%s%s", c.Arg("prefix"), strings.ToUpper(string(b)))
+		fmt.Fprint(c.Stderr, "done")
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- app.RunContext(ctx, []string{"app", "serve", "--socket", path})
+	}()
+
+	var conn net.Conn
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Fprintln(conn, `{"id": 1, "args": ["upper", "> "], "stdin": "hi"}`)
+	fmt.Fprintln(conn, `{"id": 2, "args": ["serve"]}`)
+
+	msgs := []ServeMessage{}
+	sc := bufio.NewScanner(conn)
+
+	for len(msgs) < 4 && sc.Scan() {
+		var m ServeMessage
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+
+		msgs = append(msgs, m)
+	}
+
+	conn.Close()
+
+	expected := []ServeMessage{
+		{ID: 1, Stdout: "> HI"},
+		{ID: 1, Stderr: "done"},
+		{ID: 1, Done: true},
+		{ID: 2, Done: true, ExitCode: 1, Err: "serve cannot be run over its own socket"},
+	}
+
+	if fmt.Sprint(msgs) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, msgs)
+	}
+
+	cancel()
+
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("Expected the socket to be removed")
+	}
+}
+
+func TestServeBadFlag(t *testing.T) {
+	dir, err := os.MkdirTemp("", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.sock")
+
+	app := NewApp()
+	app.Stderr = io.Discard
+	app.EnableServe()
+	app.AddCommand(NewCommand("echo", "test-group", "echoes its argument", func(c *Command) {
+		c.AppendArg("text", "the text")
+	}, func(c *Command) error {
+		fmt.Fprint(c.Stdout, c.Arg("text"))
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- app.RunContext(ctx, []string{"app", "serve", "--socket", path})
+	}()
+
+	var conn net.Conn
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fmt.Fprintln(conn, `{"id": 1, "args": ["echo", "--bogus", "hi"]}`)
+	fmt.Fprintln(conn, `{"id": 2, "args": ["echo", "hi"]}`)
+
+	done := map[int]ServeMessage{}
+	stdout := ""
+	sc := bufio.NewScanner(conn)
+
+	for len(done) < 2 && sc.Scan() {
+		var m ServeMessage
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+
+		if m.Done {
+			done[m.ID] = m
+		} else if m.ID == 2 {
+			stdout += m.Stdout
+		}
+	}
+
+	if m := done[1]; m.ExitCode == 0 || !strings.Contains(m.Err, "-bogus") {
+		t.Fatalf("Expected a usage error for -bogus, got %+v", m)
+	}
+
+	if m := done[2]; m.ExitCode != 0 || stdout != "hi" {
+		t.Fatalf("Expected the server to keep serving, got %+v with stdout %q", m, stdout)
+	}
+
+	cancel()
+
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
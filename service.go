@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// ServiceKind is a service manager GenServiceFile can write for.
+type ServiceKind string
+
+const (
+	ServiceSystemd ServiceKind = "systemd"
+	ServiceLaunchd ServiceKind = "launchd"
+)
+
+// ServiceOptions describes the service GenServiceFile writes.
+type ServiceOptions struct {
+	// Args are the command line run as the service, e.g. ["start",
+	// "--foreground"].
+	Args []string
+
+	// Binary is the program to run, the running executable by default.
+	Binary string
+
+	// Description defaults to the App's.
+	Description string
+
+	// Env is set for the service, as KEY=VALUE.
+	Env []string
+
+	// Dir is the service's working directory.
+	Dir string
+
+	// LogFile receives the service's stdout and stderr under launchd.
+	// systemd sends them to the journal.
+	LogFile string
+}
+
+// GenServiceFile returns a systemd user unit or a launchd agent plist that
+// runs opts.Args as a service named after the App, restarted if it fails.
+func (app *App) GenServiceFile(kind ServiceKind, opts ServiceOptions) ([]byte, error) {
+	if opts.Binary == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Binary = exe
+	}
+
+	if opts.Description == "" {
+		opts.Description = app.Description
+	}
+
+	if opts.Description == "" {
+		opts.Description = app.name()
+	}
+
+	for _, kv := range opts.Env {
+		if !strings.Contains(kv, "=") {
+			return nil, fmt.Errorf("env %q is not KEY=VALUE", kv)
+		}
+	}
+
+	switch kind {
+	case ServiceSystemd:
+		return systemdUnit(opts), nil
+	case ServiceLaunchd:
+		return launchdPlist(app.serviceName(), opts), nil
+	}
+
+	return nil, fmt.Errorf("Unsupported service kind %q", kind)
+}
+
+// serviceName is the App name usable as a file name, with mount prefixes
+// joined by dashes.
+func (app *App) serviceName() string {
+	return strings.ReplaceAll(app.name(), " ", "-")
+}
+
+func systemdUnit(opts ServiceOptions) []byte {
+	var b bytes.Buffer
+
+	exec := []string{systemdQuote(opts.Binary)}
+	for _, a := range opts.Args {
+		exec = append(exec, systemdQuote(a))
+	}
+
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\n\n[Service]\n", systemdEscape(opts.Description))
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(exec, " "))
+	fmt.Fprint(&b, "Restart=on-failure\n")
+
+	if opts.Dir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(opts.Dir))
+	}
+
+	for _, kv := range opts.Env {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(kv))
+	}
+
+	fmt.Fprint(&b, "\n[Install]\nWantedBy=default.target\n")
+
+	return b.Bytes()
+}
+
+// systemdEscape escapes the specifier and variable expansions systemd does
+// in unit values.
+func systemdEscape(s string) string {
+	return strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+}
+
+func systemdQuote(s string) string {
+	s = systemdEscape(s)
+
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\;") {
+		return s
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func launchdPlist(label string, opts ServiceOptions) []byte {
+	var b bytes.Buffer
+
+	str := func(s string) string {
+		var e bytes.Buffer
+		xml.EscapeText(&e, []byte(s))
+		return "<string>" + e.String() + "</string>"
+	}
+
+	fmt.Fprint(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t%s\n", str(label))
+	fmt.Fprint(&b, "\t<key>ProgramArguments</key>\n\t<array>\n")
+
+	for _, a := range append([]string{opts.Binary}, opts.Args...) {
+		fmt.Fprintf(&b, "\t\t%s\n", str(a))
+	}
+
+	fmt.Fprint(&b, "\t</array>\n\t<key>RunAtLoad</key>\n\t<true/>\n")
+	fmt.Fprint(&b, "\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+
+	if opts.Dir != "" {
+		fmt.Fprintf(&b, "\t<key>WorkingDirectory</key>\n\t%s\n", str(opts.Dir))
+	}
+
+	if len(opts.Env) > 0 {
+		env := append([]string(nil), opts.Env...)
+		sort.Strings(env)
+
+		fmt.Fprint(&b, "\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, kv := range env {
+			k, v, _ := strings.Cut(kv, "=")
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t%s\n", k, str(v))
+		}
+		fmt.Fprint(&b, "\t</dict>\n")
+	}
+
+	if opts.LogFile != "" {
+		fmt.Fprintf(&b, "\t<key>StandardOutPath</key>\n\t%s\n", str(opts.LogFile))
+		fmt.Fprintf(&b, "\t<key>StandardErrorPath</key>\n\t%s\n", str(opts.LogFile))
+	}
+
+	fmt.Fprint(&b, "</dict>\n</plist>\n")
+
+	return b.Bytes()
+}
+
+// servicePath is where the user's service manager looks for a service file
+// of kind.
+func (app *App) servicePath(kind ServiceKind) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case ServiceSystemd:
+		return filepath.Join(userConfigDir(app.getenv), "systemd", "user", app.serviceName()+".service"), nil
+	case ServiceLaunchd:
+		return filepath.Join(home, "Library", "LaunchAgents", app.serviceName()+".plist"), nil
+	}
+
+	return "", fmt.Errorf("Unsupported service kind %q", kind)
+}
+
+// EnableInstallService registers an install-service command that writes a
+// service file running the given command line, a launchd agent on macOS and
+// a systemd user unit elsewhere:
+//
+//	app install-service -- start --foreground
+func (app *App) EnableInstallService() {
+	app.AddCommand(NewCommand("install-service", "Shell", "Write a service file that runs a command",
+		func(cmd *Command) {
+			cmd.AppendVarArg("args", "The command line to run as the service")
+			cmd.Flags.String("kind", "", "systemd or launchd (default for this platform)")
+			cmd.Flags.String("output", "", "Path to write the service file to, - for stdout (default where the service manager looks)")
+			cmd.Flags.Bool("force", false, "Overwrite an existing service file")
+		},
+		func(cmd *Command) error {
+			kind := ServiceKind(cmd.Flag("kind").String())
+			if kind == "" {
+				kind = ServiceSystemd
+				if runtime.GOOS == "darwin" {
+					kind = ServiceLaunchd
+				}
+			}
+
+			var args []string
+			for _, a := range cmd.VarArgs() {
+				args = append(args, a.String())
+			}
+
+			if len(args) == 0 {
+				return newUsageErr("no command to run as the service", cmd.Usage)
+			}
+
+			b, err := app.GenServiceFile(kind, ServiceOptions{
+				Args:    append(app.mountPath(), args...),
+				LogFile: filepath.Join(app.dataDir(), app.serviceName()+".log"),
+			})
+			if err != nil {
+				return newUsageErr(err.Error(), cmd.Usage)
+			}
+
+			path := cmd.Flag("output").String()
+			if path == "-" {
+				_, err := cmd.Stdout.Write(b)
+				return err
+			}
+
+			if path == "" {
+				if path, err = app.servicePath(kind); err != nil {
+					return err
+				}
+			}
+
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if force, _ := cmd.Flag("force").Bool(); !force {
+				flags |= os.O_EXCL
+			}
+
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(path, flags, 0644)
+			if os.IsExist(err) {
+				return errors.New(app.trf("%s already exists; use --force to overwrite it", path))
+			} else if err != nil {
+				return err
+			}
+
+			if _, err := f.Write(b); err != nil {
+				f.Close()
+				return err
+			}
+
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.Stdout, app.trf("Wrote %s", path))
+			return nil
+		}))
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenServiceFile(t *testing.T) {
+	app := NewApp()
+	app.Name = "svc"
+	app.Description = "Syncs 100% of things"
+
+	opts := ServiceOptions{
+		Args:    []string{"start", "--foreground", "two words"},
+		Binary:  "/usr/bin/svc",
+		Env:     []string{"MODE=a&b"},
+		LogFile: "/tmp/svc.log",
+	}
+
+	unit, err := app.GenServiceFile(ServiceSystemd, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"Description=Syncs 100%% of things\n",
+		`ExecStart=/usr/bin/svc start --foreground "two words"` + "\n",
+		"Environment=MODE=a&b\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(string(unit), expected) {
+			t.Fatalf("Expected %q in\n%s", expected, unit)
+		}
+	}
+
+	plist, err := app.GenServiceFile(ServiceLaunchd, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"<key>Label</key>\n\t<string>svc</string>\n",
+		"\t\t<string>/usr/bin/svc</string>\n\t\t<string>start</string>\n\t\t<string>--foreground</string>\n\t\t<string>two words</string>\n",
+		"<key>MODE</key>\n\t\t<string>a&amp;b</string>\n",
+		"<key>StandardOutPath</key>\n\t<string>/tmp/svc.log</string>\n",
+	} {
+		if !strings.Contains(string(plist), expected) {
+			t.Fatalf("Expected %q in\n%s", expected, plist)
+		}
+	}
+
+	if _, err := app.GenServiceFile("upstart", opts); err == nil {
+		t.Fatal("Expected an error for an unknown kind")
+	}
+}
+
+func TestInstallService(t *testing.T) {
+	app := NewApp()
+	app.Name = "svc"
+	app.DataDir = t.TempDir()
+	app.EnableInstallService()
+
+	path := filepath.Join(t.TempDir(), "svc.service")
+	args := []string{"svc", "install-service", "--kind", "systemd", "--output", path, "--", "start", "--foreground"}
+
+	if err := app.Run(args); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), " start --foreground\n") {
+		t.Fatalf("Unexpected unit\n%s", b)
+	}
+
+	if err := app.Run(args); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Expected an error about overwriting, got %v", err)
+	}
+}
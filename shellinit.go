@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	shellEnv           = "CMD_SHELL"
+	shellDirectivesEnv = "CMD_SHELL_DIRECTIVES"
+)
+
+var ErrNoShellWrapper = errors.New("the shell wrapper is not installed")
+
+// EnableShellInit registers a shell-init command that prints a shell function
+// wrapping the program. Once installed with
+//
+//	eval "$(app shell-init bash)"
+//
+// commands can change the calling shell's directory or environment with
+// ShellChdir and ShellExport.
+func (app *App) EnableShellInit() {
+	app.AddCommand(NewCommand("shell-init", "Shell", "Print the shell wrapper function for sh, bash, zsh or fish",
+		func(cmd *Command) {
+			cmd.AppendArg("shell", "The shell to generate the wrapper for")
+		},
+		func(cmd *Command) error {
+			script, err := shellWrapper(cmd.Arg("shell").String(), app.name())
+			if err != nil {
+				return newUsageErr(err.Error(), cmd.Usage)
+			}
+
+			fmt.Fprint(cmd.Stdout, script)
+			return nil
+		}))
+}
+
+func shellWrapper(shell, name string) (string, error) {
+	switch shell {
+	case "sh", "bash", "zsh":
+		return fmt.Sprintf(`%[1]s() {
+    local __f __rc
+    __f="$(mktemp)" || return
+    %[2]s=sh %[3]s="$__f" command %[1]s "$@"
+    __rc=$?
+    if [ -s "$__f" ]; then . "$__f"; fi
+    rm -f "$__f"
+    return $__rc
+}
+`, name, shellEnv, shellDirectivesEnv), nil
+	case "fish":
+		return fmt.Sprintf(`function %[1]s
+    set -l __f (mktemp); or return
+    %[2]s=fish %[3]s=$__f command %[1]s $argv
+    set -l __rc $status
+    if test -s $__f; source $__f; end
+    rm -f $__f
+    return $__rc
+end
+`, name, shellEnv, shellDirectivesEnv), nil
+	}
+
+	return "", fmt.Errorf("Unsupported shell %q", shell)
+}
+
+// ShellChdir asks the wrapping shell function to change directory once the
+// command exits.
+func (cmd *Command) ShellChdir(dir string) error {
+	return cmd.writeShellDirective("cd " + shellQuote(cmd.getenv(shellEnv), dir))
+}
+
+var shellVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ShellExport asks the wrapping shell function to export name=value once the
+// command exits. name must be a valid shell variable name.
+func (cmd *Command) ShellExport(name, value string) error {
+	if !shellVarName.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+
+	shell := cmd.getenv(shellEnv)
+
+	if shell == "fish" {
+		return cmd.writeShellDirective(fmt.Sprintf("set -gx %s %s", name, shellQuote(shell, value)))
+	}
+
+	return cmd.writeShellDirective(fmt.Sprintf("export %s=%s", name, shellQuote(shell, value)))
+}
+
+func (cmd *Command) writeShellDirective(line string) error {
+	path := cmd.getenv(shellDirectivesEnv)
+	if path == "" {
+		return ErrNoShellWrapper
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func shellQuote(shell, s string) string {
+	if shell == "fish" {
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	}
+
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
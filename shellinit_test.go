@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellDirectives(t *testing.T) {
+	env := map[string]string{}
+
+	c := NewCommand("test", "test-group", "does test stuff", nil, nil)
+	c.Getenv = func(name string) string {
+		return env[name]
+	}
+
+	if err := c.ShellChdir("/tmp"); err != ErrNoShellWrapper {
+		t.Fatalf("Expected ErrNoShellWrapper, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "directives")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	env[shellDirectivesEnv] = path
+	env[shellEnv] = "sh"
+
+	if err := c.ShellChdir("/tmp/it's here"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ShellExport("FOO", "bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ShellExport("X=1; rm -rf ~ #", "bar"); err == nil {
+		t.Fatal("Expected an invalid variable name to be refused")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "cd '/tmp/it'\\''s here'\nexport FOO='bar'\n"
+	if string(b) != expected {
+		t.Fatalf("Expected %q, got %q", expected, string(b))
+	}
+}
+
+func TestShellWrapper(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script, err := shellWrapper(shell, "tool")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(script, "command tool") {
+			t.Fatalf("Wrapper for %s does not call the binary:\n%s", shell, script)
+		}
+	}
+
+	if _, err := shellWrapper("tcsh", "tool"); err == nil {
+		t.Fatal("Expected an error for an unsupported shell")
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// splitArgs splits a command line into words the way a POSIX shell would,
+// honoring single and double quotes and backslash escapes. No expansion is
+// performed.
+func splitArgs(line string) ([]string, error) {
+	words := []string{}
+
+	var word strings.Builder
+	inWord := false
+	escaped := false
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' {
+				escaped = true
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errUnterminatedQuote
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownGrace = 10 * time.Second
+
+// InterruptErr is returned by App.Run when the command was stopped by a
+// signal.
+type InterruptErr struct {
+	Signal os.Signal
+	Err    error
+}
+
+func (ie *InterruptErr) Error() string {
+	if ie.Err != nil {
+		return fmt.Sprintf("interrupted by %s: %v", ie.Signal, ie.Err)
+	}
+
+	return fmt.Sprintf("interrupted by %s", ie.Signal)
+}
+
+func (ie *InterruptErr) Unwrap() error {
+	return ie.Err
+}
+
+type shutdownHooks struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+func (sh *shutdownHooks) reset() {
+	sh.mu.Lock()
+	sh.funcs = nil
+	sh.mu.Unlock()
+}
+
+func (sh *shutdownHooks) add(fn func()) {
+	sh.mu.Lock()
+	sh.funcs = append(sh.funcs, fn)
+	sh.mu.Unlock()
+}
+
+func (sh *shutdownHooks) run() {
+	sh.mu.Lock()
+	funcs := sh.funcs
+	sh.funcs = nil
+	sh.mu.Unlock()
+
+	for i := len(funcs) - 1; i >= 0; i-- {
+		funcs[i]()
+	}
+}
+
+// OnShutdown registers fn to be called when the run is interrupted by a
+// signal. Hooks run in reverse order of registration.
+func (cmd *Command) OnShutdown(fn func()) {
+	cmd.shutdown.add(fn)
+}
+
+func (app *App) runWithSignals(cmd *Command, run RunFunc, cancel func()) error {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- run(cmd)
+	}()
+
+	var sig os.Signal
+
+	select {
+	case err := <-errc:
+		return err
+	case sig = <-sigs:
+	}
+
+	cancel()
+
+	grace := app.ShutdownGrace
+	if grace <= 0 {
+		grace = defaultShutdownGrace
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	// The hooks run alongside the command, within the grace period, so a
+	// hung hook or a second signal still forces the exit
+	hooks := make(chan struct{})
+	go func() {
+		cmd.shutdown.run()
+		close(hooks)
+	}()
+
+	var err error
+
+	for running := errc; running != nil || hooks != nil; {
+		select {
+		case err = <-running:
+			running = nil
+		case <-hooks:
+			hooks = nil
+		case <-sigs:
+			forceExit(cmd, sig)
+		case <-timer.C:
+			forceExit(cmd, sig)
+		}
+	}
+
+	return &InterruptErr{Signal: sig, Err: err}
+}
+
+func forceExit(cmd *Command, sig os.Signal) {
+	fmt.Fprintln(cmd.Stderr, cmd.app.trf("%s: forcing exit after %s", cmd.Name, sig))
+	os.Exit(130)
+}
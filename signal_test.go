@@ -0,0 +1,49 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAppHandleSignals(t *testing.T) {
+	cleanedUp := false
+
+	app := NewApp()
+	app.HandleSignals = true
+	app.ShutdownGrace = 5 * time.Second
+	app.AddCommand(NewCommand("wait", "test-group", "waits for a signal", func(c *Command) {}, func(c *Command) error {
+		c.OnShutdown(func() {
+			cleanedUp = true
+		})
+
+		p, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			return err
+		}
+
+		if err := p.Signal(os.Interrupt); err != nil {
+			return err
+		}
+
+		<-c.Context().Done()
+		return c.Context().Err()
+	}))
+
+	err := app.Run([]string{"app", "wait"})
+
+	ie, ok := err.(*InterruptErr)
+	if !ok {
+		t.Fatalf("Expected an interrupt error, got %v", err)
+	}
+
+	if ie.Signal != os.Interrupt {
+		t.Fatalf("Unexpected signal %v", ie.Signal)
+	}
+
+	if !cleanedUp {
+		t.Fatal("Expected shutdown hook to run")
+	}
+}
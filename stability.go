@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stability tells users how settled a command's interface is. Help shows
+// non-stable levels after the description, e.g. "(beta)".
+type Stability string
+
+const (
+	StabilityStable Stability = ""
+	StabilityBeta   Stability = "beta"
+	StabilityAlpha  Stability = "alpha"
+)
+
+const (
+	includeAlphaEnv  = "CMD_INCLUDE_ALPHA"
+	includeAlphaFlag = "--include-alpha"
+)
+
+// includeAlphaKey marks the context of a run given --include-alpha.
+type includeAlphaKey struct{}
+
+// alphaIncluded reports whether alpha commands are available in ctx, because
+// the run was given --include-alpha or CMD_INCLUDE_ALPHA is set.
+func (app *App) alphaIncluded(ctx context.Context) bool {
+	if ctx.Value(includeAlphaKey{}) != nil {
+		return true
+	}
+
+	v := app.getenvIn(ctx, includeAlphaEnv)
+	return v != "" && v != "0" && v != "false"
+}
+
+// gated reports whether cmd is an alpha command that HideAlpha keeps out of
+// the run in ctx.
+func (app *App) gated(ctx context.Context, cmd *Command) bool {
+	return app.HideAlpha && cmd.Stability == StabilityAlpha && !app.alphaIncluded(ctx)
+}
+
+// listed reports whether cmd appears in help, search and completion.
+func (app *App) listed(ctx context.Context, cmd *Command) bool {
+	if cmd.Hidden || app.gated(ctx, cmd) {
+		return false
+	}
+
+	return !app.HideUnauthorized || app.authorize(cmd) == nil
+}
+
+func (cmd *Command) describeStability() string {
+	if cmd.Stability == StabilityStable {
+		return cmd.description()
+	}
+
+	return fmt.Sprintf("%s (%s)", cmd.description(), cmd.app.tr(string(cmd.Stability)))
+}
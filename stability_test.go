@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStability(t *testing.T) {
+	var out strings.Builder
+	ran := []string{}
+
+	app := NewApp()
+	app.Stdout = &out
+	app.HideAlpha = true
+	app.Getenv = func(string) string { return "" }
+
+	for _, s := range []Stability{StabilityStable, StabilityBeta, StabilityAlpha} {
+		name := "cmd-" + string(s)
+		if s == StabilityStable {
+			name = "cmd-stable"
+		}
+
+		c := NewCommand(name, "test-group", "the "+name+" command", func(*Command) {}, func(c *Command) error {
+			ran = append(ran, c.Name)
+			return nil
+		})
+		c.Stability = s
+
+		app.AddCommand(c)
+	}
+
+	if err := app.Run([]string{"app", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := out.String(); !strings.Contains(s, "the cmd-beta command (beta)") || strings.Contains(s, "cmd-alpha") || strings.Contains(s, "(stable)") {
+		t.Fatalf("Unexpected listing:\n%s", s)
+	}
+
+	if err := app.Run([]string{"app", "cmd-alpha"}); !errors.Is(err, ErrInvalidCommand) {
+		t.Fatalf("Expected alpha to be refused, got %v", err)
+	}
+
+	out.Reset()
+
+	if err := app.Run([]string{"app", "--include-alpha", "--help"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "the cmd-alpha command (alpha)") {
+		t.Fatalf("Expected alpha in the listing:\n%s", out.String())
+	}
+
+	if err := app.Run([]string{"app", "--include-alpha", "cmd-alpha"}); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Getenv = func(name string) string {
+		if name == includeAlphaEnv {
+			return "1"
+		}
+		return ""
+	}
+
+	if err := app.Run([]string{"app", "cmd-alpha"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(ran, ",") != "cmd-alpha,cmd-alpha" {
+		t.Fatalf("Unexpected runs: %v", ran)
+	}
+}
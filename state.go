@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type stateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+var stateStores sync.Map
+
+func stateStoreFor(dir string) *stateStore {
+	path := filepath.Join(dir, "state.json")
+	s, _ := stateStores.LoadOrStore(path, &stateStore{path: path})
+	return s.(*stateStore)
+}
+
+func (s *stateStore) load() (map[string]json.RawMessage, error) {
+	data := map[string]json.RawMessage{}
+
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return data, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return data, nil
+	}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *stateStore) save(data map[string]json.RawMessage) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, b)
+}
+
+// lockFile takes the store's lock file, so processes sharing the store
+// don't lose each other's changes. It waits for another holder up to a
+// couple of seconds.
+func (s *stateStore) lockFile() (*lockFile, error) {
+	for i := 0; ; i++ {
+		lf, err := acquireLock(s.path+".lock", "state")
+		if _, locked := err.(*LockedErr); !locked || i == 200 {
+			return lf, err
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// modify loads the store under its locks and lets fn change the data, which
+// is saved if fn reports a change.
+func (s *stateStore) modify(fn func(data map[string]json.RawMessage) (bool, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lf, err := s.lockFile()
+	if err != nil {
+		return err
+	}
+	defer lf.release()
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if changed, err := fn(data); err != nil || !changed {
+		return err
+	}
+
+	return s.save(data)
+}
+
+func (s *stateStore) get(key string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return false, err
+	}
+
+	raw, ok := data[key]
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(raw, v)
+}
+
+func (s *stateStore) set(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.modify(func(data map[string]json.RawMessage) (bool, error) {
+		data[key] = raw
+		return true, nil
+	})
+}
+
+func (s *stateStore) delete(key string) error {
+	return s.modify(func(data map[string]json.RawMessage) (bool, error) {
+		if _, ok := data[key]; !ok {
+			return false, nil
+		}
+
+		delete(data, key)
+		return true, nil
+	})
+}
+
+// update decodes key into v, lets fn change v and stores it, with no other
+// change to the store in between.
+func (s *stateStore) update(key string, v interface{}, fn func()) error {
+	return s.modify(func(data map[string]json.RawMessage) (bool, error) {
+		if raw, ok := data[key]; ok {
+			if err := json.Unmarshal(raw, v); err != nil {
+				return false, err
+			}
+		}
+
+		fn()
+
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return false, err
+		}
+
+		data[key] = raw
+		return true, nil
+	})
+}
+
+// keys returns the sorted keys starting with prefix.
+func (s *stateStore) keys(prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for k := range data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// State is a persistent key-value store private to one command, kept in a
+// JSON file in the app's data dir. Values are stored as JSON.
+type State struct {
+	prefix string
+	store  *stateStore
+}
+
+// State returns the command's store, for remembering things like cursors,
+// last-sync timestamps or preferences between runs.
+func (cmd *Command) State() *State {
+	return &State{
+		prefix: "state/" + cmd.Name + "/",
+		store:  stateStoreFor(cmd.app.dataDir()),
+	}
+}
+
+// Get decodes the value of key into v, reporting whether it was set.
+func (s *State) Get(key string, v interface{}) (bool, error) {
+	return s.store.get(s.prefix+key, v)
+}
+
+func (s *State) Set(key string, v interface{}) error {
+	return s.store.set(s.prefix+key, v)
+}
+
+// Delete removes key. Deleting a key that is not set is not an error.
+func (s *State) Delete(key string) error {
+	return s.store.delete(s.prefix + key)
+}
+
+// Keys returns the command's keys in order.
+func (s *State) Keys() ([]string, error) {
+	keys, err := s.store.keys(s.prefix)
+
+	for i, k := range keys {
+		keys[i] = k[len(s.prefix):]
+	}
+
+	return keys, err
+}
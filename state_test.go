@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCmdState(t *testing.T) {
+	app := NewApp()
+	app.DataDir = t.TempDir()
+
+	var cursor int
+
+	sync := NewCommand("sync", "test-group", "syncs", func(c *Command) {}, func(c *Command) error {
+		if _, err := c.State().Get("cursor", &cursor); err != nil {
+			return err
+		}
+
+		cursor++
+		return c.State().Set("cursor", cursor)
+	})
+	app.AddCommand(sync)
+	app.AddCommand(NewCommand("other", "test-group", "does other things", func(c *Command) {}, func(c *Command) error {
+		return c.State().Set("cursor", "unrelated")
+	}))
+
+	for _, name := range []string{"sync", "other", "sync"} {
+		if err := app.Run([]string{"app", name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if cursor != 2 {
+		t.Fatalf("Expected the cursor to persist across runs, got %d", cursor)
+	}
+
+	st := sync.State()
+
+	if err := st.Set("last", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys, err := st.Keys(); err != nil || !reflect.DeepEqual(keys, []string{"cursor", "last"}) {
+		t.Fatalf("Unexpected keys %v, %v", keys, err)
+	}
+
+	if err := st.Delete("cursor"); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := st.Get("cursor", &cursor); ok || err != nil {
+		t.Fatalf("Expected cursor to be deleted, got %v, %v", ok, err)
+	}
+}
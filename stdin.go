@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// AppendStdinArg adds a file argument, checked like AppendFileArg, that
+// also accepts "-" for the standard input. Read it with ArgReader.
+func (cmd *Command) AppendStdinArg(name, desc string, opts PathOptions) {
+	cmd.Args = append(cmd.Args, &Arg{Name: name, Description: desc, Stdin: true, path: &pathArg{opts: opts}})
+}
+
+// ArgReader opens the file named by the arg called name, relative to Dir, or
+// returns the command's standard input if the arg has Stdin set and its value
+// is "-". Closing the standard input this way leaves it open.
+func (cmd *Command) ArgReader(name string) (io.ReadCloser, error) {
+	for i, a := range cmd.Args {
+		if a.Name != name {
+			continue
+		}
+
+		v := cmd.Flags.Arg(i)
+		if a.Stdin && v == "-" {
+			return io.NopCloser(cmd.stdin()), nil
+		}
+
+		return os.Open(inDir(cmd.Dir(), v))
+	}
+
+	return nil, fmt.Errorf("no arg named %s", name)
+}
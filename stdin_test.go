@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCmdArgReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.txt")
+	if err := os.WriteFile(path, []byte("from file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+
+	app := NewApp()
+	app.Stdin = strings.NewReader("from stdin")
+	app.AddCommand(NewCommand("cat", "test-group", "prints a file", func(c *Command) {
+		c.AppendStdinArg("input", "the file", PathOptions{})
+	}, func(c *Command) error {
+		r, err := c.ArgReader("input")
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		b, err := io.ReadAll(r)
+		got = string(b)
+		return err
+	}))
+
+	for _, tc := range []struct{ arg, expected string }{{path, "from file"}, {"-", "from stdin"}} {
+		if err := app.Run([]string{"app", "cat", tc.arg}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != tc.expected {
+			t.Fatalf("Expected %q, got %q", tc.expected, got)
+		}
+	}
+
+	if err := app.Run([]string{"app", "cat", path + ".missing"}); err == nil {
+		t.Fatal("Expected a missing file to fail")
+	}
+
+	var out strings.Builder
+	cmd, _ := app.command("cat")
+	cmd.Stdout = &out
+	cmd.Usage()
+
+	if !strings.Contains(out.String(), `input: the file (use "-" to read standard input)`) {
+		t.Fatalf("Expected the usage to explain -, got:\n%s", out.String())
+	}
+}
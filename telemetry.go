@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+const (
+	telemetryConsentKey = "telemetry/consent"
+	telemetryQueueKey   = "telemetry/queue"
+	telemetryQueueMax   = 500
+	telemetryTimeout    = 5 * time.Second
+)
+
+// TelemetryEvent is the anonymous record of one command run. It carries no
+// arguments or values. ID is unique to the event, so a sink can tell one
+// that is sent again.
+type TelemetryEvent struct {
+	ID       string        `json:"id"`
+	Time     time.Time     `json:"time"`
+	Command  string        `json:"command"`
+	Version  string        `json:"version,omitempty"`
+	OS       string        `json:"os"`
+	Arch     string        `json:"arch"`
+	Duration time.Duration `json:"duration"`
+	Success  bool          `json:"success"`
+}
+
+// TelemetrySink delivers events, e.g. to an analytics endpoint. Events are
+// sent in the background while a later command runs; events that fail to
+// send are kept and retried.
+type TelemetrySink interface {
+	Send(events []TelemetryEvent) error
+}
+
+// EnableTelemetry records an event for every command run and sends it to
+// sink, but only once the user has opted in with the telemetry command that
+// it registers. A nil sink records nothing.
+func (app *App) EnableTelemetry(sink TelemetrySink) {
+	app.telemetry = sink
+
+	app.AddCommand(NewCommand("telemetry", "Shell", "Show or change anonymous usage reporting: on, off or status",
+		func(cmd *Command) {
+			cmd.AppendArg("setting", "on, off or status")
+		},
+		func(cmd *Command) error {
+			store := stateStoreFor(app.dataDir())
+
+			switch cmd.Arg("setting") {
+			case "on", "off":
+				on := cmd.Arg("setting") == "on"
+
+				if err := store.set(telemetryConsentKey, on); err != nil {
+					return err
+				}
+
+				if !on {
+					return store.delete(telemetryQueueKey)
+				}
+
+				return nil
+			case "status":
+				status := app.tr("Usage reporting is off")
+				if app.telemetryConsent() {
+					status = app.tr("Usage reporting is on")
+				}
+
+				fmt.Fprintln(cmd.Stdout, status)
+				return nil
+			}
+
+			return cmd.usageErr("Invalid setting", ErrArgCount)
+		}))
+}
+
+func (app *App) telemetryConsent() bool {
+	consent := false
+	stateStoreFor(app.dataDir()).get(telemetryConsentKey, &consent)
+	return consent
+}
+
+// recordTelemetry queues an event for res, to be sent by a later run's
+// flush. Failures are ignored so reporting never affects the command.
+func (app *App) recordTelemetry(res CommandResult) {
+	if app.telemetry == nil || res.Name == "telemetry" || !app.telemetryConsent() {
+		return
+	}
+
+	e := TelemetryEvent{
+		ID:       newULID().String(),
+		Time:     time.Now().UTC(),
+		Command:  res.Name,
+		Version:  app.Version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Duration: res.Duration,
+		Success:  res.Err == nil,
+	}
+
+	queue := []TelemetryEvent{}
+	stateStoreFor(app.dataDir()).update(telemetryQueueKey, &queue, func() {
+		queue = append(queue, e)
+		if len(queue) > telemetryQueueMax {
+			queue = queue[len(queue)-telemetryQueueMax:]
+		}
+	})
+}
+
+// startTelemetryFlush sends the queued events in the background while the
+// command runs, unless a flush is already under way, and returns a channel
+// closed when it is done. Sent events are removed from the queue by ID,
+// however long the send took; events that fail to send are left for a
+// later run.
+func (app *App) startTelemetryFlush() <-chan struct{} {
+	if app.telemetry == nil || !app.telemetryConsent() {
+		return nil
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if done := app.telemetryDone; done != nil {
+		select {
+		case <-done:
+		default:
+			return nil
+		}
+	}
+
+	done := make(chan struct{})
+	app.telemetryDone = done
+
+	go func() {
+		defer close(done)
+
+		store := stateStoreFor(app.dataDir())
+
+		queue := []TelemetryEvent{}
+		if _, err := store.get(telemetryQueueKey, &queue); err != nil || len(queue) == 0 {
+			return
+		}
+
+		if err := app.telemetry.Send(queue); err != nil {
+			return
+		}
+
+		// Other runs may have queued or trimmed events in the meantime
+		sent := map[string]bool{}
+		for _, e := range queue {
+			sent[e.ID] = true
+		}
+
+		rest := []TelemetryEvent{}
+		store.update(telemetryQueueKey, &rest, func() {
+			kept := rest[:0]
+			for _, e := range rest {
+				if !sent[e.ID] {
+					kept = append(kept, e)
+				}
+			}
+
+			rest = kept
+		})
+	}()
+
+	return done
+}
+
+// finishTelemetryFlush waits up to telemetryTimeout for the flush a run
+// started, so that the process does not exit in the middle of it. A flush
+// that takes longer carries on, and its events are sent again by a later
+// run if the process exits first.
+func (app *App) finishTelemetryFlush(done <-chan struct{}) {
+	if done == nil {
+		return
+	}
+
+	select {
+	case <-done:
+	case <-time.After(telemetryTimeout):
+	}
+}
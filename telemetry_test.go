@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testSink struct {
+	sent   []TelemetryEvent
+	fail   bool
+	during func()
+}
+
+func (ts *testSink) Send(events []TelemetryEvent) error {
+	if ts.fail {
+		return errors.New("offline")
+	}
+
+	if ts.during != nil {
+		ts.during()
+	}
+
+	ts.sent = append(ts.sent, events...)
+	return nil
+}
+
+func TestTelemetry(t *testing.T) {
+	var out strings.Builder
+	sink := &testSink{}
+
+	app := NewApp()
+	app.DataDir = t.TempDir()
+	app.Version = "1.2.3"
+	app.Stdout = &out
+	app.EnableTelemetry(sink)
+
+	app.AddCommand(NewCommand("work", "test-group", "works", func(c *Command) {}, func(c *Command) error {
+		return nil
+	}))
+
+	run := func(args ...string) {
+		t.Helper()
+
+		if err := app.Run(append([]string{"app"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("work")
+	run("telemetry", "status")
+
+	if len(sink.sent) != 0 || out.String() != "Usage reporting is off\n" {
+		t.Fatalf("Expected no events without consent, got %d and %q", len(sink.sent), out.String())
+	}
+
+	run("telemetry", "on")
+
+	// Runs send the events queued before them in the background, and
+	// failed sends keep the events queued
+	sink.fail = true
+	run("work")
+	run("work")
+
+	sink.fail = false
+	run("work")
+	run("telemetry", "status")
+
+	if len(sink.sent) != 3 {
+		t.Fatalf("Expected the queued events to be sent, got %d", len(sink.sent))
+	}
+
+	if e := sink.sent[0]; e.Command != "work" || e.Version != "1.2.3" || !e.Success || e.OS == "" {
+		t.Fatalf("Unexpected event %+v", e)
+	}
+
+	run("telemetry", "off")
+	run("work")
+
+	if len(sink.sent) != 3 {
+		t.Fatalf("Expected no events after opting out, got %d", len(sink.sent))
+	}
+}
+
+func TestTelemetryFlushByID(t *testing.T) {
+	sink := &testSink{}
+
+	app := NewApp()
+	app.DataDir = t.TempDir()
+	app.EnableTelemetry(sink)
+
+	store := stateStoreFor(app.dataDir())
+	if err := store.set(telemetryConsentKey, true); err != nil {
+		t.Fatal(err)
+	}
+
+	app.recordTelemetry(CommandResult{Name: "work"})
+
+	// An event queued while the flush sends is left for the next one
+	sink.during = func() { app.recordTelemetry(CommandResult{Name: "work"}) }
+	app.finishTelemetryFlush(app.startTelemetryFlush())
+
+	queue := []TelemetryEvent{}
+	if _, err := store.get(telemetryQueueKey, &queue); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.sent) != 1 || len(queue) != 1 {
+		t.Fatalf("Expected one event sent and one queued, got %d and %d", len(sink.sent), len(queue))
+	}
+
+	if queue[0].ID == "" || queue[0].ID == sink.sent[0].ID {
+		t.Fatalf("Expected distinct IDs, got %q and %q", sink.sent[0].ID, queue[0].ID)
+	}
+}
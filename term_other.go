@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package cmd
+
+import (
+	"errors"
+	"os"
+)
+
+func setEcho(f *os.File, on bool) error {
+	return errors.New("hidden input is not supported on this platform")
+}
+
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func stty(f *os.File, args ...string) error {
+	c := exec.Command("stty", args...)
+	c.Stdin = f
+	return c.Run()
+}
+
+func setEcho(f *os.File, on bool) error {
+	if on {
+		return stty(f, "echo")
+	}
+
+	return stty(f, "-echo")
+}
+
+// makeRaw puts f into a mode where keys are read one at a time without echo,
+// and returns a func restoring the previous mode.
+func makeRaw(f *os.File) (func(), error) {
+	c := exec.Command("stty", "-g")
+	c.Stdin = f
+
+	out, err := c.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stty(f, "-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+
+	state := strings.TrimSpace(string(out))
+
+	return func() {
+		stty(f, state)
+	}, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	enableProcessedInput       = 0x1
+	enableLineInput            = 0x2
+	enableEchoInput            = 0x4
+	enableVirtualTerminalInput = 0x200
+)
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
+
+// updateConsoleMode sets f's console mode to fn of its current mode and
+// returns the mode it had.
+func updateConsoleMode(f *os.File, fn func(uint32) uint32) (uint32, error) {
+	h := syscall.Handle(f.Fd())
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return 0, err
+	}
+
+	if r, _, err := procSetConsoleMode.Call(uintptr(h), uintptr(fn(mode))); r == 0 {
+		return 0, err
+	}
+
+	return mode, nil
+}
+
+func setEcho(f *os.File, on bool) error {
+	_, err := updateConsoleMode(f, func(mode uint32) uint32 {
+		if on {
+			return mode | enableEchoInput
+		}
+
+		return mode &^ enableEchoInput
+	})
+
+	return err
+}
+
+// makeRaw puts f into a mode where keys are read one at a time without echo,
+// with arrows as escape sequences, and returns a func restoring the previous
+// mode.
+func makeRaw(f *os.File) (func(), error) {
+	old, err := updateConsoleMode(f, func(mode uint32) uint32 {
+		return mode&^(enableLineInput|enableEchoInput|enableProcessedInput) | enableVirtualTerminalInput
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		updateConsoleMode(f, func(uint32) uint32 { return old })
+	}, nil
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// TimeParser parses timestamps given in any of several forms:
+//
+//   - RFC 3339, "2006-01-02 15:04:05", "2006-01-02" and RFC 1123
+//   - unix seconds, or milliseconds when more than 11 digits long
+//   - now, today, yesterday and tomorrow
+//   - a signed duration relative to now, like "-2h" or "+1d", or "2h ago"
+//
+// The zero TimeParser is ready to use.
+type TimeParser struct {
+	// Layouts are tried before the default ones.
+	Layouts []string
+
+	// Location is used for times without a zone and for day names. It
+	// defaults to time.Local.
+	Location *time.Location
+
+	// Now is the reference for relative times. It defaults to time.Now.
+	Now func() time.Time
+}
+
+func (tp *TimeParser) Parse(s string) (time.Time, error) {
+	t := strings.TrimSpace(s)
+
+	loc := tp.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	now := time.Now()
+	if tp.Now != nil {
+		now = tp.Now()
+	}
+	now = now.In(loc)
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	switch strings.ToLower(t) {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	}
+
+	if strings.HasPrefix(t, "-") || strings.HasPrefix(t, "+") {
+		if d, err := ParseLongDuration(t); err == nil {
+			return now.Add(d), nil
+		}
+	}
+
+	if ago, ok := strings.CutSuffix(t, " ago"); ok {
+		if d, err := ParseLongDuration(ago); err == nil && d >= 0 {
+			return now.Add(-d), nil
+		}
+	}
+
+	if n, err := strconv.ParseInt(t, 10, 64); err == nil && n >= 0 {
+		if len(t) > 11 {
+			return time.UnixMilli(n).In(loc), nil
+		}
+
+		return time.Unix(n, 0).In(loc), nil
+	}
+
+	for _, layout := range append(append([]string{}, tp.Layouts...), timeLayouts...) {
+		if tm, err := time.ParseInLocation(layout, t, loc); err == nil {
+			return tm, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// TimeFlexible parses v with the default TimeParser.
+func (v Value) TimeFlexible() (time.Time, error) {
+	return (&TimeParser{}).Parse(string(v))
+}
+
+// ParseTime parses v with the command's TimeParser, so a command can accept
+// its own layouts or resolve times in a fixed zone.
+func (cmd *Command) ParseTime(v Value) (time.Time, error) {
+	tp := cmd.TimeParser
+	if tp == nil {
+		tp = &TimeParser{}
+	}
+
+	return tp.Parse(string(v))
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeParser(t *testing.T) {
+	utc := time.UTC
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, utc)
+
+	tp := &TimeParser{Location: utc, Now: func() time.Time { return now }}
+
+	testCases := []struct {
+		s string
+		t time.Time
+	}{
+		{"2024-03-01T12:00:00Z", time.Date(2024, 3, 1, 12, 0, 0, 0, utc)},
+		{"2024-03-01T12:00:00+02:00", time.Date(2024, 3, 1, 10, 0, 0, 0, utc)},
+		{"2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, utc)},
+		{"2024-03-01 08:15", time.Date(2024, 3, 1, 8, 15, 0, 0, utc)},
+		{"1700000000", time.Unix(1700000000, 0)},
+		{"1700000000123", time.UnixMilli(1700000000123)},
+		{"now", now},
+		{"yesterday", time.Date(2024, 3, 9, 0, 0, 0, 0, utc)},
+		{"-2h", now.Add(-2 * time.Hour)},
+		{"+1d", now.Add(24 * time.Hour)},
+		{"90m ago", now.Add(-90 * time.Minute)},
+	}
+
+	for _, tc := range testCases {
+		tm, err := tp.Parse(tc.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !tm.Equal(tc.t) {
+			t.Fatalf("Expected %v for %q, got %v", tc.t, tc.s, tm)
+		}
+	}
+
+	for _, s := range []string{"", "last week", "2024-13-01", "-2x"} {
+		if _, err := tp.Parse(s); err == nil {
+			t.Fatalf("Expected an error for %q", s)
+		}
+	}
+
+	if _, err := Value("2024-03-01").TimeFlexible(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCmdParseTime(t *testing.T) {
+	c := NewCommand("logs", "test-group", "shows logs", func(c *Command) {}, nil)
+	c.TimeParser = &TimeParser{Layouts: []string{"02/01/2006"}, Location: time.UTC}
+
+	tm, err := c.ParseTime("10/03/2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC); !tm.Equal(expected) {
+		t.Fatalf("Expected %v, got %v", expected, tm)
+	}
+}
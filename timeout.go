@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"time"
+)
+
+const timeoutExitCode = 124
+
+// TimeoutErr is returned when a command runs past its Timeout or --timeout.
+// ExitCode is 124, matching timeout(1).
+type TimeoutErr struct {
+	Command string
+	Timeout time.Duration
+
+	app *App
+}
+
+func (te *TimeoutErr) Error() string {
+	return te.app.trf("command %s timed out after %s", te.Command, te.Timeout)
+}
+
+func (te *TimeoutErr) ExitCode() int {
+	return timeoutExitCode
+}
+
+func (cmd *Command) timeout() time.Duration {
+	if cmd.timeoutFlag != nil {
+		return *cmd.timeoutFlag
+	}
+
+	return cmd.Timeout
+}
+
+// withTimeout returns a RunFunc that reports a TimeoutErr once the command's
+// context deadline passes. The deadline cancels the context, and run must
+// notice and return: it is always waited for, since the command's state is
+// reset as soon as the wrapper returns.
+func withTimeout(run RunFunc, timeout time.Duration) RunFunc {
+	return func(cmd *Command) error {
+		ctx := cmd.Context()
+		err := run(cmd)
+
+		if ctx.Err() == context.DeadlineExceeded {
+			return &TimeoutErr{Command: cmd.Name, Timeout: timeout, app: cmd.app}
+		}
+
+		return err
+	}
+}
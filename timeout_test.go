@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCmdTimeout(t *testing.T) {
+	app := NewApp()
+	app.TimeoutFlag = true
+
+	c := NewCommand("slow", "test-group", "takes a while", func(c *Command) {}, func(c *Command) error {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-c.Context().Done():
+		}
+
+		return nil
+	})
+	c.Timeout = 10 * time.Millisecond
+	app.AddCommand(c)
+
+	err := app.Run([]string{"app", "slow"})
+	if _, ok := err.(*TimeoutErr); !ok {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+
+	if code := ExitCode(err); code != timeoutExitCode {
+		t.Fatalf("Expected exit code %d, got %d", timeoutExitCode, code)
+	}
+
+	err = app.Run([]string{"app", "slow", "--timeout", "20ms"})
+	te, ok := err.(*TimeoutErr)
+	if !ok {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+
+	if te.Timeout != 20*time.Millisecond {
+		t.Fatalf("Expected the flag to override the timeout, got %s", te.Timeout)
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+// CommandResult describes a finished command run.
+type CommandResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+	ExitCode int
+}
+
+// complete reports a finished run of cmd to OnCommandComplete and, with
+// --timings, prints its duration.
+func (app *App) complete(cmd *Command, start time.Time, err error) {
+	res := CommandResult{
+		Name:     cmd.Name,
+		Duration: time.Since(start),
+		Err:      err,
+		ExitCode: ExitCode(err),
+	}
+
+	if cmd.timingsFlag != nil && *cmd.timingsFlag {
+		fmt.Fprintln(cmd.stderr(), app.trf("%s took %s", cmd.Name, res.Duration.Round(time.Millisecond)))
+	}
+
+	if app.OnCommandComplete != nil {
+		app.OnCommandComplete(res)
+	}
+
+	if !cmd.Hidden {
+		app.recordTelemetry(res)
+		app.printUpdateNotice(cmd)
+	}
+}
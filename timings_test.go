@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandComplete(t *testing.T) {
+	var errOut strings.Builder
+	results := []CommandResult{}
+
+	app := NewApp()
+	app.Stderr = &errOut
+	app.TimingsFlag = true
+	app.OnCommandComplete = func(res CommandResult) {
+		results = append(results, res)
+	}
+
+	app.AddCommand(NewCommand("sleep", "test-group", "sleeps", func(c *Command) {
+		c.Flags.Bool("fail", false, "fail")
+	}, func(c *Command) error {
+		time.Sleep(5 * time.Millisecond)
+
+		if c.Flag("fail") == "true" {
+			return errors.New("failed")
+		}
+
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "sleep", "--timings"}); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Run([]string{"app", "sleep", "--timings=false", "--fail"})
+	app.Run([]string{"app", "sleep", "--help"})
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Name != "sleep" || results[0].Duration < 5*time.Millisecond || results[0].ExitCode != 0 {
+		t.Fatalf("Unexpected result %+v", results[0])
+	}
+
+	if results[1].Err == nil || results[1].ExitCode != 1 {
+		t.Fatalf("Unexpected result %+v", results[1])
+	}
+
+	if !strings.HasPrefix(errOut.String(), "sleep took ") || strings.Count(errOut.String(), "took") != 1 {
+		t.Fatalf("Unexpected timings output %q", errOut.String())
+	}
+}
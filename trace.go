@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	traceEnv  = "CMD_TRACE"
+	traceFlag = "--debug-cli"
+)
+
+// traceFromEnv reports whether CMD_TRACE asks for every run to be traced.
+func (app *App) traceFromEnv(ctx context.Context) bool {
+	v := app.getenvIn(ctx, traceEnv)
+	return v != "" && v != "0" && v != "false"
+}
+
+func tracef(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprintf(w, "trace: "+format+"\n", args...)
+}
+
+// trace prints how an invocation of cmd was resolved: its flags, positional
+// args and env args, each with where its value came from.
+func (cmd *Command) trace(args []string) {
+	w := cmd.stderr()
+	args, _ = cmd.redactArgs(args)
+
+	tracef(w, "command %q (group %q) with args %q", cmd.Name, cmd.Group, args)
+
+	cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if cmd.isSecret(f.Name) {
+			tracef(w, "flag %s (secret) from %s", f.Name, cmd.Provenance(f.Name))
+			return
+		}
+
+		tracef(w, "flag %s=%q from %s", f.Name, f.Value.String(), cmd.Provenance(f.Name))
+	})
+
+	for i, a := range cmd.Args {
+		if cmd.isSecret(a.Name) {
+			tracef(w, "arg %s (secret) from %s", a.Name, cmd.Provenance(a.Name))
+			if a.Variable {
+				break
+			}
+			continue
+		}
+
+		if a.Variable {
+			tracef(w, "var arg %s=%q from %s", a.Name, cmd.VarArgs(), cmd.Provenance(a.Name))
+			break
+		}
+
+		if i >= cmd.Flags.NArg() {
+			tracef(w, "arg %s missing", a.Name)
+			continue
+		}
+
+		tracef(w, "arg %s=%q from %s", a.Name, cmd.Flags.Arg(i), cmd.Provenance(a.Name))
+	}
+
+	names := make([]string, 0, len(cmd.EnvArgs))
+	for n := range cmd.EnvArgs {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		switch v := cmd.EnvArg(n); {
+		case v == "":
+			tracef(w, "env %s unset", n)
+		case cmd.isSecret(n):
+			tracef(w, "env %s set (secret) from %s", n, cmd.Provenance(n))
+		default:
+			tracef(w, "env %s=%q from %s", n, v.String(), cmd.Provenance(n))
+		}
+	}
+}
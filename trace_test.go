@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var out, errOut strings.Builder
+	env := map[string]string{"TEST_TOKEN": "abc", "TEST_REGION": "eu"}
+
+	app := NewApp()
+	app.Stdout = &out
+	app.Stderr = &errOut
+	app.Getenv = func(name string) string {
+		return env[name]
+	}
+
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.Flags.Bool("force", false, "force it")
+		c.Flags.Int("count", 1, "how many")
+		c.AppendArg("target", "the target")
+		c.AddEnvArg("TEST_REGION", "the region")
+		c.AddSecretEnvArg("TEST_TOKEN", "a token")
+	}, func(c *Command) error {
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "--debug-cli", "deploy", "--force", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `trace: command "deploy" (group "test-group") with args ["--force" "web"]
+trace: flag count="1" from default
+trace: flag force="true" from cli
+trace: arg target="web" from cli
+trace: env TEST_REGION="eu" from env
+trace: env TEST_TOKEN set (secret) from env
+`
+
+	if errOut.String() != expected {
+		t.Fatalf("Unexpected trace:\n%s", errOut.String())
+	}
+
+	errOut.Reset()
+
+	if err := app.Run([]string{"app", "deploy", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if errOut.Len() != 0 {
+		t.Fatalf("Unexpected trace without CMD_TRACE: %q", errOut.String())
+	}
+
+	env["CMD_TRACE"] = "1"
+
+	app.Run([]string{"app", "nope"})
+
+	if errOut.String() != "trace: no command named \"nope\"\n" {
+		t.Fatalf("Unexpected trace: %q", errOut.String())
+	}
+}
+
+func TestTraceRedactsSecrets(t *testing.T) {
+	var errOut strings.Builder
+
+	app := NewApp()
+	app.Stderr = &errOut
+
+	app.AddCommand(NewCommand("login", "test-group", "logs in", func(c *Command) {
+		c.Flags.String("token", "", "the token")
+		c.MarkSecret("token")
+	}, func(c *Command) error {
+		return nil
+	}))
+
+	if err := app.Run([]string{"app", "--debug-cli", "login", "--token=hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(errOut.String(), "hunter2") {
+		t.Fatalf("Expected the secret to be redacted from the trace:\n%s", errOut.String())
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer creates spans around command runs, e.g. by adapting an
+// OpenTelemetry tracer. The context it returns becomes the parent of the
+// command's Context, so RunFuncs can propagate the span to backend calls.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced run.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// startSpan starts a span for a run of args, as a child of any span in
+// parent, when the App has a Tracer. It returns the context to run in and a
+// func ending the span with the run's result.
+func (app *App) startSpan(parent context.Context, args []string) (context.Context, func(error)) {
+	if app.Tracer == nil {
+		return parent, func(error) {}
+	}
+
+	name := app.name()
+	if len(args) > 1 {
+		name += " " + args[1]
+	}
+
+	ctx, span := app.Tracer.StartSpan(parent, name)
+	start := time.Now()
+
+	if len(args) > 1 {
+		span.SetAttribute("cli.command", args[1])
+		span.SetAttribute("cli.args.count", len(args)-2)
+	}
+
+	return ctx, func(err error) {
+		span.SetAttribute("cli.exit_code", ExitCode(err))
+		span.SetAttribute("cli.duration_ms", time.Since(start).Milliseconds())
+		span.End(err)
+	}
+}
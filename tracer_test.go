@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	name   string
+	parent *testSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.attrs[key] = value
+}
+
+func (s *testSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (tt *testTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	s := &testSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	tt.spans = append(tt.spans, s)
+
+	return context.WithValue(ctx, testSpanKey{}, s), s
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	var seen *testSpan
+
+	app := NewApp()
+	app.Name = "tool"
+	app.Tracer = tracer
+
+	app.AddCommand(NewCommand("outer", "test-group", "runs inner", func(c *Command) {}, func(c *Command) error {
+		seen, _ = c.Context().Value(testSpanKey{}).(*testSpan)
+		return app.RunContext(c.Context(), []string{"tool", "inner", "a", "b"})
+	}))
+	app.AddCommand(NewCommand("inner", "test-group", "fails", func(c *Command) {
+		c.AppendVarArg("items", "the items")
+	}, func(c *Command) error {
+		return errors.New("failed")
+	}))
+
+	if err := app.Run([]string{"tool", "outer"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(tracer.spans))
+	}
+
+	outer, inner := tracer.spans[0], tracer.spans[1]
+
+	if seen != outer {
+		t.Fatal("Expected the command context to carry the span")
+	}
+
+	if outer.name != "tool outer" || inner.name != "tool inner" || inner.parent != outer {
+		t.Fatalf("Unexpected spans %q and %q", outer.name, inner.name)
+	}
+
+	delete(inner.attrs, "cli.duration_ms")
+
+	expected := map[string]interface{}{"cli.command": "inner", "cli.args.count": 2, "cli.exit_code": 1}
+	if !reflect.DeepEqual(inner.attrs, expected) || !inner.ended || inner.err == nil {
+		t.Fatalf("Unexpected inner span %+v", inner)
+	}
+}
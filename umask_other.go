@@ -0,0 +1,7 @@
+//go:build !unix
+
+package cmd
+
+func setUmask(mask int) int {
+	return 0
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+}
+
+// ParseBytes parses a size such as "512MB", "1.5GiB" or "4096". KB, MB, GB,
+// TB and PB are powers of 1000 and KiB, MiB, GiB, TiB and PiB powers of
+// 1024. Units are case-insensitive and the B may be left out.
+func ParseBytes(s string) (int64, error) {
+	t := strings.TrimSpace(s)
+
+	i := strings.IndexFunc(t, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(t)
+	}
+
+	mult, ok := byteUnits[strings.ToLower(strings.TrimSpace(t[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	n, err := strconv.ParseFloat(t[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	size := n * float64(mult)
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return int64(size), nil
+}
+
+// FormatBytes formats n with the largest unit that divides it exactly, so
+// that ParseBytes returns n again, e.g. "512MiB" or "1500KB".
+func FormatBytes(n int64) string {
+	if n == 0 {
+		return "0B"
+	}
+
+	for _, u := range []string{"PiB", "PB", "TiB", "TB", "GiB", "GB", "MiB", "MB", "KiB", "KB"} {
+		if m := byteUnits[strings.ToLower(u)]; n%m == 0 {
+			return fmt.Sprintf("%d%s", n/m, u)
+		}
+	}
+
+	return fmt.Sprintf("%dB", n)
+}
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// ParseLongDuration parses a duration like time.ParseDuration, also
+// accepting days (d) and weeks (w), e.g. "2d12h" or "1w".
+func ParseLongDuration(s string) (time.Duration, error) {
+	t := strings.TrimSpace(s)
+
+	neg := strings.HasPrefix(t, "-")
+	t = strings.TrimLeft(t, "+-")
+
+	if t == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	} else if t == "0" {
+		return 0, nil
+	}
+
+	var d time.Duration
+
+	for t != "" {
+		i := strings.IndexFunc(t, func(r rune) bool {
+			return !unicode.IsDigit(r) && r != '.'
+		})
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+
+		j := strings.IndexFunc(t[i:], func(r rune) bool {
+			return unicode.IsDigit(r) || r == '.'
+		})
+		if j < 0 {
+			j = len(t) - i
+		}
+
+		num, unit := t[:i], t[i:i+j]
+		t = t[i+j:]
+
+		var part time.Duration
+
+		switch unit {
+		case "d", "w":
+			n, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+
+			mult := day
+			if unit == "w" {
+				mult = week
+			}
+
+			if n*float64(mult) >= math.MaxInt64 {
+				return 0, fmt.Errorf("duration %q is too long", s)
+			}
+
+			part = time.Duration(n * float64(mult))
+		default:
+			var err error
+			if part, err = time.ParseDuration(num + unit); err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+		}
+
+		if d > math.MaxInt64-part {
+			return 0, fmt.Errorf("duration %q is too long", s)
+		}
+
+		d += part
+	}
+
+	if neg {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// FormatLongDuration formats d like time.Duration.String, with whole days
+// split off, e.g. "2d12h0m0s".
+func FormatLongDuration(d time.Duration) string {
+	days := d / day
+	rest := d % day
+
+	if days == 0 {
+		return d.String()
+	}
+
+	if rest < 0 {
+		rest = -rest
+	}
+
+	if rest == 0 {
+		return fmt.Sprintf("%dd", days)
+	}
+
+	return fmt.Sprintf("%dd%s", days, rest)
+}
+
+func (v Value) Bytes() (int64, error) {
+	return ParseBytes(string(v))
+}
+
+func (v Value) LongDuration() (time.Duration, error) {
+	return ParseLongDuration(string(v))
+}
+
+// bytesValue is a flag.Value for sizes, see ParseBytes.
+type bytesValue int64
+
+func (bv *bytesValue) String() string {
+	return FormatBytes(int64(*bv))
+}
+
+func (bv *bytesValue) Set(s string) error {
+	n, err := ParseBytes(s)
+	*bv = bytesValue(n)
+	return err
+}
+
+func (bv *bytesValue) Get() interface{} {
+	return int64(*bv)
+}
+
+func (bv *bytesValue) clone() flag.Value {
+	return new(bytesValue)
+}
+
+// longDurationValue is a flag.Value for durations, see ParseLongDuration.
+type longDurationValue time.Duration
+
+func (dv *longDurationValue) String() string {
+	return FormatLongDuration(time.Duration(*dv))
+}
+
+func (dv *longDurationValue) Set(s string) error {
+	d, err := ParseLongDuration(s)
+	*dv = longDurationValue(d)
+	return err
+}
+
+func (dv *longDurationValue) Get() interface{} {
+	return time.Duration(*dv)
+}
+
+func (dv *longDurationValue) clone() flag.Value {
+	return new(longDurationValue)
+}
+
+// AddFlagBytes defines a size flag accepting values like "512MB" or
+// "1.5GiB", in bytes.
+func (cmd *Command) AddFlagBytes(name string, value int64, usage string) *int64 {
+	p := new(int64)
+	*p = value
+	cmd.Flags.Var((*bytesValue)(p), name, usage)
+	return p
+}
+
+// AddFlagLongDuration defines a duration flag that also accepts days and
+// weeks, e.g. "2d12h".
+func (cmd *Command) AddFlagLongDuration(name string, value time.Duration, usage string) *time.Duration {
+	p := new(time.Duration)
+	*p = value
+	cmd.Flags.Var((*longDurationValue)(p), name, usage)
+	return p
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBytes(t *testing.T) {
+	testCases := []struct {
+		s string
+		n int64
+	}{
+		{"4096", 4096},
+		{"512MB", 512000000},
+		{"512mb", 512000000},
+		{"1.5GiB", 1610612736},
+		{"10 KiB", 10240},
+		{"2k", 2000},
+		{"1Ti", 1 << 40},
+	}
+
+	for _, tc := range testCases {
+		n, err := Value(tc.s).Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != tc.n {
+			t.Fatalf("Expected %d for %q, got %d", tc.n, tc.s, n)
+		}
+	}
+
+	for _, s := range []string{"", "MB", "-1MB", "12XB", "1.2.3KB", "9999999PB"} {
+		if _, err := ParseBytes(s); err == nil {
+			t.Fatalf("Expected an error for %q", s)
+		}
+	}
+
+	for n, s := range map[int64]string{0: "0B", 1500: "1500B", 536870912: "512MiB", 512000000: "512MB", 3000: "3KB"} {
+		if f := FormatBytes(n); f != s {
+			t.Fatalf("Expected %q for %d, got %q", s, n, f)
+		}
+	}
+}
+
+func TestParseLongDuration(t *testing.T) {
+	testCases := []struct {
+		s string
+		d time.Duration
+	}{
+		{"2d12h", 60 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"90m", 90 * time.Minute},
+		{"-1d30m", -(24*time.Hour + 30*time.Minute)},
+		{"0", 0},
+	}
+
+	for _, tc := range testCases {
+		d, err := Value(tc.s).LongDuration()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d != tc.d {
+			t.Fatalf("Expected %v for %q, got %v", tc.d, tc.s, d)
+		}
+
+		if rt, err := ParseLongDuration(FormatLongDuration(d)); err != nil || rt != d {
+			t.Fatalf("Expected %q to round trip, got %v, %v", FormatLongDuration(d), rt, err)
+		}
+	}
+
+	for _, s := range []string{"", "d", "2x", "1d-2h", "999999w"} {
+		if _, err := ParseLongDuration(s); err == nil {
+			t.Fatalf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestCmdAddFlagBytes(t *testing.T) {
+	var size *int64
+	var retain *time.Duration
+
+	app := NewApp()
+	c := NewCommand("prune", "test-group", "prunes", func(c *Command) {
+		size = c.AddFlagBytes("max-size", 1<<30, "the largest size to keep")
+		retain = c.AddFlagLongDuration("retain", 7*24*time.Hour, "how long to keep things")
+	}, func(c *Command) error { return nil })
+	app.AddCommand(c)
+
+	if f := c.Flags.Lookup("max-size"); f.DefValue != "1GiB" || flagType(f) != "bytes" {
+		t.Fatalf("Unexpected default %q of type %s", f.DefValue, flagType(f))
+	}
+
+	if err := app.Run([]string{"app", "prune", "--max-size", "512MB", "--retain", "2d12h"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if *size != 512000000 || *retain != 60*time.Hour {
+		t.Fatalf("Unexpected values %d, %v", *size, *retain)
+	}
+
+	if err := app.Run([]string{"app", "prune"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if *size != 1<<30 || *retain != 7*24*time.Hour {
+		t.Fatalf("Expected the defaults after a reset, got %d, %v", *size, *retain)
+	}
+
+	res, err := c.ParseStrict([]string{"--max-size", "2GiB"})
+	if err != nil || res.Flags["max-size"] != "2GiB" {
+		t.Fatalf("Unexpected strict parse %v, %v", res, err)
+	}
+}
@@ -0,0 +1,401 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+const (
+	// maxDownload caps the size of release metadata and binaries.
+	maxDownload = 512 << 20
+
+	// updateTimeout bounds each request of an update without a Client.
+	updateTimeout = 5 * time.Minute
+)
+
+// Release is a published version of the app with a binary per platform.
+type Release struct {
+	Version string         `json:"version"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is the binary for one platform, e.g. "linux/amd64". SHA256
+// is the hex digest of the binary and Signature, when present, the base64
+// ed25519 signature of the release version, a NUL byte and the binary, so an
+// old release can't be passed off as a newer one.
+type ReleaseAsset struct {
+	Platform  string `json:"platform"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// ReleaseSource finds the latest release.
+type ReleaseSource interface {
+	Latest(ctx context.Context, client *http.Client) (*Release, error)
+}
+
+// ReleaseURL is a ReleaseSource reading a Release as JSON from a URL.
+type ReleaseURL string
+
+func (u ReleaseURL) Latest(ctx context.Context, client *http.Client) (*Release, error) {
+	b, err := httpGet(ctx, client, string(u))
+	if err != nil {
+		return nil, err
+	}
+
+	var r Release
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("invalid release from %s: %v", u, err)
+	}
+
+	return &r, nil
+}
+
+// GitHubReleases is a ReleaseSource for the latest release of a GitHub
+// repository, given as "owner/repo". Binaries are matched to platforms by
+// having the GOOS and GOARCH in their names, e.g. tool_linux_amd64;
+// archives and packages are skipped, and more than one match is an error.
+// Checksums are read from a checksums.txt asset. A <binary>.sig asset holds
+// the signature.
+type GitHubReleases string
+
+func (gh GitHubReleases) Latest(ctx context.Context, client *http.Client) (*Release, error) {
+	b, err := httpGet(ctx, client, "https://api.github.com/repos/"+string(gh)+"/releases/latest")
+	if err != nil {
+		return nil, err
+	}
+
+	var gr struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+
+	if err := json.Unmarshal(b, &gr); err != nil {
+		return nil, fmt.Errorf("invalid release from GitHub: %v", err)
+	}
+
+	urls := map[string]string{}
+	for _, a := range gr.Assets {
+		urls[a.Name] = a.URL
+	}
+
+	sums := map[string]string{}
+	if u, ok := urls["checksums.txt"]; ok {
+		b, err := httpGet(ctx, client, u)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range strings.Split(string(b), "\n") {
+			if f := strings.Fields(l); len(f) == 2 {
+				sums[strings.TrimPrefix(f[1], "*")] = f[0]
+			}
+		}
+	}
+
+	names := make([]string, len(gr.Assets))
+	for i, a := range gr.Assets {
+		names[i] = a.Name
+	}
+
+	name, err := binaryAsset(names, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return nil, fmt.Errorf("release %s: %v", gr.TagName, err)
+	}
+
+	r := &Release{Version: gr.TagName}
+	if name == "" {
+		return r, nil
+	}
+
+	asset := ReleaseAsset{Platform: runtime.GOOS + "/" + runtime.GOARCH, URL: urls[name], SHA256: sums[name]}
+
+	if su, ok := urls[name+".sig"]; ok {
+		sig, err := httpGet(ctx, client, su)
+		if err != nil {
+			return nil, err
+		}
+
+		asset.Signature = strings.TrimSpace(string(sig))
+	}
+
+	r.Assets = append(r.Assets, asset)
+
+	return r, nil
+}
+
+// nonBinaryExts are asset extensions that can't be a bare binary.
+var nonBinaryExts = []string{
+	".sig", ".txt", ".sha256", ".asc", ".pem", ".json", ".sbom",
+	".tar.gz", ".tgz", ".tar.xz", ".tar.bz2", ".tar", ".zip", ".gz", ".xz",
+	".deb", ".rpm", ".apk", ".pkg", ".msi", ".dmg",
+}
+
+// binaryAsset returns the one asset name that is a binary for goos and
+// goarch, or "" if there is none. More than one match is an error, so an
+// update never depends on the order assets are listed in.
+func binaryAsset(names []string, goos, goarch string) (string, error) {
+	matches := []string{}
+
+	for _, name := range names {
+		if !assetMatches(name, goos, goarch) || hasNonBinaryExt(name) {
+			continue
+		}
+
+		matches = append(matches, name)
+	}
+
+	if len(matches) > 1 {
+		return "", fmt.Errorf("several binaries for %s/%s: %s", goos, goarch, strings.Join(matches, ", "))
+	} else if len(matches) == 0 {
+		return "", nil
+	}
+
+	return matches[0], nil
+}
+
+func hasNonBinaryExt(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range nonBinaryExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// assetMatches reports whether the words of an asset name, split on "_",
+// "-" and ".", include goos and goarch.
+func assetMatches(name, goos, goarch string) bool {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+
+	hasOS, hasArch := false, false
+	for _, w := range words {
+		hasOS = hasOS || w == goos
+		hasArch = hasArch || w == goarch
+	}
+
+	return hasOS && hasArch
+}
+
+// UpdateOptions configures EnableUpdate. Without a PublicKey, binaries are
+// only verified against their SHA-256 checksum. Executable is the binary
+// replaced, the running one by default.
+type UpdateOptions struct {
+	Source     ReleaseSource
+	PublicKey  ed25519.PublicKey
+	Client     *http.Client
+	Executable string
+}
+
+// EnableUpdate registers an update command which replaces the running
+// binary with the latest release when it is newer than App.Version. With
+// --check it only reports whether an update is available.
+func (app *App) EnableUpdate(opts UpdateOptions) {
+	app.AddCommand(NewCommand("update", "Shell", "Update to the latest version",
+		func(cmd *Command) {
+			cmd.Flags.Bool("check", false, "Only check whether an update is available")
+		},
+		func(cmd *Command) error {
+			client := opts.Client
+			if client == nil {
+				client = &http.Client{Timeout: updateTimeout}
+			}
+
+			r, err := opts.Source.Latest(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+
+			newer, err := compareVersions(r.Version, app.Version)
+			if err != nil {
+				return err
+			}
+
+			if newer <= 0 {
+				fmt.Fprintln(cmd.Stdout, app.trf("%s is up to date (%s)", app.name(), app.Version))
+				return nil
+			}
+
+			if cmd.Flag("check") == "true" {
+				fmt.Fprintln(cmd.Stdout, app.trf("%s %s is available (current %s)", app.name(), r.Version, app.Version))
+				return nil
+			}
+
+			asset, err := r.asset(runtime.GOOS + "/" + runtime.GOARCH)
+			if err != nil {
+				return err
+			}
+
+			bin, err := httpGet(cmd.Context(), client, asset.URL)
+			if err != nil {
+				return err
+			}
+
+			if err := asset.verify(r.Version, bin, opts.PublicKey); err != nil {
+				return err
+			}
+
+			exe := opts.Executable
+			if exe == "" {
+				if exe, err = os.Executable(); err != nil {
+					return err
+				}
+			}
+
+			if err := replaceExecutable(exe, bin); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.Stdout, app.trf("Updated %s to %s", app.name(), r.Version))
+			return nil
+		}))
+}
+
+func (r *Release) asset(platform string) (*ReleaseAsset, error) {
+	var found *ReleaseAsset
+
+	for i := range r.Assets {
+		if r.Assets[i].Platform != platform {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("release %s has several binaries for %s", r.Version, platform)
+		}
+
+		found = &r.Assets[i]
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("release %s has no binary for %s", r.Version, platform)
+	}
+
+	return found, nil
+}
+
+func (a *ReleaseAsset) verify(version string, bin []byte, key ed25519.PublicKey) error {
+	if a.SHA256 == "" {
+		return fmt.Errorf("release binary for %s has no checksum", a.Platform)
+	}
+
+	sum := sha256.Sum256(bin)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), a.SHA256) {
+		return ErrChecksumMismatch
+	}
+
+	if key == nil {
+		return nil
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(a.Signature)
+	if err != nil || !ed25519.Verify(key, signedRelease(version, bin), sig) {
+		return fmt.Errorf("invalid signature for release binary for %s", a.Platform)
+	}
+
+	return nil
+}
+
+// signedRelease is the message a release binary's signature covers.
+func signedRelease(version string, bin []byte) []byte {
+	return append(append([]byte(version), 0), bin...)
+}
+
+// replaceExecutable swaps bin in for the binary at exe by renaming a file
+// written next to it over it. On Windows, which does not allow replacing a
+// running executable, the old binary is moved aside first.
+func replaceExecutable(exe string, bin []byte) error {
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	mode := os.FileMode(0755)
+	if fi, err := os.Stat(exe); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(bin); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		return os.Rename(tmp.Name(), exe)
+	}
+
+	old := exe + ".old"
+	os.Remove(old)
+
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+
+	os.Remove(old)
+
+	return nil
+}
+
+func httpGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err == nil && len(b) > maxDownload {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", url, maxDownload)
+	}
+
+	return b, err
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	bin := []byte("new binary")
+	sum := sha256.Sum256(bin)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := Release{Version: "1.3.0"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/release.json":
+			json.NewEncoder(w).Encode(release)
+		case "/bin":
+			w.Write(bin)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	release.Assets = []ReleaseAsset{{
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		URL:       srv.URL + "/bin",
+		SHA256:    hex.EncodeToString(sum[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, signedRelease("1.3.0", bin))),
+	}}
+
+	exe := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+
+	app := NewApp()
+	app.Name = "tool"
+	app.Version = "1.2.0"
+	app.Stdout = &out
+	app.EnableUpdate(UpdateOptions{Source: ReleaseURL(srv.URL + "/release.json"), PublicKey: pub, Executable: exe})
+
+	if err := app.Run([]string{"tool", "update", "--check"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "tool 1.3.0 is available (current 1.2.0)\n" {
+		t.Fatalf("Unexpected check output %q", out.String())
+	}
+
+	release.Assets[0].SHA256 = strings.Repeat("0", 64)
+
+	if err := app.Run([]string{"tool", "update", "--check=false"}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Expected ErrChecksumMismatch, got %v", err)
+	}
+
+	release.Assets[0].SHA256 = hex.EncodeToString(sum[:])
+	release.Version = "1.4.0"
+
+	if err := app.Run([]string{"tool", "update"}); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("Expected a signature for another version to be refused, got %v", err)
+	}
+
+	release.Version = "1.3.0"
+
+	if err := app.Run([]string{"tool", "update"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := os.ReadFile(exe); string(b) != "new binary" {
+		t.Fatalf("Expected the binary to be replaced, got %q", b)
+	}
+
+	if fi, err := os.Stat(exe); err != nil || fi.Mode().Perm() != 0755 {
+		t.Fatalf("Expected the binary to stay executable, got %v", err)
+	}
+
+	out.Reset()
+	app.Version = "1.3.0"
+
+	if err := app.Run([]string{"tool", "update"}); err != nil || out.String() != "tool is up to date (1.3.0)\n" {
+		t.Fatalf("Expected to be up to date, got %v and %q", err, out.String())
+	}
+}
+
+func TestAssetMatches(t *testing.T) {
+	if !assetMatches("tool_Linux_amd64", "linux", "amd64") || assetMatches("tool-linux-arm64", "linux", "arm") {
+		t.Fatal("Unexpected asset match")
+	}
+}
+
+func TestBinaryAsset(t *testing.T) {
+	names := []string{"checksums.txt", "tool_linux_amd64.tar.gz", "tool_linux_amd64.deb", "tool_linux_amd64", "tool_linux_amd64.sig", "tool_darwin_arm64"}
+
+	if name, err := binaryAsset(names, "linux", "amd64"); err != nil || name != "tool_linux_amd64" {
+		t.Fatalf("Expected the bare binary, got %q and %v", name, err)
+	}
+
+	if name, err := binaryAsset(names, "windows", "amd64"); err != nil || name != "" {
+		t.Fatalf("Expected no binary, got %q and %v", name, err)
+	}
+
+	names = append(names, "tool-debug_linux_amd64")
+
+	if _, err := binaryAsset(names, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "several binaries") {
+		t.Fatalf("Expected an error for several matching binaries, got %v", err)
+	}
+
+	r := &Release{Version: "1.0.0", Assets: []ReleaseAsset{{Platform: "linux/amd64"}, {Platform: "linux/amd64"}}}
+
+	if _, err := r.asset("linux/amd64"); err == nil {
+		t.Fatal("Expected an error for several assets for one platform")
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidValue = errors.New("invalid value")
+
+// Validator checks the value of an argument or flag, returning an error
+// that says what is allowed.
+type Validator func(v Value) error
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+func number(v Value) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+	if err != nil {
+		return 0, errors.New("must be a number")
+	}
+
+	return n, nil
+}
+
+// Min allows numbers of at least n.
+func Min(n float64) Validator {
+	return func(v Value) error {
+		x, err := number(v)
+		if err == nil && x < n {
+			err = fmt.Errorf("must be at least %s", formatNumber(n))
+		}
+
+		return err
+	}
+}
+
+// Max allows numbers of at most n.
+func Max(n float64) Validator {
+	return func(v Value) error {
+		x, err := number(v)
+		if err == nil && x > n {
+			err = fmt.Errorf("must be at most %s", formatNumber(n))
+		}
+
+		return err
+	}
+}
+
+// Between allows numbers from a to b inclusive.
+func Between(a, b float64) Validator {
+	return func(v Value) error {
+		x, err := number(v)
+		if err == nil && (x < a || x > b) {
+			err = fmt.Errorf("must be between %s and %s", formatNumber(a), formatNumber(b))
+		}
+
+		return err
+	}
+}
+
+// OneOf allows only the given values.
+func OneOf(values ...string) Validator {
+	return func(v Value) error {
+		for _, a := range values {
+			if string(v) == a {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+	}
+}
+
+// ValidateArg adds validators for the argument called name. For a variable
+// argument every value is checked.
+func (cmd *Command) ValidateArg(name string, vs ...Validator) {
+	if cmd.argValidators == nil {
+		cmd.argValidators = map[string][]Validator{}
+	}
+
+	cmd.argValidators[name] = append(cmd.argValidators[name], vs...)
+}
+
+// ValidateFlag adds validators for the flag called name. They check values
+// given on the command line, not the default.
+func (cmd *Command) ValidateFlag(name string, vs ...Validator) {
+	if cmd.flagValidators == nil {
+		cmd.flagValidators = map[string][]Validator{}
+	}
+
+	cmd.flagValidators[name] = append(cmd.flagValidators[name], vs...)
+}
+
+// checkValues runs the validators against the flags and args parsed into fs.
+func (cmd *Command) checkValues(fs *flag.FlagSet) error {
+	var err error
+
+	fs.Visit(func(f *flag.Flag) {
+		if err == nil {
+			err = cmd.checkValue("--"+f.Name, Value(f.Value.String()), cmd.flagValidators[f.Name])
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for i, a := range cmd.Args {
+		values := []string{fs.Arg(i)}
+		if a.Variable {
+			values = fs.Args()[i:]
+		}
+
+		vs := cmd.argValidators[a.Name]
+		if a.KV {
+			vs = append([]Validator{isKV}, vs...)
+		}
+
+		for _, v := range values {
+			if a.Stdin && v == "-" {
+				continue
+			}
+
+			if a.path != nil {
+				if msg := a.path.check(cmd.Dir(), v); msg != "" {
+					return cmd.usageErr(msg, ErrInvalidValue)
+				}
+			}
+
+			if err := cmd.checkValue(a.Name, Value(v), vs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cmd *Command) checkValue(name string, v Value, vs []Validator) error {
+	for _, validate := range vs {
+		if err := validate(v); err != nil {
+			return cmd.usageErr(fmt.Sprintf("Invalid value %q for %s: %v", v, name, err), ErrInvalidValue)
+		}
+	}
+
+	return nil
+}
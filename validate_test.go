@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	testCases := []struct {
+		v   Validator
+		in  Value
+		err string
+	}{
+		{Min(1), "1", ""},
+		{Min(1), "0", "must be at least 1"},
+		{Max(2.5), "3", "must be at most 2.5"},
+		{Between(1, 10), "10", ""},
+		{Between(1, 10), "11", "must be between 1 and 10"},
+		{Between(1, 10), "ten", "must be a number"},
+		{OneOf("json", "yaml"), "yaml", ""},
+		{OneOf("json", "yaml"), "xml", "must be one of json, yaml"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.v(tc.in)
+
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || err.Error() != tc.err) {
+			t.Fatalf("Expected %q for %q, got %v", tc.err, tc.in, err)
+		}
+	}
+}
+
+func TestCmdValidateArgsAndFlags(t *testing.T) {
+	app := NewApp()
+	c := NewCommand("scale", "test-group", "scales", func(c *Command) {
+		c.AppendArg("service", "the service")
+		c.AppendVarArg("replicas", "replica counts")
+		c.Flags.Int("timeout", 0, "seconds to wait")
+		c.ValidateArg("service", OneOf("web", "worker"))
+		c.ValidateArg("replicas", Between(1, 10))
+		c.ValidateFlag("timeout", Min(1))
+	}, func(c *Command) error { return nil })
+	app.AddCommand(c)
+
+	if err := app.Run([]string{"app", "scale", "web", "1", "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		args []string
+		msg  string
+	}{
+		{[]string{"db", "1"}, `Invalid value "db" for service: must be one of web, worker`},
+		{[]string{"web", "1", "42"}, `Invalid value "42" for replicas: must be between 1 and 10`},
+		{[]string{"--timeout", "0", "web", "1"}, `Invalid value "0" for --timeout: must be at least 1`},
+	}
+
+	for _, tc := range testCases {
+		err := app.Run(append([]string{"app", "scale"}, tc.args...))
+		if !errors.Is(err, ErrInvalidValue) || err.Error() != tc.msg {
+			t.Fatalf("Expected %q for %q, got %v", tc.msg, tc.args, err)
+		}
+
+		if _, err := c.ParseStrict(tc.args); !errors.Is(err, ErrInvalidValue) {
+			t.Fatalf("Expected ParseStrict to reject %q, got %v", tc.args, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	defaultWatchInterval = 250 * time.Millisecond
+	defaultWatchDebounce = 200 * time.Millisecond
+)
+
+// EnableWatch adds a --watch flag to every command. Given a glob, where
+// "**" matches any number of directories, the command is run again whenever
+// a matching file is added, removed or modified, until it is interrupted.
+func (app *App) EnableWatch() {
+	app.watch = true
+}
+
+// watchTimes returns the app's WatchInterval and WatchDebounce or their
+// defaults.
+func (app *App) watchTimes() (interval, debounce time.Duration) {
+	interval, debounce = app.WatchInterval, app.WatchDebounce
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+
+	return interval, debounce
+}
+
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+func watchSnapshot(pattern string) map[string]fileStamp {
+	snap := map[string]fileStamp{}
+
+	files, _ := globFiles(pattern)
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil && !fi.IsDir() {
+			snap[f] = fileStamp{fi.ModTime(), fi.Size()}
+		}
+	}
+
+	return snap
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for f, s := range a {
+		if b[f] != s {
+			return false
+		}
+	}
+
+	return true
+}
+
+// withWatch runs run, then again after each change to the files matching
+// pattern, polling every interval and waiting for debounce without changes,
+// clearing the screen in between on a terminal. Errors are printed rather
+// than returned so a failing run does not end the watch.
+func withWatch(run RunFunc, pattern string, interval, debounce time.Duration) RunFunc {
+	return func(cmd *Command) error {
+		ctx := cmd.Context()
+		pattern := inDir(cmd.Dir(), pattern)
+		snap := watchSnapshot(pattern)
+
+		for {
+			if cmd.interactiveWriter(cmd.stdout()) {
+				fmt.Fprint(cmd.stdout(), "\033[H\033[2J")
+			}
+
+			if err := run(cmd); err != nil {
+				fmt.Fprintf(cmd.stderr(), "%s: %v\n", cmd.Name, err)
+			}
+
+			fmt.Fprintln(cmd.stderr(), cmd.app.trf("Watching %s for changes...", pattern))
+
+			// Wait for a change, then for the files to settle
+			changed := false
+			settled := time.Time{}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(interval):
+				}
+
+				next := watchSnapshot(pattern)
+
+				if !sameSnapshot(snap, next) {
+					changed = true
+					settled = time.Now()
+					snap = next
+				} else if changed && time.Since(settled) >= debounce {
+					break
+				}
+			}
+		}
+	}
+}
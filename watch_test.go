@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "src", "main.go")
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var errOut strings.Builder
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+
+	c := NewCommand("test", "test-group", "does test stuff", func(c *Command) {}, nil)
+	c.Stderr = &errOut
+	c.ctx = ctx
+
+	run := withWatch(func(c *Command) error {
+		runs++
+
+		switch runs {
+		case 1:
+			return os.WriteFile(file, []byte("package main"), 0644)
+		case 2:
+			cancel()
+		}
+
+		return errors.New("failed")
+	}, filepath.Join(dir, "**", "*.go"), 5*time.Millisecond, 10*time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		done <- run(c)
+	}()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not rerun after a change")
+	}
+
+	if runs != 2 {
+		t.Fatalf("Expected 2 runs, got %d", runs)
+	}
+
+	if !strings.Contains(errOut.String(), "test: failed\n") {
+		t.Fatalf("Expected errors to be printed, got %q", errOut.String())
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnableWizard registers a wizard command which walks through another
+// command's arguments, flags and environment variables interactively, prints
+// the resulting command line and offers to run it.
+func (app *App) EnableWizard() {
+	app.AddCommand(NewCommand("wizard", "Shell", "Build a command line step by step",
+		func(cmd *Command) {
+			cmd.AppendArg("command", "The command to build")
+		},
+		func(cmd *Command) error {
+			target, ok := app.command(cmd.Arg("command").String())
+			if !ok || !app.listed(cmd.Context(), target) {
+				return cmd.usageErr("Invalid command", ErrInvalidCommand)
+			}
+
+			if !cmd.Interactive() {
+				return ErrNotInteractive
+			}
+
+			ctx, release := hold(cmd.Context(), target)
+			defer release()
+
+			app.prepare(target)
+
+			w := &wizard{
+				app: app,
+				cmd: target,
+				ask: func(label string, secret bool) (string, error) {
+					if secret {
+						return cmd.PromptSecret(label)
+					}
+
+					return cmd.Prompt(label)
+				},
+			}
+
+			if err := w.collect(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.Stdout, "\n%s\n\n", w.commandLine())
+
+			if !cmd.Confirm("Run it now?") {
+				return nil
+			}
+
+			ctx = context.WithValue(ctx, envKey{}, w.env)
+
+			return app.RunContext(ctx, append([]string{app.name()}, w.args()...))
+		}))
+}
+
+// envKey holds env values a run was started with, such as those collected
+// by the wizard. They are set in the command's env overlay.
+type envKey struct{}
+
+type wizard struct {
+	app *App
+	cmd *Command
+	ask func(label string, secret bool) (string, error)
+
+	positional []string
+	flags      [][2]string
+	env        map[string]string
+}
+
+func (w *wizard) collect() error {
+	for _, a := range w.cmd.Args {
+		if !a.Variable {
+			v, err := w.ask(fmt.Sprintf("%s (%s)", a.Name, a.Description), w.cmd.isSecret(a.Name))
+			if err != nil {
+				return err
+			}
+
+			w.positional = append(w.positional, v)
+			continue
+		}
+
+		for {
+			v, err := w.ask(fmt.Sprintf("%s (%s, empty line to finish)", a.Name, a.Description), w.cmd.isSecret(a.Name))
+			if err != nil {
+				return err
+			}
+
+			if v == "" {
+				break
+			}
+
+			w.positional = append(w.positional, v)
+		}
+	}
+
+	// Shorthands such as -o share their long flag's value; only the long
+	// name is asked for
+	primary := map[flag.Value]string{}
+	w.cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if !reflect.TypeOf(f.Value).Comparable() {
+			return
+		}
+
+		if name, ok := primary[f.Value]; !ok || len(f.Name) > len(name) {
+			primary[f.Value] = f.Name
+		}
+	})
+
+	var err error
+
+	w.cmd.Flags.VisitAll(func(f *flag.Flag) {
+		if err != nil || f.Name == "help" || w.cmd.hiddenFlags[f.Name] {
+			return
+		}
+
+		if name, ok := primary[f.Value]; ok && name != f.Name {
+			return
+		}
+
+		var v string
+		v, err = w.ask(fmt.Sprintf("--%s (%s) [%s]", f.Name, f.Usage, f.DefValue), w.cmd.isSecret(f.Name))
+
+		if err == nil && v != "" && v != f.DefValue {
+			w.flags = append(w.flags, [2]string{f.Name, v})
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	w.env = map[string]string{}
+
+	names := make([]string, 0, len(w.cmd.EnvArgs))
+	for n := range w.cmd.EnvArgs {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		if w.cmd.EnvArg(n) != "" {
+			continue
+		}
+
+		v, err := w.ask(fmt.Sprintf("%s (%s)", n, w.cmd.EnvArgs[n]), w.cmd.isSecret(n))
+		if err != nil {
+			return err
+		}
+
+		w.env[n] = v
+	}
+
+	return nil
+}
+
+func (w *wizard) args() []string {
+	args := []string{w.cmd.Name}
+
+	for _, f := range w.flags {
+		args = append(args, "--"+f[0]+"="+f[1])
+	}
+
+	if len(w.positional) > 0 {
+		args = append(args, "--")
+	}
+
+	return append(args, w.positional...)
+}
+
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+func displayQuote(s string) string {
+	if plainWord.MatchString(s) {
+		return s
+	}
+
+	return shellQuote("sh", s)
+}
+
+// commandLine renders the invocation for copy and paste. The values of secret
+// flags, args and environment variables are replaced, as in the history.
+func (w *wizard) commandLine() string {
+	words := []string{}
+
+	names := make([]string, 0, len(w.env))
+	for n := range w.env {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		v := w.env[n]
+		if w.cmd.isSecret(n) {
+			v = historyRedacted
+		}
+
+		words = append(words, n+"="+displayQuote(v))
+	}
+
+	words = append(words, w.app.name(), w.cmd.Name)
+
+	args, _ := w.cmd.redactArgs(w.args()[1:])
+	for _, a := range args {
+		if a == "--" && !w.needsDashes() {
+			continue
+		}
+
+		words = append(words, displayQuote(a))
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (w *wizard) needsDashes() bool {
+	for _, p := range w.positional {
+		if strings.HasPrefix(p, "-") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWizard(t *testing.T) {
+	app := NewApp()
+	app.Name = "tool"
+
+	c := NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		c.AppendArg("service", "the service")
+		c.AppendVarArg("hosts", "the hosts")
+		c.Flags.String("env", "staging", "the environment")
+		c.Flags.Bool("force", false, "force it")
+		c.Flags.String("key", "", "the key")
+		c.MarkSecret("key")
+		c.AddEnvArg("CMD_TEST_WIZARD_REGION", "the region")
+		c.AddSecretEnvArg("CMD_TEST_WIZARD_TOKEN", "the token")
+	}, nil)
+	app.AddCommand(c)
+
+	answers := []string{"web app", "host1", "host2", "", "prod", "", "s3cret", "us-east-1", "hunter2"}
+
+	w := &wizard{
+		app: app,
+		cmd: c,
+		ask: func(label string, secret bool) (string, error) {
+			a := answers[0]
+			answers = answers[1:]
+			return a, nil
+		},
+	}
+
+	if err := w.collect(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"deploy", "--env=prod", "--key=s3cret", "--", "web app", "host1", "host2"}
+	if args := w.args(); !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected %q, got %q", expected, args)
+	}
+
+	line := "CMD_TEST_WIZARD_REGION=us-east-1 CMD_TEST_WIZARD_TOKEN='***' tool deploy --env=prod '--key=***' 'web app' host1 host2"
+	if l := w.commandLine(); l != line {
+		t.Fatalf("Expected %q, got %q", line, l)
+	}
+}
+
+func TestWizardRun(t *testing.T) {
+	var got string
+	var out strings.Builder
+
+	app := NewApp()
+	app.Name = "tool"
+	app.Interactive = Always
+	app.Getenv = func(string) string { return "" }
+	app.Stdout = &out
+	app.Stderr = &out
+	app.EnableWizard()
+
+	// One answer each for --output and CMD_TEST_WIZARD_REGION, then "y" to
+	// run it; a prompt for -o would take the region's answer
+	app.Stdin = strings.NewReader("json\neu-west-1\ny\n")
+
+	app.AddCommand(NewCommand("deploy", "test-group", "deploys", func(c *Command) {
+		output := c.Flags.String("output", "", "the output format")
+		c.Flags.StringVar(output, "o", "", "Shorthand for --output")
+		c.AddEnvArg("CMD_TEST_WIZARD_REGION", "the region")
+	}, func(c *Command) error {
+		got = c.Flag("output").String() + " " + c.EnvArg("CMD_TEST_WIZARD_REGION").String()
+		return nil
+	}))
+
+	if err := app.Run([]string{"tool", "wizard", "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "--o ") {
+		t.Fatalf("Expected no prompt for the shorthand:\n%s", out.String())
+	}
+
+	if got != "json eu-west-1" {
+		t.Fatalf("Expected the wizard's flag and env values in the run, got %q", got)
+	}
+}
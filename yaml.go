@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9_./ @()-]*$`)
+
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"null": true, "~": true, "y": true, "n": true,
+}
+
+func yamlString(s string) string {
+	if yamlPlain.MatchString(s) && !strings.HasSuffix(s, " ") && !yamlReserved[strings.ToLower(s)] && !looksNumeric(s) {
+		return s
+	}
+
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func looksNumeric(s string) bool {
+	_, err := json.Number(s).Float64()
+	return err == nil || strings.HasPrefix(s, ".")
+}
+
+func (n *node) yamlScalar() string {
+	switch v := n.scalar.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlString(v)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	}
+
+	return yamlString(n.text())
+}
+
+func (n *node) isEmptyCollection() bool {
+	return n.kind != nodeScalar && len(n.values) == 0
+}
+
+func (n *node) inlineYAML() string {
+	switch {
+	case n.kind == nodeObject && len(n.values) == 0:
+		return "{}"
+	case n.kind == nodeArray && len(n.values) == 0:
+		return "[]"
+	}
+
+	return n.yamlScalar()
+}
+
+// writeYAML writes n as a block-style YAML document.
+func (n *node) writeYAML(w io.Writer) {
+	var b strings.Builder
+	n.yamlBlock(&b, 0)
+	io.WriteString(w, b.String())
+}
+
+func (n *node) yamlBlock(b *strings.Builder, indent int) {
+	pad := strings.Repeat(" ", indent)
+
+	if n.kind == nodeScalar || n.isEmptyCollection() {
+		b.WriteString(pad + n.inlineYAML() + "\n")
+		return
+	}
+
+	for i, v := range n.values {
+		if n.kind == nodeObject {
+			b.WriteString(pad + yamlString(n.keys[i]) + ":")
+		} else {
+			b.WriteString(pad + "-")
+		}
+
+		switch {
+		case v.kind == nodeScalar || v.isEmptyCollection():
+			b.WriteString(" " + v.inlineYAML() + "\n")
+		case n.kind == nodeArray && v.kind == nodeObject:
+			// The first key of a mapping in a sequence shares the dash's line
+			var sub strings.Builder
+			v.yamlBlock(&sub, indent+2)
+			b.WriteString(" " + strings.TrimPrefix(sub.String(), pad+"  "))
+		default:
+			b.WriteString("\n")
+			v.yamlBlock(b, indent+2)
+		}
+	}
+}